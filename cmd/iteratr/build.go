@@ -6,12 +6,16 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/mark3labs/iteratr/internal/agent"
 	"github.com/mark3labs/iteratr/internal/config"
+	ierr "github.com/mark3labs/iteratr/internal/errors"
 	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/nats"
 	"github.com/mark3labs/iteratr/internal/orchestrator"
 	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/template"
 	"github.com/mark3labs/iteratr/internal/tui/wizard"
 	natsserver "github.com/nats-io/nats-server/v2/server"
 	"github.com/spf13/cobra"
@@ -19,8 +23,11 @@ import (
 
 var buildFlags struct {
 	name              string
-	spec              string
+	specs             []string
 	template          string
+	templateMode      string
+	promptBudget      int
+	transcriptMax     int
 	extraInstructions string
 	iterations        int
 	headless          bool
@@ -28,6 +35,18 @@ var buildFlags struct {
 	model             string
 	reset             bool
 	autoCommit        bool
+	commitMode        string
+	review            bool
+	specSync          bool
+	worktree          bool
+	backend           string
+	replayScript      string
+	iterationTimeout  time.Duration
+	idleTimeout       time.Duration
+	maxTokens         int64
+	maxCost           float64
+	maxDuration       time.Duration
+	budgetAction      string
 }
 
 var buildCmd = &cobra.Command{
@@ -39,6 +58,10 @@ The build command starts an iterative loop where an AI agent works on tasks
 defined in a spec file. It uses embedded NATS for persistence and presents
 a TUI (unless --headless) to monitor progress.
 
+Pass --spec more than once to run several sessions concurrently in one
+process. Each runs in its own git worktree; switch between them in the TUI
+with ctrl+x ] and ctrl+x [.
+
 Configuration is loaded from multiple sources with the following precedence:
   CLI flags > Environment variables > Project config > Global config > Defaults
 
@@ -49,8 +72,11 @@ Global config: ~/.config/iteratr/iteratr.yml`,
 
 func init() {
 	buildCmd.Flags().StringVarP(&buildFlags.name, "name", "n", "", "Session name (default: spec filename stem)")
-	buildCmd.Flags().StringVarP(&buildFlags.spec, "spec", "s", "", "Spec file path (default: ./specs/SPEC.md)")
+	buildCmd.Flags().StringArrayVarP(&buildFlags.specs, "spec", "s", nil, "Spec file path, repeat to run several sessions at once (default: ./specs/SPEC.md)")
 	buildCmd.Flags().StringVarP(&buildFlags.template, "template", "t", "", "Custom template file (overrides config file)")
+	buildCmd.Flags().StringVar(&buildFlags.templateMode, "template-mode", "", "Template rendering: auto, legacy, or go (overrides config file, default: auto)")
+	buildCmd.Flags().IntVar(&buildFlags.promptBudget, "prompt-budget", 0, "Trim session state in the prompt to about this many tokens, 0=unlimited (overrides config file)")
+	buildCmd.Flags().IntVar(&buildFlags.transcriptMax, "transcript-max-bytes", 0, "Size cap of each iteration's transcript, 0=unlimited, -1=off (overrides config file, default: 4MiB)")
 	buildCmd.Flags().StringVarP(&buildFlags.extraInstructions, "extra-instructions", "e", "", "Extra instructions for prompt")
 	buildCmd.Flags().IntVarP(&buildFlags.iterations, "iterations", "i", 0, "Max iterations, 0=infinite (overrides config file)")
 	buildCmd.Flags().BoolVar(&buildFlags.headless, "headless", false, "Run without TUI (overrides config file)")
@@ -58,11 +84,23 @@ func init() {
 	buildCmd.Flags().StringVarP(&buildFlags.model, "model", "m", "", "Model to use (overrides config file, e.g., anthropic/claude-sonnet-4-5)")
 	buildCmd.Flags().BoolVar(&buildFlags.reset, "reset", false, "Reset session data before starting (clears all NATS events for this session)")
 	buildCmd.Flags().BoolVar(&buildFlags.autoCommit, "auto-commit", true, "Auto-commit modified files after iteration (overrides config file)")
+	buildCmd.Flags().StringVar(&buildFlags.commitMode, "commit-mode", "", "Auto-commit mode: agent, native, or native-llm (overrides config file, default: agent)")
+	buildCmd.Flags().BoolVar(&buildFlags.review, "review", false, "Review each iteration's diff before auto-commit: approve, reject, or send feedback (overrides config file)")
+	buildCmd.Flags().BoolVar(&buildFlags.specSync, "spec-sync", false, "Check off the spec's checklist items as their tasks are completed (overrides config file)")
+	buildCmd.Flags().BoolVar(&buildFlags.worktree, "worktree", false, "Run the agent in a git worktree on branch iteratr/<session> (overrides config file)")
+	buildCmd.Flags().StringVar(&buildFlags.backend, "backend", "", "Agent backend (overrides config file, default: kit)")
+	buildCmd.Flags().StringVar(&buildFlags.replayScript, "replay-script", "", "Script file for the replay backend (overrides config file)")
+	buildCmd.Flags().DurationVar(&buildFlags.iterationTimeout, "iteration-timeout", 0, "Cancel an iteration after this long, e.g. 45m, 0=no limit (overrides config file)")
+	buildCmd.Flags().DurationVar(&buildFlags.idleTimeout, "idle-timeout", 0, "Cancel an iteration when the agent is silent this long, 0=no limit (overrides config file)")
+	buildCmd.Flags().Int64Var(&buildFlags.maxTokens, "max-tokens", 0, "Session token budget, 0=unlimited (overrides config file)")
+	buildCmd.Flags().Float64Var(&buildFlags.maxCost, "max-cost", 0, "Session estimated cost budget in USD, 0=unlimited (overrides config file)")
+	buildCmd.Flags().DurationVar(&buildFlags.maxDuration, "max-duration", 0, "Wall-clock budget for this run, e.g. 8h, 0=unlimited (overrides config file)")
+	buildCmd.Flags().StringVar(&buildFlags.budgetAction, "budget-action", "", "Action when a budget is exceeded: stop or pause (overrides config file, default: stop)")
 }
 
 // setupWizardStore creates a temporary NATS connection and session store for the wizard.
 // Returns the store and a cleanup function that must be called when done.
-func setupWizardStore(dataDir string) (*session.Store, func(), error) {
+func setupWizardStore(dataDir string, retention nats.Retention) (*session.Store, func(), error) {
 	// Ensure data directory exists
 	fullDataDir := filepath.Join(dataDir, "data")
 	if err := os.MkdirAll(fullDataDir, 0755); err != nil {
@@ -102,7 +140,7 @@ func setupWizardStore(dataDir string) (*session.Store, func(), error) {
 
 	// Setup stream
 	ctx := context.Background()
-	stream, err := nats.SetupStream(ctx, js)
+	stream, err := nats.SetupStreamWithRetention(ctx, js, retention)
 	if err != nil {
 		nc.Close()
 		if ns != nil {
@@ -113,6 +151,7 @@ func setupWizardStore(dataDir string) (*session.Store, func(), error) {
 
 	// Create session store
 	store := session.NewStore(js, stream)
+	store.SetRetention(retention)
 
 	// Return cleanup function
 	cleanup := func() {
@@ -156,12 +195,60 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	if !cmd.Flags().Changed("auto-commit") {
 		buildFlags.autoCommit = cfg.AutoCommit
 	}
+	if !cmd.Flags().Changed("commit-mode") {
+		buildFlags.commitMode = cfg.CommitMode
+	}
+	if !cmd.Flags().Changed("review") {
+		buildFlags.review = cfg.Review
+	}
+	if !cmd.Flags().Changed("spec-sync") {
+		buildFlags.specSync = cfg.SpecSync
+	}
+	if !cmd.Flags().Changed("worktree") {
+		buildFlags.worktree = cfg.Worktree
+	}
 	if !cmd.Flags().Changed("data-dir") {
 		buildFlags.dataDir = cfg.DataDir
 	}
 	if !cmd.Flags().Changed("template") {
 		buildFlags.template = cfg.Template
 	}
+	if !cmd.Flags().Changed("template-mode") {
+		buildFlags.templateMode = cfg.TemplateMode
+	}
+	if !cmd.Flags().Changed("backend") {
+		buildFlags.backend = cfg.Backend
+	}
+	if !cmd.Flags().Changed("replay-script") {
+		buildFlags.replayScript = cfg.ReplayScript
+	}
+	if !cmd.Flags().Changed("prompt-budget") {
+		buildFlags.promptBudget = cfg.PromptBudget
+	}
+	if !cmd.Flags().Changed("transcript-max-bytes") {
+		buildFlags.transcriptMax = cfg.TranscriptMaxBytes
+	}
+	if !cmd.Flags().Changed("iteration-timeout") {
+		buildFlags.iterationTimeout = cfg.IterationTimeout
+	}
+	if !cmd.Flags().Changed("idle-timeout") {
+		buildFlags.idleTimeout = cfg.IdleTimeout
+	}
+	if !cmd.Flags().Changed("max-tokens") {
+		buildFlags.maxTokens = cfg.Budget.MaxTokens
+	}
+	if !cmd.Flags().Changed("max-cost") {
+		buildFlags.maxCost = cfg.Budget.MaxCost
+	}
+	if !cmd.Flags().Changed("max-duration") {
+		buildFlags.maxDuration = cfg.Budget.MaxDuration
+	}
+	if !cmd.Flags().Changed("budget-action") {
+		buildFlags.budgetAction = cfg.Budget.Action
+	}
+	if !agent.HasBackend(buildFlags.backend) {
+		return fmt.Errorf("unknown agent backend %q (available: %s)", buildFlags.backend, strings.Join(agent.Backends(), ", "))
+	}
 
 	// Validate that model is set after applying config and CLI flags
 	// Model can come from config file, ENV var (ITERATR_MODEL), or CLI flag
@@ -175,11 +262,11 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	resumeMode := false
 
 	// Run wizard if no spec provided and not headless
-	if buildFlags.spec == "" && !buildFlags.headless {
+	if len(buildFlags.specs) == 0 && !buildFlags.headless {
 		logger.Info("No spec file provided, launching wizard...")
 
 		// Set up NATS for wizard session selector
-		wizardStore, cleanup, err := setupWizardStore(buildFlags.dataDir)
+		wizardStore, cleanup, err := setupWizardStore(buildFlags.dataDir, streamRetention(cfg))
 		if err != nil {
 			return fmt.Errorf("failed to setup wizard store: %w", err)
 		}
@@ -202,7 +289,7 @@ func runBuild(cmd *cobra.Command, args []string) error {
 			logger.Info("Resuming existing session: %s (model: %s)", result.SessionName, buildFlags.model)
 		} else {
 			// New session mode: apply all wizard results to buildFlags
-			buildFlags.spec = result.SpecPath
+			buildFlags.specs = []string{result.SpecPath}
 			buildFlags.model = result.Model
 			buildFlags.name = result.SessionName
 			buildFlags.iterations = result.Iterations
@@ -239,50 +326,53 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Determine spec path
+	// Determine spec paths
 	// In resume mode, spec is optional (session already has tasks)
-	specPath := buildFlags.spec
-	if specPath == "" {
+	specPaths := buildFlags.specs
+	if len(specPaths) == 0 {
 		// Look for SPEC.md in specs/ directory
 		defaultSpec := "specs/SPEC.md"
 		if _, err := os.Stat(defaultSpec); err == nil {
-			specPath = defaultSpec
+			specPaths = []string{defaultSpec}
 		} else if !resumeMode {
 			// Require spec file for new sessions (not resume mode)
 			return fmt.Errorf("no spec file found, use --spec to specify path or run without --headless to use wizard")
+		} else {
+			specPaths = []string{""}
 		}
 	}
 
-	// Check if spec file exists (only if a spec path was determined)
-	if specPath != "" {
-		if _, err := os.Stat(specPath); os.IsNotExist(err) {
-			return fmt.Errorf("spec file not found: %s", specPath)
+	// Several specs run as concurrent sessions, each isolated in its own worktree
+	if len(specPaths) > 1 {
+		if buildFlags.name != "" {
+			return fmt.Errorf("--name cannot be used with several --spec flags (session names come from the spec filenames)")
+		}
+		if !buildFlags.worktree {
+			logger.Info("Running %d specs concurrently, enabling worktree mode", len(specPaths))
+			buildFlags.worktree = true
 		}
 	}
 
-	// Determine session name
-	sessionName := buildFlags.name
-	if sessionName == "" {
-		// Derive from spec filename
-		base := filepath.Base(specPath)
-		ext := filepath.Ext(base)
-		sessionName = strings.TrimSuffix(base, ext)
+	sessionNames := make([]string, len(specPaths))
+	for i, specPath := range specPaths {
+		// Check if spec file exists (only if a spec path was determined)
+		if specPath != "" {
+			if _, err := os.Stat(specPath); os.IsNotExist(err) {
+				return fmt.Errorf("spec file not found: %s", specPath)
+			}
+		}
 
-		// Replace dots with hyphens (NATS subject constraint)
-		sessionName = strings.ReplaceAll(sessionName, ".", "-")
-	}
+		// Determine session name
+		sessionName := buildFlags.name
+		if sessionName == "" {
+			sessionName = sessionNameFromSpec(specPath)
+		}
 
-	// Validate session name (alphanumeric, hyphens, underscores only)
-	if sessionName == "" {
-		return fmt.Errorf("session name cannot be empty")
-	}
-	if len(sessionName) > 64 {
-		return fmt.Errorf("session name too long (max 64 characters): %s", sessionName)
-	}
-	for _, r := range sessionName {
-		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
-			return fmt.Errorf("invalid session name: %s (use only alphanumeric, hyphens, underscores)", sessionName)
+		// Validate session name (alphanumeric, hyphens, underscores only)
+		if err := validateSessionName(sessionName); err != nil {
+			return err
 		}
+		sessionNames[i] = sessionName
 	}
 
 	// Validate iteration count
@@ -290,6 +380,46 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("iterations must be >= 0 (0 means unlimited)")
 	}
 
+	// Validate watchdog timeouts
+	if buildFlags.iterationTimeout < 0 || buildFlags.idleTimeout < 0 {
+		return fmt.Errorf("iteration and idle timeouts must be >= 0 (0 means no limit)")
+	}
+
+	// Validate commit mode
+	switch buildFlags.commitMode {
+	case "", orchestrator.CommitModeAgent, orchestrator.CommitModeNative, orchestrator.CommitModeNativeLLM:
+	default:
+		return fmt.Errorf("commit mode must be %q, %q, or %q, got %q", orchestrator.CommitModeAgent, orchestrator.CommitModeNative, orchestrator.CommitModeNativeLLM, buildFlags.commitMode)
+	}
+
+	// Validate template mode
+	if !template.IsValidMode(buildFlags.templateMode) {
+		return fmt.Errorf("template mode must be %q, %q, or %q, got %q", template.ModeAuto, template.ModeLegacy, template.ModeGo, buildFlags.templateMode)
+	}
+
+	// Validate prompt budget
+	if buildFlags.promptBudget < 0 {
+		return fmt.Errorf("prompt budget must be >= 0 (0 means unlimited)")
+	}
+
+	// Validate transcript cap
+	if buildFlags.transcriptMax < -1 {
+		return fmt.Errorf("transcript max bytes must be >= 0 (0 means unlimited), or -1 to disable transcripts")
+	}
+
+	// Validate budget
+	if buildFlags.maxTokens < 0 || buildFlags.maxCost < 0 || buildFlags.maxDuration < 0 {
+		return fmt.Errorf("budget limits must be >= 0 (0 means unlimited)")
+	}
+	switch buildFlags.budgetAction {
+	case "", orchestrator.BudgetActionStop, orchestrator.BudgetActionPause:
+	default:
+		return fmt.Errorf("budget action must be %q or %q, got %q", orchestrator.BudgetActionStop, orchestrator.BudgetActionPause, buildFlags.budgetAction)
+	}
+	if buildFlags.maxCost > 0 && len(cfg.Prices) == 0 {
+		logger.Warn("Cost budget is set but no prices are configured; estimated cost stays at $0 and the limit is never reached")
+	}
+
 	// Use template path from config, CLI flag, or wizard
 	// If empty, orchestrator will use embedded default template
 	templatePath := buildFlags.template
@@ -305,20 +435,62 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Create orchestrator
-	orch, err := orchestrator.New(orchestrator.Config{
-		SessionName:       sessionName,
-		SpecPath:          specPath,
+	// Create orchestrator (or a group of them for several specs)
+	retention := streamRetention(cfg)
+	base := orchestrator.Config{
 		TemplatePath:      templatePath,
+		TemplateMode:      buildFlags.templateMode,
+		TemplateVars:      cfg.TemplateVars,
+		PromptBudget:      buildFlags.promptBudget,
+		TranscriptMax:     buildFlags.transcriptMax,
 		ExtraInstructions: buildFlags.extraInstructions,
 		Iterations:        buildFlags.iterations,
 		DataDir:           buildFlags.dataDir,
 		Headless:          buildFlags.headless,
 		Model:             buildFlags.model,
+		Backend:           buildFlags.backend,
+		ReplayScript:      buildFlags.replayScript,
 		Reset:             buildFlags.reset,
 		AutoCommit:        buildFlags.autoCommit,
 		CommitDataDir:     cfg.CommitDataDir,
-	})
+		CommitMode:        buildFlags.commitMode,
+		Review:            buildFlags.review,
+		SpecSync:          buildFlags.specSync,
+		Worktree:          buildFlags.worktree,
+		Retention:         &retention,
+		Prices:            priceTable(cfg),
+		IterationTimeout:  buildFlags.iterationTimeout,
+		IdleTimeout:       buildFlags.idleTimeout,
+		Retry: ierr.RetryConfig{
+			MaxAttempts: cfg.Retry.MaxAttempts,
+			InitialWait: cfg.Retry.InitialWait,
+			MaxWait:     cfg.Retry.MaxWait,
+			Multiplier:  2.0,
+		},
+		Budget: orchestrator.Budget{
+			MaxTokens:   buildFlags.maxTokens,
+			MaxCost:     buildFlags.maxCost,
+			MaxDuration: buildFlags.maxDuration,
+			Action:      buildFlags.budgetAction,
+		},
+	}
+	cfgs := make([]orchestrator.Config, len(specPaths))
+	for i, specPath := range specPaths {
+		cfgs[i] = base
+		cfgs[i].SessionName = sessionNames[i]
+		cfgs[i].SpecPath = specPath
+	}
+
+	var orch interface {
+		Start() error
+		Run() error
+		Stop() error
+	}
+	if len(cfgs) == 1 {
+		orch, err = orchestrator.New(cfgs[0])
+	} else {
+		orch, err = orchestrator.NewGroup(cfgs)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create orchestrator: %w", err)
 	}
@@ -352,3 +524,45 @@ func runBuild(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// sessionNameFromSpec derives a session name from a spec filename:
+// specs/my.feature.md -> my-feature.
+func sessionNameFromSpec(specPath string) string {
+	base := filepath.Base(specPath)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	// Replace dots with hyphens (NATS subject constraint)
+	return strings.ReplaceAll(name, ".", "-")
+}
+
+// validateSessionName checks that a session name is usable as a NATS subject token.
+// Names must be 1-64 characters of alphanumerics, hyphens, or underscores.
+func validateSessionName(sessionName string) error {
+	if sessionName == "" {
+		return fmt.Errorf("session name cannot be empty")
+	}
+	if len(sessionName) > 64 {
+		return fmt.Errorf("session name too long (max 64 characters): %s", sessionName)
+	}
+	for _, r := range sessionName {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return fmt.Errorf("invalid session name: %s (use only alphanumeric, hyphens, underscores)", sessionName)
+		}
+	}
+	return nil
+}
+
+// priceTable converts configured model prices to the session price table.
+func priceTable(cfg *config.Config) session.PriceTable {
+	table := make(session.PriceTable, len(cfg.Prices))
+	for _, p := range cfg.Prices {
+		table[p.Model] = session.Price{
+			Input:      p.Input,
+			Output:     p.Output,
+			CacheRead:  p.CacheRead,
+			CacheWrite: p.CacheWrite,
+		}
+	}
+	return table
+}
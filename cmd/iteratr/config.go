@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 
 	"charm.land/lipgloss/v2"
@@ -46,12 +48,39 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	configRows := [][]string{
 		{"model", cfg.Model},
 		{"auto_commit", strconv.FormatBool(cfg.AutoCommit)},
+		{"commit_mode", cfg.CommitMode},
+		{"review", strconv.FormatBool(cfg.Review)},
+		{"spec_sync", strconv.FormatBool(cfg.SpecSync)},
 		{"data_dir", cfg.DataDir},
 		{"log_level", cfg.LogLevel},
 		{"log_file", cfg.LogFile},
 		{"iterations", strconv.Itoa(cfg.Iterations)},
 		{"headless", strconv.FormatBool(cfg.Headless)},
 		{"template", cfg.Template},
+		{"template_mode", cfg.TemplateMode},
+		{"prompt_budget", strconv.Itoa(cfg.PromptBudget)},
+		{"transcript_max_bytes", strconv.Itoa(cfg.TranscriptMaxBytes)},
+		{"worktree", strconv.FormatBool(cfg.Worktree)},
+		{"backend", cfg.Backend},
+		{"replay_script", cfg.ReplayScript},
+		{"iteration_timeout", cfg.IterationTimeout.String()},
+		{"idle_timeout", cfg.IdleTimeout.String()},
+		{"retention.max_age_days", strconv.Itoa(cfg.Retention.MaxAgeDays)},
+		{"retention.max_bytes", strconv.FormatInt(cfg.Retention.MaxBytes, 10)},
+		{"retention.max_msgs_per_subject", strconv.FormatInt(cfg.Retention.MaxMsgsPerSubject, 10)},
+		{"retry.max_attempts", strconv.Itoa(cfg.Retry.MaxAttempts)},
+		{"retry.initial_wait", cfg.Retry.InitialWait.String()},
+		{"retry.max_wait", cfg.Retry.MaxWait.String()},
+		{"budget.max_tokens", strconv.FormatInt(cfg.Budget.MaxTokens, 10)},
+		{"budget.max_cost", strconv.FormatFloat(cfg.Budget.MaxCost, 'f', -1, 64)},
+		{"budget.max_duration", cfg.Budget.MaxDuration.String()},
+		{"budget.action", cfg.Budget.Action},
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.TemplateVars)) {
+		configRows = append(configRows, []string{"template_vars." + name, cfg.TemplateVars[name]})
+	}
+	for _, p := range cfg.Prices {
+		configRows = append(configRows, []string{"prices." + p.Model, formatPrice(p)})
 	}
 
 	configTable := table.New().
@@ -127,12 +156,33 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	}{
 		{"ITERATR_MODEL", "model"},
 		{"ITERATR_AUTO_COMMIT", "auto_commit"},
+		{"ITERATR_COMMIT_MODE", "commit_mode"},
+		{"ITERATR_REVIEW", "review"},
+		{"ITERATR_SPEC_SYNC", "spec_sync"},
 		{"ITERATR_DATA_DIR", "data_dir"},
 		{"ITERATR_LOG_LEVEL", "log_level"},
 		{"ITERATR_LOG_FILE", "log_file"},
 		{"ITERATR_ITERATIONS", "iterations"},
 		{"ITERATR_HEADLESS", "headless"},
 		{"ITERATR_TEMPLATE", "template"},
+		{"ITERATR_TEMPLATE_MODE", "template_mode"},
+		{"ITERATR_PROMPT_BUDGET", "prompt_budget"},
+		{"ITERATR_TRANSCRIPT_MAX_BYTES", "transcript_max_bytes"},
+		{"ITERATR_WORKTREE", "worktree"},
+		{"ITERATR_BACKEND", "backend"},
+		{"ITERATR_REPLAY_SCRIPT", "replay_script"},
+		{"ITERATR_ITERATION_TIMEOUT", "iteration_timeout"},
+		{"ITERATR_IDLE_TIMEOUT", "idle_timeout"},
+		{"ITERATR_RETENTION_MAX_AGE_DAYS", "retention.max_age_days"},
+		{"ITERATR_RETENTION_MAX_BYTES", "retention.max_bytes"},
+		{"ITERATR_RETENTION_MAX_MSGS_PER_SUBJECT", "retention.max_msgs_per_subject"},
+		{"ITERATR_RETRY_MAX_ATTEMPTS", "retry.max_attempts"},
+		{"ITERATR_RETRY_INITIAL_WAIT", "retry.initial_wait"},
+		{"ITERATR_RETRY_MAX_WAIT", "retry.max_wait"},
+		{"ITERATR_BUDGET_MAX_TOKENS", "budget.max_tokens"},
+		{"ITERATR_BUDGET_MAX_COST", "budget.max_cost"},
+		{"ITERATR_BUDGET_MAX_DURATION", "budget.max_duration"},
+		{"ITERATR_BUDGET_ACTION", "budget.action"},
 	}
 
 	var envRows [][]string
@@ -176,3 +226,12 @@ func runConfig(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// formatPrice formats a configured model price in USD per million tokens.
+func formatPrice(p config.PriceConfig) string {
+	s := fmt.Sprintf("in $%g, out $%g", p.Input, p.Output)
+	if p.CacheRead > 0 || p.CacheWrite > 0 {
+		s += fmt.Sprintf(", cache read $%g, cache write $%g", p.CacheRead, p.CacheWrite)
+	}
+	return s + " /Mtok"
+}
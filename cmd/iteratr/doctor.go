@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"charm.land/lipgloss/v2"
 	"charm.land/lipgloss/v2/table"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +27,7 @@ var doctorCmd = &cobra.Command{
 This command verifies that:
 - opencode is installed and in PATH
 - The data directory is writable
+- No stored session is about to lose events to the retention max age
 - Other environment requirements are met`,
 	RunE: runDoctor,
 }
@@ -69,6 +79,9 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Check stored sessions against the retention max age
+	results = append(results, checkRetention())
+
 	// Build rows with status icons
 	rows := make([][]string, len(results))
 	for i, r := range results {
@@ -137,3 +150,133 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("doctor check failed")
 	}
 }
+
+// retentionWarnFraction is the fraction of the max age after which a
+// session's oldest events are reported as close to expiring.
+const retentionWarnFraction = 0.8
+
+// checkRetention reports sessions whose oldest events are close to being
+// removed by the stream's max age limit. It opens the existing stream
+// read-only, so running doctor never applies the configured retention.
+// Never fails the doctor run.
+func checkRetention() checkResult {
+	cfg, err := config.Load()
+	if err != nil {
+		return checkResult{name: "retention", status: "WARN", details: "Could not load config: " + err.Error()}
+	}
+
+	// Only inspect an existing data directory; doctor should not create one
+	dataDir := resolveDataDir("")
+	if _, err := os.Stat(filepath.Join(dataDir, "data")); err != nil {
+		return checkResult{name: "retention", status: "OK", details: fmt.Sprintf("Max age %d days, no stored sessions", cfg.Retention.MaxAgeDays)}
+	}
+
+	js, cleanup, err := connectJetStream(dataDir)
+	if err != nil {
+		return checkResult{name: "retention", status: "WARN", details: "Could not open event store: " + err.Error()}
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	stream, err := js.Stream(ctx, nats.StreamName)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrStreamNotFound) {
+			return checkResult{name: "retention", status: "OK", details: fmt.Sprintf("Max age %d days, no stored sessions", cfg.Retention.MaxAgeDays)}
+		}
+		return checkResult{name: "retention", status: "WARN", details: "Could not open event stream: " + err.Error()}
+	}
+
+	// Report the limits the stream enforces now; a changed config only takes
+	// effect once iteratr next opens the stream for writing
+	streamCfg := stream.CachedInfo().Config
+	maxAge := streamCfg.MaxAge
+	var pending string
+	if configured := cfg.Retention.MaxAge(); configured != maxAge {
+		pending = fmt.Sprintf(" (configured max age %d days applies when iteratr next opens the stream)", cfg.Retention.MaxAgeDays)
+	}
+	if maxAge <= 0 {
+		return withSizeLimits(checkResult{name: "retention", status: "OK", details: "Events kept forever" + pending}, streamCfg)
+	}
+
+	sessions, err := nats.ListSessions(ctx, stream)
+	if err != nil {
+		return checkResult{name: "retention", status: "WARN", details: "Could not list sessions: " + err.Error()}
+	}
+	oldest := make(map[string]time.Time, len(sessions))
+	for _, name := range sessions {
+		t, err := nats.OldestEventTime(ctx, stream, name)
+		if err != nil {
+			return checkResult{name: "retention", status: "WARN", details: "Could not read session '" + name + "': " + err.Error()}
+		}
+		oldest[name] = t
+	}
+
+	result := retentionResult(oldest, time.Now(), maxAge)
+	result.details += pending
+	return withSizeLimits(result, streamCfg)
+}
+
+// withSizeLimits turns result into a warning when the stream caps its total
+// size or the events per subject. Either cap drops a session's oldest events
+// (its first task-add events) while keeping later ones, which corrupts the
+// replayed state instead of expiring the session as a whole.
+func withSizeLimits(result checkResult, cfg jetstream.StreamConfig) checkResult {
+	var limits []string
+	if cfg.MaxBytes > 0 {
+		limits = append(limits, fmt.Sprintf("retention.max_bytes=%d", cfg.MaxBytes))
+	}
+	if cfg.MaxMsgsPerSubject > 0 {
+		limits = append(limits, fmt.Sprintf("retention.max_msgs_per_subject=%d", cfg.MaxMsgsPerSubject))
+	}
+	if len(limits) == 0 {
+		return result
+	}
+	result.status = "WARN"
+	result.details += fmt.Sprintf(". Size limits in effect (%s) drop sessions' oldest events and can corrupt their state; export sessions you need to keep", strings.Join(limits, ", "))
+	return result
+}
+
+// retentionResult builds the retention check result from the oldest event
+// time of each session. Sessions past retentionWarnFraction of maxAge are
+// listed with the time left before their first events expire.
+func retentionResult(oldest map[string]time.Time, now time.Time, maxAge time.Duration) checkResult {
+	threshold := time.Duration(float64(maxAge) * retentionWarnFraction)
+
+	var expiring []string
+	for name, t := range oldest {
+		if t.IsZero() {
+			continue
+		}
+		if now.Sub(t) >= threshold {
+			expiring = append(expiring, name)
+		}
+	}
+
+	days := int(maxAge / (24 * time.Hour))
+	if len(expiring) == 0 {
+		return checkResult{name: "retention", status: "OK", details: fmt.Sprintf("Max age %d days, %d sessions within limit", days, len(oldest))}
+	}
+
+	sort.Strings(expiring)
+	parts := make([]string, len(expiring))
+	for i, name := range expiring {
+		left := maxAge - now.Sub(oldest[name])
+		if left < 0 {
+			left = 0
+		}
+		parts[i] = fmt.Sprintf("%s (%s left)", name, formatRetentionLeft(left))
+	}
+	return checkResult{
+		name:    "retention",
+		status:  "WARN",
+		details: fmt.Sprintf("Events expiring soon (max age %d days): %s. Export with 'iteratr session export' or raise retention.max_age_days", days, strings.Join(parts, ", ")),
+	}
+}
+
+// formatRetentionLeft formats time remaining as whole days, or hours when under a day.
+func formatRetentionLeft(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%dh", int(d/time.Hour))
+}
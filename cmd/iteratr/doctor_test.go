@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestRetentionResult(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	maxAge := 30 * day
+
+	t.Run("no sessions", func(t *testing.T) {
+		r := retentionResult(map[string]time.Time{}, now, maxAge)
+		if r.status != "OK" {
+			t.Errorf("status = %q, want OK", r.status)
+		}
+	})
+
+	t.Run("recent sessions are ok", func(t *testing.T) {
+		r := retentionResult(map[string]time.Time{
+			"a": now.Add(-2 * day),
+			"b": now.Add(-20 * day),
+		}, now, maxAge)
+		if r.status != "OK" {
+			t.Errorf("status = %q, want OK (details: %s)", r.status, r.details)
+		}
+		if !strings.Contains(r.details, "2 sessions") {
+			t.Errorf("details = %q, want session count", r.details)
+		}
+	})
+
+	t.Run("old sessions warn with time left", func(t *testing.T) {
+		r := retentionResult(map[string]time.Time{
+			"fresh":  now.Add(-day),
+			"old":    now.Add(-27 * day),
+			"ending": now.Add(-30*day + 5*time.Hour),
+		}, now, maxAge)
+		if r.status != "WARN" {
+			t.Fatalf("status = %q, want WARN", r.status)
+		}
+		if !strings.Contains(r.details, "ending (5h left), old (3d left)") {
+			t.Errorf("details = %q, want sorted sessions with time left", r.details)
+		}
+		if strings.Contains(r.details, "fresh") {
+			t.Errorf("details = %q, should not list fresh session", r.details)
+		}
+	})
+
+	t.Run("empty session is ignored", func(t *testing.T) {
+		r := retentionResult(map[string]time.Time{"empty": {}}, now, maxAge)
+		if r.status != "OK" {
+			t.Errorf("status = %q, want OK", r.status)
+		}
+	})
+}
+
+func TestWithSizeLimits(t *testing.T) {
+	ok := checkResult{name: "retention", status: "OK", details: "Max age 30 days, 2 sessions within limit"}
+
+	t.Run("unlimited stream is unchanged", func(t *testing.T) {
+		r := withSizeLimits(ok, jetstream.StreamConfig{MaxBytes: -1, MaxMsgsPerSubject: -1})
+		if r != ok {
+			t.Errorf("withSizeLimits() = %+v, want %+v", r, ok)
+		}
+	})
+
+	t.Run("size caps warn", func(t *testing.T) {
+		r := withSizeLimits(ok, jetstream.StreamConfig{MaxBytes: 1 << 20, MaxMsgsPerSubject: 500})
+		if r.status != "WARN" {
+			t.Fatalf("status = %q, want WARN", r.status)
+		}
+		for _, want := range []string{"2 sessions within limit", "retention.max_bytes=1048576", "retention.max_msgs_per_subject=500", "corrupt"} {
+			if !strings.Contains(r.details, want) {
+				t.Errorf("details = %q, want %q", r.details, want)
+			}
+		}
+	})
+}
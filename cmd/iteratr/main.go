@@ -51,7 +51,8 @@ Orchestrates AI coding agents in an iterative loop.
 Getting Started:
   iteratr setup  - create config
   iteratr build  - start session
-  iteratr config - view settings`
+  iteratr config - view settings
+  iteratr status - inspect a running session`
 
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(specCmd)
@@ -59,4 +60,9 @@ Getting Started:
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(setupCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(sessionCmd)
+	rootCmd.AddCommand(tasksCmd)
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(transcriptCmd)
 }
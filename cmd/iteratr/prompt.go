@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	udiff "github.com/aymanbagabas/go-udiff"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/orchestrator"
+	"github.com/mark3labs/iteratr/internal/template"
+	"github.com/spf13/cobra"
+)
+
+var promptFlags struct {
+	name              string
+	spec              string
+	template          string
+	templateMode      string
+	extraInstructions string
+	promptBudget      int
+	dataDir           string
+	iteration         int
+	diff              bool
+	runHooks          bool
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect session prompts",
+}
+
+var promptPreviewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Print the prompt the next iteration would receive",
+	Long: `Render the prompt the next iteration of a session would receive, without
+starting the agent.
+
+The session state is loaded from the data directory (whether or not an
+iteratr build is running) and rendered with the same template, template mode,
+and prompt budget as 'iteratr build'. Fresh sessions get the planning prompt
+of iteration #0. Hooks are not run by default, so the preview has no side
+effects. With --run-hooks the session_start and pre_iteration hooks run for
+real, including ones that are not piped, and their piped output, which the
+agent receives before the prompt, is printed first.
+
+The prompt goes to stdout, its estimated size and any trimming to stderr.
+With --diff the prompt is compared to the saved prompt of the previous
+iteration instead (the last 10 prompts of each session are kept).`,
+	RunE: runPromptPreview,
+}
+
+func init() {
+	promptCmd.AddCommand(promptPreviewCmd)
+
+	promptPreviewCmd.Flags().StringVarP(&promptFlags.name, "name", "n", "", "Session name (default: spec filename stem)")
+	promptPreviewCmd.Flags().StringVarP(&promptFlags.spec, "spec", "s", "", "Spec file path (default: ./specs/SPEC.md)")
+	promptPreviewCmd.Flags().StringVarP(&promptFlags.template, "template", "t", "", "Custom template file (overrides config file)")
+	promptPreviewCmd.Flags().StringVar(&promptFlags.templateMode, "template-mode", "", "Template rendering: auto, legacy, or go (overrides config file, default: auto)")
+	promptPreviewCmd.Flags().StringVarP(&promptFlags.extraInstructions, "extra-instructions", "e", "", "Extra instructions for prompt")
+	promptPreviewCmd.Flags().IntVar(&promptFlags.promptBudget, "prompt-budget", 0, "Trim session state in the prompt to about this many tokens, 0=unlimited (overrides config file)")
+	promptPreviewCmd.Flags().StringVar(&promptFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	promptPreviewCmd.Flags().IntVar(&promptFlags.iteration, "iteration", -1, "Iteration to render (default: the next one)")
+	promptPreviewCmd.Flags().BoolVar(&promptFlags.diff, "diff", false, "Show a diff against the previous iteration's saved prompt")
+	promptPreviewCmd.Flags().BoolVar(&promptFlags.runHooks, "run-hooks", false, "Run session_start and pre_iteration hooks for their piped output (hooks run for real)")
+}
+
+func runPromptPreview(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Flags override config, as in build
+	if !cmd.Flags().Changed("template") {
+		promptFlags.template = cfg.Template
+	}
+	if !cmd.Flags().Changed("template-mode") {
+		promptFlags.templateMode = cfg.TemplateMode
+	}
+	if !cmd.Flags().Changed("prompt-budget") {
+		promptFlags.promptBudget = cfg.PromptBudget
+	}
+	if !template.IsValidMode(promptFlags.templateMode) {
+		return fmt.Errorf("template mode must be %q, %q, or %q, got %q", template.ModeAuto, template.ModeLegacy, template.ModeGo, promptFlags.templateMode)
+	}
+	if promptFlags.promptBudget < 0 {
+		return fmt.Errorf("prompt budget must be >= 0 (0 means unlimited)")
+	}
+
+	specPath := promptFlags.spec
+	if specPath == "" {
+		if _, err := os.Stat("specs/SPEC.md"); err == nil {
+			specPath = "specs/SPEC.md"
+		}
+	} else if _, err := os.Stat(specPath); err != nil {
+		return fmt.Errorf("spec file not found: %s", specPath)
+	}
+	sessionName := promptFlags.name
+	if sessionName == "" {
+		sessionName = sessionNameFromSpec(specPath)
+	}
+	if err := validateSessionName(sessionName); err != nil {
+		return err
+	}
+
+	dataDir := resolveDataDir(promptFlags.dataDir)
+	store, cleanup, err := openSessionStore(dataDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// The port of a running build, if any; the prompt shows it to the agent
+	port, _ := nats.ReadPort(filepath.Join(dataDir, "data"))
+
+	preview, err := orchestrator.PreviewPrompt(context.Background(), store, orchestrator.Config{
+		SessionName:       sessionName,
+		SpecPath:          specPath,
+		TemplatePath:      promptFlags.template,
+		TemplateMode:      promptFlags.templateMode,
+		TemplateVars:      cfg.TemplateVars,
+		PromptBudget:      promptFlags.promptBudget,
+		ExtraInstructions: promptFlags.extraInstructions,
+		Iterations:        cfg.Iterations,
+		DataDir:           dataDir,
+		Model:             cfg.Model,
+		Backend:           cfg.Backend,
+		AutoCommit:        cfg.AutoCommit,
+		CommitMode:        cfg.CommitMode,
+		Review:            cfg.Review,
+		SpecSync:          cfg.SpecSync,
+		Worktree:          cfg.Worktree,
+	}, orchestrator.PreviewOptions{
+		Iteration: promptFlags.iteration,
+		RunHooks:  promptFlags.runHooks,
+		NATSPort:  port,
+	})
+	if err != nil {
+		return err
+	}
+
+	if promptFlags.diff {
+		if preview.Iteration == 0 {
+			return fmt.Errorf("iteration #0 has no previous iteration to diff against")
+		}
+		previous, err := orchestrator.LoadPrompt(dataDir, sessionName, preview.Iteration-1)
+		if err != nil {
+			return err
+		}
+		diff := udiff.Unified(
+			fmt.Sprintf("iteration #%d", preview.Iteration-1),
+			fmt.Sprintf("iteration #%d (preview)", preview.Iteration),
+			previous, preview.Prompt)
+		if diff == "" {
+			fmt.Fprintf(os.Stderr, "Prompt unchanged since iteration #%d\n", preview.Iteration-1)
+			return nil
+		}
+		fmt.Print(diff)
+		return nil
+	}
+
+	if preview.HookOutput != "" {
+		fmt.Printf("%s\n\n", preview.HookOutput)
+	}
+	fmt.Println(preview.Prompt)
+
+	fmt.Fprintf(os.Stderr, "\nIteration #%d prompt: %d characters (~%d tokens)", preview.Iteration, len(preview.Prompt), template.EstimateTokens(preview.Prompt))
+	if preview.HookOutput != "" {
+		fmt.Fprintf(os.Stderr, ", after %d characters of hook output", len(preview.HookOutput))
+	}
+	fmt.Fprintln(os.Stderr)
+	for _, trimmed := range preview.Report.Trimmed {
+		fmt.Fprintf(os.Stderr, "Trimmed to fit budget of %d tokens: %s\n", preview.Report.Budget, trimmed)
+	}
+	return nil
+}
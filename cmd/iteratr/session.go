@@ -0,0 +1,413 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/orchestrator"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/spf13/cobra"
+)
+
+var sessionFlags struct {
+	dataDir string
+}
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Manage stored sessions",
+	Long: `Manage sessions stored in the data directory.
+
+These commands work whether or not an iteratr build is running: they connect
+to the running NATS server if there is one, or start a temporary one.`,
+}
+
+func init() {
+	sessionCmd.AddCommand(sessionExportCmd)
+	sessionCmd.AddCommand(sessionImportCmd)
+	sessionCmd.AddCommand(sessionForkCmd)
+	sessionCmd.AddCommand(sessionRollbackCmd)
+
+	sessionCmd.PersistentFlags().StringVar(&sessionFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+}
+
+// openSessionStore connects to the NATS server for a data directory, starting a
+// temporary embedded server if none is running. The returned cleanup function
+// closes the connection and shuts down the server if this call started it.
+func openSessionStore(dataDir string) (*session.Store, func(), error) {
+	js, stream, cleanup, err := openEventStream(dataDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	store := session.NewStore(js, stream)
+	store.SetRetention(loadRetention())
+	return store, cleanup, nil
+}
+
+// openEventStream is openSessionStore without the session.Store wrapper, for
+// callers that inspect the stream directly.
+func openEventStream(dataDir string) (jetstream.JetStream, jetstream.Stream, func(), error) {
+	js, shutdown, err := connectJetStream(dataDir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	stream, err := nats.SetupStreamWithRetention(context.Background(), js, loadRetention())
+	if err != nil {
+		shutdown()
+		return nil, nil, nil, fmt.Errorf("failed to setup stream: %w", err)
+	}
+	return js, stream, shutdown, nil
+}
+
+// connectJetStream connects to the NATS server for a data directory, starting a
+// temporary embedded server if none is running. It does not create or update
+// the event stream, so read-only callers leave its configuration untouched.
+func connectJetStream(dataDir string) (jetstream.JetStream, func(), error) {
+	serverDataDir := filepath.Join(dataDir, "data")
+	if err := os.MkdirAll(serverDataDir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	nc := nats.TryConnectExisting(serverDataDir)
+	startedServer := false
+	var shutdown func()
+	if nc == nil {
+		logger.Debug("No running NATS server, starting temporary one")
+		ns, port, err := nats.StartEmbeddedNATS(serverDataDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to start NATS: %w", err)
+		}
+		nc, err = nats.ConnectToPort(port)
+		if err != nil {
+			ns.Shutdown()
+			return nil, nil, fmt.Errorf("failed to connect to NATS: %w", err)
+		}
+		startedServer = true
+		shutdown = func() {
+			// Close rather than drain: short-lived commands have nothing in flight
+			nc.Close()
+			if err := nats.Shutdown(nil, ns); err != nil {
+				logger.Warn("Failed to shut down temporary NATS server: %v", err)
+			}
+		}
+	} else {
+		shutdown = nc.Close
+	}
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		shutdown()
+		return nil, nil, fmt.Errorf("failed to create JetStream: %w", err)
+	}
+
+	logger.Debug("Connected to JetStream (started server: %v)", startedServer)
+	return js, shutdown, nil
+}
+
+// session export command
+var sessionExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Export a session as a portable event archive",
+	Long: `Export every event of a session to a versioned JSONL archive.
+
+The archive can be imported into another data directory with 'iteratr session import'.
+Files ending in .gz are gzip-compressed. Use --output - to write to stdout.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			output = name + ".iteratr.jsonl"
+		}
+
+		store, cleanup, err := openSessionStore(resolveDataDir(sessionFlags.dataDir))
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		var w io.Writer = os.Stdout
+		if output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create archive file: %w", err)
+			}
+			defer func() { _ = f.Close() }()
+			w = f
+
+			if strings.HasSuffix(output, ".gz") {
+				gz := gzip.NewWriter(f)
+				defer func() { _ = gz.Close() }()
+				w = gz
+			}
+		}
+
+		count, err := store.ExportSession(context.Background(), name, w)
+		if err != nil {
+			if output != "-" {
+				_ = os.Remove(output)
+			}
+			return err
+		}
+
+		if output != "-" {
+			fmt.Printf("Exported %d events from session '%s' to %s\n", count, name, output)
+		}
+		return nil
+	},
+}
+
+func init() {
+	sessionExportCmd.Flags().StringP("output", "o", "", "Archive path (default: <name>.iteratr.jsonl, - for stdout)")
+}
+
+// session import command
+var sessionImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a session from an event archive",
+	Long: `Replay the events of a session archive into the data directory.
+
+By default the session keeps the name it was exported with; use --name to rename it.
+Importing into an existing session fails unless --force is given, in which case
+the existing session's events are replaced. The archive is checked before
+anything is replaced, so a bad archive leaves the existing session untouched.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		name, _ := cmd.Flags().GetString("name")
+		force, _ := cmd.Flags().GetBool("force")
+
+		header, events, err := readArchiveFile(path)
+		if err != nil {
+			return err
+		}
+
+		if name == "" {
+			name = header.Session
+		}
+		if err := validateSessionName(name); err != nil {
+			return err
+		}
+
+		store, cleanup, err := openSessionStore(resolveDataDir(sessionFlags.dataDir))
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		err = store.ImportSession(context.Background(), session.ImportParams{
+			Name:   name,
+			Force:  force,
+			Events: events,
+		})
+		if err != nil {
+			return err
+		}
+
+		if name != header.Session {
+			fmt.Printf("Imported %d events from session '%s' as '%s'\n", len(events), header.Session, name)
+		} else {
+			fmt.Printf("Imported %d events into session '%s'\n", len(events), name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	sessionImportCmd.Flags().String("name", "", "Import under a different session name")
+	sessionImportCmd.Flags().Bool("force", false, "Replace the session if it already exists")
+}
+
+// session fork command
+var sessionForkCmd = &cobra.Command{
+	Use:   "fork <source> <target>",
+	Short: "Copy a session up to an iteration into a new session",
+	Long: `Create a new session from the events of an existing one, up to the end of
+iteration N. The source session is left untouched.
+
+Use it to rewind after the agent goes down a bad path, then resume the fork
+with 'iteratr build --name <target>' and different extra instructions or a
+different model. Only session history is copied; the working tree is not
+changed.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, target := args[0], args[1]
+		atIteration, _ := cmd.Flags().GetInt("at-iteration")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if atIteration < 0 {
+			return fmt.Errorf("--at-iteration must be >= 0")
+		}
+		if err := validateSessionName(target); err != nil {
+			return err
+		}
+
+		store, cleanup, err := openSessionStore(resolveDataDir(sessionFlags.dataDir))
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		count, err := store.ForkSession(context.Background(), session.ForkParams{
+			Source:      source,
+			Target:      target,
+			AtIteration: atIteration,
+			Force:       force,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Forked session '%s' at iteration %d into '%s' (%d events)\n", source, atIteration, target, count)
+		fmt.Printf("Resume with: iteratr build --name %s\n", target)
+		return nil
+	},
+}
+
+func init() {
+	sessionForkCmd.Flags().Int("at-iteration", 0, "Last iteration to keep (required)")
+	sessionForkCmd.Flags().Bool("force", false, "Replace the target session if it already exists")
+	_ = sessionForkCmd.MarkFlagRequired("at-iteration")
+}
+
+var sessionRollbackCmd = &cobra.Command{
+	Use:   "rollback <name>",
+	Short: "Throw away the iterations of a session after an iteration",
+	Long: `Undo everything a session did after iteration N. The working tree is
+restored from the checkpoint iteratr records before each iteration: files the
+later iterations changed or deleted are written back and files they created
+are removed. Ignored files, HEAD, and the index are not touched.
+
+The session's event log is append-only, so compensating events are appended
+that bring tasks, notes, and the completion flag back to their state at the
+end of iteration N; the later iterations stay in the history marked as rolled
+back. The working tree from before the rollback is kept under
+refs/iteratr/<name>/pre-rollback.
+
+The session must not be running: rollback refuses while an iteratr build is
+running in the data directory or the session's last iteration never finished
+(use ctrl+x u in the build's TUI instead). Pass --force after a crashed build.
+Outside a git repository only the session events are rolled back.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		toIteration, _ := cmd.Flags().GetInt("to-iteration")
+		workDir, _ := cmd.Flags().GetString("work-dir")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if toIteration < 0 {
+			return fmt.Errorf("--to-iteration must be >= 0")
+		}
+		dataDir := resolveDataDir(sessionFlags.dataDir)
+		if workDir == "" {
+			// Sessions built with --worktree run in <data_dir>/worktrees/<name>
+			workDir = "."
+			if wt := filepath.Join(dataDir, "worktrees", name); fileExists(wt) {
+				workDir = wt
+			}
+		}
+
+		// Check for a running build before openSessionStore starts a server of its own
+		buildRunning := false
+		if nc := nats.TryConnectExisting(filepath.Join(dataDir, "data")); nc != nil {
+			nc.Close()
+			buildRunning = true
+		}
+
+		store, cleanup, err := openSessionStore(dataDir)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if !force {
+			state, err := store.LoadState(context.Background(), name)
+			if err != nil {
+				return fmt.Errorf("failed to load session state: %w", err)
+			}
+			if err := checkSessionIdle(state, buildRunning); err != nil {
+				return err
+			}
+		}
+
+		report, err := orchestrator.Rollback(context.Background(), store, orchestrator.RollbackOptions{
+			Session:     name,
+			ToIteration: toIteration,
+			WorkDir:     workDir,
+			DataDir:     dataDir,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Rolled back session '%s' to iteration %d (%d iterations discarded)\n", name, toIteration, len(report.Iterations))
+		if report.Files == nil {
+			fmt.Printf("%s is not a git repository, files were not restored\n", workDir)
+			return nil
+		}
+		fmt.Printf("Files: %d restored, %d removed in %s\n", len(report.Files.Restored), len(report.Files.Removed), workDir)
+		if report.Files.HeadMoved {
+			fmt.Println("Note: commits made after the checkpoint are still in history; their changes now show as local modifications")
+		}
+		fmt.Printf("Previous working tree saved as %s\n", report.SavedRef)
+		return nil
+	},
+}
+
+func init() {
+	sessionRollbackCmd.Flags().Int("to-iteration", 0, "Last iteration to keep (required)")
+	sessionRollbackCmd.Flags().String("work-dir", "", "Working tree to restore (default: the session's worktree if it exists, else the current directory)")
+	sessionRollbackCmd.Flags().Bool("force", false, "Roll back even if a build may be running the session")
+	_ = sessionRollbackCmd.MarkFlagRequired("to-iteration")
+}
+
+// checkSessionIdle returns an error if a build may still be running a session:
+// an iteratr server is running for the data directory, or the session's last
+// iteration started but never finished.
+func checkSessionIdle(state *session.State, buildRunning bool) error {
+	if buildRunning {
+		return fmt.Errorf("an iteratr build is running in this data directory; stop it first, or use ctrl+x u in its TUI (--force to override)")
+	}
+	for i := len(state.Iterations) - 1; i >= 0; i-- {
+		iter := state.Iterations[i]
+		if iter.RolledBack {
+			continue
+		}
+		if !iter.Complete && !iter.TimedOut && !state.Complete {
+			return fmt.Errorf("iteration %d of session '%s' never finished; if its build crashed, rerun with --force", iter.Number, state.Session)
+		}
+		break
+	}
+	return nil
+}
+
+// readArchiveFile opens and decodes a session archive, transparently
+// decompressing files that end in .gz.
+func readArchiveFile(path string) (*session.ArchiveHeader, []session.Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+
+	return session.ReadArchive(r)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestCheckSessionIdle(t *testing.T) {
+	tests := []struct {
+		name         string
+		iterations   []*session.Iteration
+		complete     bool
+		buildRunning bool
+		wantErr      string
+	}{
+		{name: "no iterations"},
+		{name: "last iteration finished", iterations: []*session.Iteration{{Number: 0, Complete: true}, {Number: 1, Complete: true}}},
+		{name: "last iteration timed out", iterations: []*session.Iteration{{Number: 1, TimedOut: true}}},
+		{name: "last iteration running", iterations: []*session.Iteration{{Number: 0, Complete: true}, {Number: 1}}, wantErr: "iteration 1 of session 's' never finished"},
+		{name: "rolled back iteration ignored", iterations: []*session.Iteration{{Number: 0, Complete: true}, {Number: 1, RolledBack: true}}},
+		{name: "complete session", iterations: []*session.Iteration{{Number: 1}}, complete: true},
+		{name: "build running", iterations: []*session.Iteration{{Number: 1, Complete: true}}, buildRunning: true, wantErr: "build is running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &session.State{Session: "s", Iterations: tt.iterations, Complete: tt.complete}
+			err := checkSessionIdle(state, tt.buildRunning)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkSessionIdle() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkSessionIdle() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var statusFlags struct {
+	json bool
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of a running session",
+	Long: `Show the current status of a running iteratr session.
+
+Connects to the session's NATS server (via the port file in the data directory)
+and prints the current iteration, in-progress task, task counts by status,
+the last iteration summary, elapsed time, and token usage with estimated cost.
+
+Use --json for machine-readable output (e.g. for CI polling).`,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(&toolFlags.name, "name", "n", "", "Session name (required)")
+	statusCmd.Flags().StringVar(&toolFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	statusCmd.Flags().BoolVar(&statusFlags.json, "json", false, "Output status as JSON")
+}
+
+// statusReport is the structured status of a session.
+// Field names double as the JSON schema for --json output.
+type statusReport struct {
+	Session          string                   `json:"session"`
+	Complete         bool                     `json:"complete"`
+	Model            string                   `json:"model,omitempty"`
+	Iteration        int                      `json:"iteration"`
+	IterationRunning bool                     `json:"iteration_running"`
+	IterationTimeout string                   `json:"iteration_timeout,omitempty"`
+	IterationElapsed string                   `json:"iteration_elapsed,omitempty"`
+	InProgress       *statusTask              `json:"in_progress,omitempty"`
+	TaskCounts       map[string]int           `json:"task_counts"`
+	TasksTotal       int                      `json:"tasks_total"`
+	LastSummary      string                   `json:"last_summary,omitempty"`
+	LastSummaryIter  int                      `json:"last_summary_iteration,omitempty"`
+	StartedAt        time.Time                `json:"started_at,omitzero"`
+	Elapsed          string                   `json:"elapsed"`
+	ElapsedSeconds   int64                    `json:"elapsed_seconds"`
+	Usage            session.Usage            `json:"usage"`
+	TaskUsage        map[string]session.Usage `json:"task_usage,omitempty"`
+}
+
+// statusTask is the subset of task fields shown in status output.
+type statusTask struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+}
+
+// statusTaskStatuses lists task statuses in display order.
+var statusTaskStatuses = []string{"remaining", "in_progress", "completed", "blocked", "cancelled"}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	if toolFlags.name == "" {
+		return fmt.Errorf("session name is required (--name)")
+	}
+
+	store, cleanup, err := connectToSession()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	state, err := store.LoadState(context.Background(), toolFlags.name)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(state.Iterations) == 0 && len(state.Tasks) == 0 && len(state.Notes) == 0 {
+		return fmt.Errorf("session not found: %s", toolFlags.name)
+	}
+
+	report := buildStatusReport(state, time.Now())
+
+	if statusFlags.json {
+		output, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal status: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	printStatusReport(report)
+	return nil
+}
+
+// buildStatusReport derives a status report from session state.
+// The current iteration is the last one recorded; it is considered running
+// until its complete event has been applied.
+func buildStatusReport(state *session.State, now time.Time) statusReport {
+	report := statusReport{
+		Session:    state.Session,
+		Complete:   state.Complete,
+		Model:      state.Model,
+		TaskCounts: make(map[string]int, len(statusTaskStatuses)),
+		TasksTotal: len(state.Tasks),
+	}
+	for _, status := range statusTaskStatuses {
+		report.TaskCounts[status] = 0
+	}
+	report.Usage = state.Usage
+	if taskUsage := state.TaskUsage(); len(taskUsage) > 0 {
+		report.TaskUsage = taskUsage
+	}
+
+	// Count tasks and find the in-progress one (lowest ID for determinism)
+	var inProgress []*session.Task
+	for _, task := range state.Tasks {
+		report.TaskCounts[task.Status]++
+		if task.Status == "in_progress" {
+			inProgress = append(inProgress, task)
+		}
+	}
+	if len(inProgress) > 0 {
+		sort.Slice(inProgress, func(i, j int) bool { return inProgress[i].ID < inProgress[j].ID })
+		report.InProgress = &statusTask{ID: inProgress[0].ID, Content: inProgress[0].Content}
+	}
+
+	if len(state.Iterations) == 0 {
+		report.Elapsed = formatElapsed(0)
+		return report
+	}
+
+	// Current iteration
+	current := state.Iterations[len(state.Iterations)-1]
+	report.Iteration = current.Number
+	report.IterationRunning = !current.Complete && !current.TimedOut && !state.Complete
+	if current.TimedOut {
+		report.IterationTimeout = current.TimeoutKind
+	}
+	if report.IterationRunning {
+		report.IterationElapsed = formatElapsed(now.Sub(current.StartedAt))
+	}
+
+	// Last iteration summary (most recent kept iteration that recorded one)
+	for i := len(state.Iterations) - 1; i >= 0; i-- {
+		if state.Iterations[i].Summary != "" && !state.Iterations[i].RolledBack {
+			report.LastSummary = state.Iterations[i].Summary
+			report.LastSummaryIter = state.Iterations[i].Number
+			break
+		}
+	}
+
+	// Elapsed time spans from the first iteration start to now, or to the
+	// end of the last iteration once the session is complete.
+	report.StartedAt = state.Iterations[0].StartedAt
+	end := now
+	if state.Complete && !current.EndedAt.IsZero() {
+		end = current.EndedAt
+	}
+	elapsed := end.Sub(report.StartedAt)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	report.Elapsed = formatElapsed(elapsed)
+	report.ElapsedSeconds = int64(elapsed / time.Second)
+
+	return report
+}
+
+// printStatusReport renders a status report as a styled table.
+func printStatusReport(r statusReport) {
+	state := "running"
+	if r.Complete {
+		state = "complete"
+	} else if !r.IterationRunning {
+		state = "idle"
+	}
+
+	iteration := "#" + strconv.Itoa(r.Iteration)
+	if r.IterationRunning {
+		iteration += " (running " + r.IterationElapsed + ")"
+	} else if r.IterationTimeout != "" {
+		iteration += " (" + strings.ReplaceAll(r.IterationTimeout, "_", " ") + ")"
+	}
+
+	inProgress := "-"
+	if r.InProgress != nil {
+		inProgress = fmt.Sprintf("[%s] %s", r.InProgress.ID, r.InProgress.Content)
+	}
+
+	lastSummary := "-"
+	if r.LastSummary != "" {
+		lastSummary = fmt.Sprintf("#%d: %s", r.LastSummaryIter, r.LastSummary)
+	}
+
+	rows := [][]string{
+		{"Session", r.Session},
+		{"State", state},
+		{"Iteration", iteration},
+		{"In progress", inProgress},
+	}
+	if r.Model != "" {
+		rows = append(rows, []string{"Model", r.Model})
+	}
+	for _, status := range statusTaskStatuses {
+		rows = append(rows, []string{"Tasks " + status, strconv.Itoa(r.TaskCounts[status])})
+	}
+	rows = append(rows,
+		[]string{"Tasks total", strconv.Itoa(r.TasksTotal)},
+		[]string{"Last summary", lastSummary},
+		[]string{"Elapsed", r.Elapsed},
+	)
+	if !r.Usage.IsZero() {
+		rows = append(rows, []string{"Tokens", fmt.Sprintf("%s in / %s out",
+			session.FormatTokens(r.Usage.InputTokens), session.FormatTokens(r.Usage.OutputTokens))})
+		if r.Usage.Cost > 0 {
+			rows = append(rows, []string{"Est. cost", fmt.Sprintf("$%.2f", r.Usage.Cost)})
+		}
+	}
+	taskIDs := make([]string, 0, len(r.TaskUsage))
+	for id, usage := range r.TaskUsage {
+		if usage.Cost > 0 {
+			taskIDs = append(taskIDs, id)
+		}
+	}
+	sort.Strings(taskIDs)
+	for _, id := range taskIDs {
+		rows = append(rows, []string{"Est. cost " + id, fmt.Sprintf("$%.2f", r.TaskUsage[id].Cost)})
+	}
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(colorBorder)).
+		Headers("Field", "Value").
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return lipgloss.NewStyle().
+					Foreground(colorPrimary).
+					Bold(true).
+					Padding(0, 1)
+			}
+			style := lipgloss.NewStyle().Padding(0, 1)
+			if col == 0 {
+				return style.Foreground(colorBase)
+			}
+			if row == 1 {
+				switch state {
+				case "complete":
+					return style.Foreground(colorSuccess)
+				case "running":
+					return style.Foreground(colorWarning)
+				}
+			}
+			return style.Foreground(colorMuted)
+		})
+
+	fmt.Println(t)
+}
+
+// formatElapsed formats a duration as H:MM:SS or M:SS.
+func formatElapsed(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	sec := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestBuildStatusReport(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	now := start.Add(90 * time.Minute)
+
+	t.Run("empty session", func(t *testing.T) {
+		report := buildStatusReport(&session.State{Session: "s", Tasks: map[string]*session.Task{}}, now)
+		if report.Iteration != 0 || report.IterationRunning {
+			t.Errorf("expected no iteration, got %d (running=%v)", report.Iteration, report.IterationRunning)
+		}
+		if report.Elapsed != "0:00" {
+			t.Errorf("expected elapsed 0:00, got %s", report.Elapsed)
+		}
+		if report.TaskCounts["remaining"] != 0 {
+			t.Errorf("expected zero remaining, got %d", report.TaskCounts["remaining"])
+		}
+	})
+
+	t.Run("running iteration", func(t *testing.T) {
+		state := &session.State{
+			Session: "s",
+			Tasks: map[string]*session.Task{
+				"TAS-1": {ID: "TAS-1", Content: "done", Status: "completed"},
+				"TAS-2": {ID: "TAS-2", Content: "doing", Status: "in_progress"},
+				"TAS-3": {ID: "TAS-3", Content: "todo", Status: "remaining"},
+				"TAS-4": {ID: "TAS-4", Content: "todo too", Status: "remaining"},
+			},
+			Iterations: []*session.Iteration{
+				{Number: 0, StartedAt: start, EndedAt: start.Add(time.Minute), Complete: true, Summary: "planned"},
+				{Number: 1, StartedAt: start.Add(time.Minute), EndedAt: start.Add(30 * time.Minute), Complete: true, Summary: "did TAS-1"},
+				{Number: 2, StartedAt: start.Add(80 * time.Minute)},
+			},
+		}
+
+		report := buildStatusReport(state, now)
+		if report.Iteration != 2 || !report.IterationRunning {
+			t.Errorf("expected running iteration 2, got %d (running=%v)", report.Iteration, report.IterationRunning)
+		}
+		if report.IterationElapsed != "10:00" {
+			t.Errorf("expected iteration elapsed 10:00, got %s", report.IterationElapsed)
+		}
+		if report.InProgress == nil || report.InProgress.ID != "TAS-2" {
+			t.Errorf("expected in-progress TAS-2, got %+v", report.InProgress)
+		}
+		if report.TaskCounts["remaining"] != 2 || report.TaskCounts["completed"] != 1 {
+			t.Errorf("unexpected task counts: %v", report.TaskCounts)
+		}
+		if report.LastSummary != "did TAS-1" || report.LastSummaryIter != 1 {
+			t.Errorf("expected last summary from #1, got #%d %q", report.LastSummaryIter, report.LastSummary)
+		}
+		if report.Elapsed != "1:30:00" || report.ElapsedSeconds != 5400 {
+			t.Errorf("expected elapsed 1:30:00 (5400s), got %s (%ds)", report.Elapsed, report.ElapsedSeconds)
+		}
+	})
+
+	t.Run("timed out iteration is not running", func(t *testing.T) {
+		state := &session.State{
+			Session: "s",
+			Tasks:   map[string]*session.Task{},
+			Iterations: []*session.Iteration{
+				{Number: 1, StartedAt: start, EndedAt: start.Add(time.Hour), TimedOut: true, TimeoutKind: "idle_timeout"},
+			},
+		}
+
+		report := buildStatusReport(state, now)
+		if report.IterationRunning || report.IterationTimeout != "idle_timeout" {
+			t.Errorf("expected idle timeout, got running=%v timeout=%q", report.IterationRunning, report.IterationTimeout)
+		}
+	})
+
+	t.Run("complete session freezes elapsed", func(t *testing.T) {
+		state := &session.State{
+			Session:  "s",
+			Complete: true,
+			Tasks:    map[string]*session.Task{},
+			Iterations: []*session.Iteration{
+				{Number: 1, StartedAt: start, EndedAt: start.Add(20 * time.Minute), Complete: true},
+			},
+		}
+
+		report := buildStatusReport(state, now)
+		if report.IterationRunning {
+			t.Error("expected no running iteration for complete session")
+		}
+		if report.Elapsed != "20:00" {
+			t.Errorf("expected elapsed 20:00, got %s", report.Elapsed)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var tasksFlags struct {
+	dataDir string
+}
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks",
+	Short: "Manage session tasks",
+	Long: `Manage the tasks of sessions stored in the data directory.
+
+These commands work whether or not an iteratr build is running: they connect
+to the running NATS server if there is one, or start a temporary one.`,
+}
+
+func init() {
+	tasksCmd.AddCommand(tasksImportCmd)
+
+	tasksCmd.PersistentFlags().StringVar(&tasksFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+}
+
+// tasks import command
+var tasksImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import tasks from a markdown checklist or a GitHub issue export",
+	Long: `Add tasks to a session from a file instead of having the agent plan them.
+
+Markdown files (such as the spec) contribute their checklist items: "- [ ]" is
+a remaining task and "- [x]" a completed one. Items nested under another item
+become its dependencies, and markers like [P0]-[P4] or (critical), (high),
+(medium), (low), (backlog) set the priority.
+
+JSON files are read as a GitHub issue export, either from the REST API or from
+'gh issue list --json number,title,body,state,labels'. Closed issues are
+imported as completed, priority labels (P1, priority: high) set the priority,
+and "Depends on #N" / "Blocked by #N" in an issue body or a "- [ ] #N" task
+list item add a dependency on issue N.
+
+Tasks that already exist in the session are skipped, so the same file always
+yields the same task list. A new session with imported tasks skips the
+planning iteration (#0) when it is built.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		name, _ := cmd.Flags().GetString("name")
+		format, _ := cmd.Flags().GetString("format")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if name == "" {
+			name = sessionNameFromSpec(path)
+		}
+		if err := validateSessionName(name); err != nil {
+			return err
+		}
+
+		tasks, err := readTaskFile(path, format)
+		if err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			return fmt.Errorf("no tasks found in %s", path)
+		}
+
+		if dryRun {
+			printImportTasks(tasks)
+			return nil
+		}
+
+		store, cleanup, err := openSessionStore(resolveDataDir(tasksFlags.dataDir))
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		result, err := store.ImportTasks(context.Background(), name, tasks)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported %d tasks into session '%s' (%d dependencies", len(result.Added), name, result.Dependencies)
+		if result.Skipped > 0 {
+			fmt.Printf(", %d already present", result.Skipped)
+		}
+		if result.Duplicates > 0 {
+			fmt.Printf(", %d duplicates merged", result.Duplicates)
+		}
+		fmt.Println(")")
+		return nil
+	},
+}
+
+func init() {
+	tasksImportCmd.Flags().StringP("name", "n", "", "Session name (default: file name stem)")
+	tasksImportCmd.Flags().String("format", "auto", "File format: auto, markdown, or json (auto uses the file extension)")
+	tasksImportCmd.Flags().Bool("dry-run", false, "Print the parsed tasks without importing them")
+}
+
+// readTaskFile parses a task file in the given format. The auto format
+// treats .json files as issue exports and anything else as markdown.
+func readTaskFile(path, format string) ([]session.ImportTask, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task file: %w", err)
+	}
+
+	if format == "auto" {
+		format = "markdown"
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			format = "json"
+		}
+	}
+	switch format {
+	case "markdown", "md":
+		return session.ParseChecklist(bytes.NewReader(data))
+	case "json":
+		return session.ParseIssues(data)
+	default:
+		return nil, fmt.Errorf("invalid format %q (must be auto, markdown, or json)", format)
+	}
+}
+
+// printImportTasks lists parsed tasks with the positions they would get.
+func printImportTasks(tasks []session.ImportTask) {
+	for i, task := range tasks {
+		mark := " "
+		switch task.Status {
+		case "completed":
+			mark = "x"
+		case "cancelled":
+			mark = "-"
+		}
+		fmt.Printf("%3d. [%s] P%d %s", i+1, mark, task.Priority, task.Content)
+		if len(task.DependsOn) > 0 {
+			deps := make([]string, len(task.DependsOn))
+			for j, dep := range task.DependsOn {
+				deps[j] = fmt.Sprintf("%d", dep+1)
+			}
+			fmt.Printf(" (depends on %s)", strings.Join(deps, ", "))
+		}
+		fmt.Println()
+	}
+}
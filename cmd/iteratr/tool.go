@@ -36,6 +36,8 @@ func init() {
 	toolCmd.AddCommand(taskStatusCmd)
 	toolCmd.AddCommand(taskPriorityCmd)
 	toolCmd.AddCommand(taskDependsCmd)
+	toolCmd.AddCommand(taskDetailsCmd)
+	toolCmd.AddCommand(taskParentCmd)
 	toolCmd.AddCommand(taskListCmd)
 	toolCmd.AddCommand(taskNextCmd)
 	toolCmd.AddCommand(noteAddCmd)
@@ -50,17 +52,7 @@ func init() {
 
 // connectToSession connects to a running iteratr session's server
 func connectToSession() (*session.Store, func(), error) {
-	// Determine data directory with precedence: CLI flag > config > default
-	dataDir := toolFlags.dataDir
-	if dataDir == "" {
-		// Try loading from config (ignore errors, fall back to default)
-		if cfg, err := config.Load(); err == nil {
-			dataDir = cfg.DataDir
-		}
-	}
-	if dataDir == "" {
-		dataDir = ".iteratr"
-	}
+	dataDir := resolveDataDir(toolFlags.dataDir)
 
 	// Read port from port file
 	serverDataDir := dataDir + "/data"
@@ -94,6 +86,7 @@ func connectToSession() (*session.Store, func(), error) {
 
 	// Create store
 	store := session.NewStore(js, stream)
+	store.SetRetention(loadRetention())
 
 	// Return cleanup function
 	cleanup := func() {
@@ -103,6 +96,40 @@ func connectToSession() (*session.Store, func(), error) {
 	return store, cleanup, nil
 }
 
+// resolveDataDir determines the data directory with precedence: CLI flag > config > default.
+func resolveDataDir(flagValue string) string {
+	dataDir := flagValue
+	if dataDir == "" {
+		// Try loading from config (ignore errors, fall back to default)
+		if cfg, err := config.Load(); err == nil {
+			dataDir = cfg.DataDir
+		}
+	}
+	if dataDir == "" {
+		dataDir = ".iteratr"
+	}
+	return dataDir
+}
+
+// streamRetention converts configured retention limits to the stream's retention policy.
+func streamRetention(cfg *config.Config) nats.Retention {
+	return nats.Retention{
+		MaxAge:            cfg.Retention.MaxAge(),
+		MaxBytes:          cfg.Retention.MaxBytes,
+		MaxMsgsPerSubject: cfg.Retention.MaxMsgsPerSubject,
+	}
+}
+
+// loadRetention returns the configured retention policy, falling back to
+// the default if the config cannot be loaded.
+func loadRetention() nats.Retention {
+	cfg, err := config.Load()
+	if err != nil {
+		return nats.DefaultRetention()
+	}
+	return streamRetention(cfg)
+}
+
 // task-add command
 var taskAddCmd = &cobra.Command{
 	Use:   "task-add",
@@ -125,10 +152,21 @@ var taskAddCmd = &cobra.Command{
 		}
 		defer cleanup()
 
+		parent, _ := cmd.Flags().GetString("parent")
+		description, _ := cmd.Flags().GetString("description")
+		criteria, _ := cmd.Flags().GetStringArray("acceptance")
+		labels, _ := cmd.Flags().GetStringSlice("labels")
+		estimate, _ := cmd.Flags().GetString("estimate")
+
 		ctx := context.Background()
 		task, err := store.TaskAdd(ctx, toolFlags.name, session.TaskAddParams{
-			Content: content,
-			Status:  status,
+			Content:            content,
+			Status:             status,
+			ParentID:           parent,
+			Description:        description,
+			AcceptanceCriteria: criteria,
+			Labels:             labels,
+			Estimate:           estimate,
 		})
 		if err != nil {
 			return err
@@ -148,6 +186,17 @@ var taskAddCmd = &cobra.Command{
 func init() {
 	taskAddCmd.Flags().String("content", "", "Task content (required)")
 	taskAddCmd.Flags().String("status", "remaining", "Initial status")
+	taskAddCmd.Flags().String("parent", "", "Task ID this is a subtask of")
+	addTaskDetailFlags(taskAddCmd)
+}
+
+// addTaskDetailFlags registers the optional task detail flags shared by
+// task-add and task-details.
+func addTaskDetailFlags(cmd *cobra.Command) {
+	cmd.Flags().String("description", "", "Long-form task description")
+	cmd.Flags().StringArray("acceptance", nil, "Acceptance criterion (repeatable)")
+	cmd.Flags().StringSlice("labels", nil, "Comma-separated labels")
+	cmd.Flags().String("estimate", "", "Size estimate, e.g. 2h or M")
 }
 
 // task-batch-add command
@@ -166,8 +215,13 @@ var taskBatchAddCmd = &cobra.Command{
 
 		// Parse JSON array of task objects
 		var taskInputs []struct {
-			Content string `json:"content"`
-			Status  string `json:"status,omitempty"`
+			Content            string   `json:"content"`
+			Status             string   `json:"status,omitempty"`
+			ParentID           string   `json:"parent_id,omitempty"`
+			Description        string   `json:"description,omitempty"`
+			AcceptanceCriteria []string `json:"acceptance_criteria,omitempty"`
+			Labels             []string `json:"labels,omitempty"`
+			Estimate           string   `json:"estimate,omitempty"`
 		}
 		if err := json.Unmarshal([]byte(tasksJSON), &taskInputs); err != nil {
 			return fmt.Errorf("invalid tasks JSON: %w", err)
@@ -187,8 +241,13 @@ var taskBatchAddCmd = &cobra.Command{
 		params := make([]session.TaskAddParams, len(taskInputs))
 		for i, input := range taskInputs {
 			params[i] = session.TaskAddParams{
-				Content: input.Content,
-				Status:  input.Status,
+				Content:            input.Content,
+				Status:             input.Status,
+				ParentID:           input.ParentID,
+				Description:        input.Description,
+				AcceptanceCriteria: input.AcceptanceCriteria,
+				Labels:             input.Labels,
+				Estimate:           input.Estimate,
 			}
 		}
 
@@ -214,7 +273,7 @@ var taskBatchAddCmd = &cobra.Command{
 }
 
 func init() {
-	taskBatchAddCmd.Flags().String("tasks", "", `JSON array of tasks, e.g. [{"content":"Task 1"},{"content":"Task 2","status":"in_progress"}]`)
+	taskBatchAddCmd.Flags().String("tasks", "", `JSON array of tasks, e.g. [{"content":"Task 1"},{"content":"Task 2","status":"in_progress","acceptance_criteria":["tests pass"]}]`)
 }
 
 // task-status command
@@ -346,6 +405,103 @@ func init() {
 	taskDependsCmd.Flags().String("depends-on", "", "Task ID this task depends on (required)")
 }
 
+// task-details command
+var taskDetailsCmd = &cobra.Command{
+	Use:   "task-details",
+	Short: "Update task description, acceptance criteria, labels, or estimate",
+	Long: `Update a task's optional details. Only the given flags are changed;
+--acceptance and --labels replace the previous list, and an empty value
+(e.g. --estimate "") clears the field.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if toolFlags.name == "" {
+			return fmt.Errorf("session name is required (--name)")
+		}
+
+		id, _ := cmd.Flags().GetString("id")
+		if id == "" {
+			return fmt.Errorf("task ID is required")
+		}
+
+		params := session.TaskDetailsParams{ID: id}
+		if cmd.Flags().Changed("description") {
+			description, _ := cmd.Flags().GetString("description")
+			params.Description = &description
+		}
+		if cmd.Flags().Changed("acceptance") {
+			criteria, _ := cmd.Flags().GetStringArray("acceptance")
+			params.AcceptanceCriteria = append([]string{}, criteria...)
+		}
+		if cmd.Flags().Changed("labels") {
+			labels, _ := cmd.Flags().GetStringSlice("labels")
+			params.Labels = append([]string{}, labels...)
+		}
+		if cmd.Flags().Changed("estimate") {
+			estimate, _ := cmd.Flags().GetString("estimate")
+			params.Estimate = &estimate
+		}
+
+		store, cleanup, err := connectToSession()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		ctx := context.Background()
+		if err := store.TaskDetails(ctx, toolFlags.name, params); err != nil {
+			return err
+		}
+
+		fmt.Println("OK")
+		return nil
+	},
+}
+
+func init() {
+	taskDetailsCmd.Flags().String("id", "", "Task ID (required)")
+	addTaskDetailFlags(taskDetailsCmd)
+}
+
+// task-parent command
+var taskParentCmd = &cobra.Command{
+	Use:   "task-parent",
+	Short: "Make a task a subtask of another task",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if toolFlags.name == "" {
+			return fmt.Errorf("session name is required (--name)")
+		}
+
+		id, _ := cmd.Flags().GetString("id")
+		parent, _ := cmd.Flags().GetString("parent")
+
+		if id == "" {
+			return fmt.Errorf("task ID is required")
+		}
+
+		store, cleanup, err := connectToSession()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		ctx := context.Background()
+		err = store.TaskParent(ctx, toolFlags.name, session.TaskParentParams{
+			ID:       id,
+			ParentID: parent,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("OK")
+		return nil
+	},
+}
+
+func init() {
+	taskParentCmd.Flags().String("id", "", "Task ID (required)")
+	taskParentCmd.Flags().String("parent", "", "Parent task ID (empty makes the task top-level)")
+}
+
 // task-list command
 var taskListCmd = &cobra.Command{
 	Use:   "task-list",
@@ -510,12 +666,19 @@ var taskNextCmd = &cobra.Command{
 		}
 
 		// Output JSON for parsing
-		output, _ := json.Marshal(map[string]any{
+		next := map[string]any{
 			"id":       task.ID,
 			"content":  task.Content,
 			"priority": task.Priority,
 			"status":   task.Status,
-		})
+		}
+		if task.Description != "" {
+			next["description"] = task.Description
+		}
+		if len(task.AcceptanceCriteria) > 0 {
+			next["acceptance_criteria"] = task.AcceptanceCriteria
+		}
+		output, _ := json.Marshal(next)
 		fmt.Println(string(output))
 		return nil
 	},
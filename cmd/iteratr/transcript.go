@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	udiff "github.com/aymanbagabas/go-udiff"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var transcriptFlags struct {
+	iteration int
+	format    string
+	dataDir   string
+}
+
+var transcriptCmd = &cobra.Command{
+	Use:   "transcript <session>",
+	Short: "Print what the agent said and did during a session",
+	Long: `Print the stored transcripts of a session: the prompts sent to the agent,
+its text and thinking, every tool call with its input, output, and file edits,
+and how each agent turn finished.
+
+Builds record one transcript per iteration, capped at transcript_max_bytes
+(4MiB by default; long tool outputs and file contents are cut at 64KiB each).
+Without --iteration every stored transcript is printed in order.
+
+--format md (default) prints readable Markdown, --format json prints a JSON
+array of transcripts for scripts.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTranscript,
+}
+
+func init() {
+	transcriptCmd.Flags().IntVar(&transcriptFlags.iteration, "iteration", -1, "Only print the transcript of this iteration")
+	transcriptCmd.Flags().StringVar(&transcriptFlags.format, "format", "md", "Output format: md or json")
+	transcriptCmd.Flags().StringVar(&transcriptFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+}
+
+func runTranscript(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if transcriptFlags.format != "md" && transcriptFlags.format != "json" {
+		return fmt.Errorf("format must be md or json, got %q", transcriptFlags.format)
+	}
+
+	store, cleanup, err := openSessionStore(resolveDataDir(transcriptFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	iterations := []int{transcriptFlags.iteration}
+	if transcriptFlags.iteration < 0 {
+		if iterations, err = store.TranscriptIterations(ctx, name); err != nil {
+			return err
+		}
+		if len(iterations) == 0 {
+			return fmt.Errorf("no transcripts stored for session %s", name)
+		}
+	}
+
+	transcripts := make([]*session.Transcript, 0, len(iterations))
+	for _, n := range iterations {
+		t, err := store.LoadTranscript(ctx, name, n)
+		if err != nil {
+			if errors.Is(err, session.ErrTranscriptNotFound) {
+				return fmt.Errorf("%w (transcripts expire with the event retention and are not recorded with transcript_max_bytes: -1)", err)
+			}
+			return err
+		}
+		transcripts = append(transcripts, t)
+	}
+
+	if transcriptFlags.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(transcripts)
+	}
+	for i, t := range transcripts {
+		if i > 0 {
+			fmt.Println()
+		}
+		writeTranscriptMarkdown(os.Stdout, t)
+	}
+	return nil
+}
+
+// writeTranscriptMarkdown writes a transcript as Markdown, one section per entry.
+func writeTranscriptMarkdown(w io.Writer, t *session.Transcript) {
+	fmt.Fprintf(w, "# %s: iteration #%d\n\n", t.Session, t.Iteration)
+	fmt.Fprintf(w, "_%d entries, %d bytes", len(t.Entries), t.Size)
+	if t.MaxBytes > 0 {
+		fmt.Fprintf(w, " (cap %d)", t.MaxBytes)
+	}
+	if t.Dropped > 0 {
+		fmt.Fprintf(w, ", %d events dropped after the cap was reached", t.Dropped)
+	}
+	fmt.Fprint(w, "_\n")
+
+	for _, entry := range t.Entries {
+		heading := transcriptHeading(entry)
+		if entry.Subagent != "" {
+			heading = fmt.Sprintf("Subagent %s: %s", entry.Subagent, heading)
+		}
+		fmt.Fprintf(w, "\n## %s · %s\n\n", heading, entry.Time.Local().Format("15:04:05"))
+
+		switch entry.Kind {
+		case session.TranscriptKindPrompt:
+			writeFenced(w, "text", entry.Text)
+		case session.TranscriptKindText:
+			fmt.Fprintf(w, "%s\n", strings.TrimRight(entry.Text, "\n"))
+		case session.TranscriptKindThinking:
+			for _, line := range strings.Split(strings.TrimRight(entry.Text, "\n"), "\n") {
+				fmt.Fprintf(w, "> %s\n", line)
+			}
+		case session.TranscriptKindTool:
+			writeTranscriptTool(w, entry.Tool)
+		case session.TranscriptKindFinish:
+			writeTranscriptFinish(w, entry.Finish)
+		}
+		if entry.Omitted > 0 {
+			fmt.Fprintf(w, "\n_[%d bytes omitted]_\n", entry.Omitted)
+		}
+	}
+}
+
+// transcriptHeading returns the section heading of an entry.
+func transcriptHeading(entry session.TranscriptEntry) string {
+	switch entry.Kind {
+	case session.TranscriptKindPrompt:
+		return "Prompt"
+	case session.TranscriptKindText:
+		return "Agent"
+	case session.TranscriptKindThinking:
+		return "Thinking"
+	case session.TranscriptKindTool:
+		if entry.Tool == nil {
+			return "Tool"
+		}
+		return fmt.Sprintf("Tool: %s (%s)", entry.Tool.Title, entry.Tool.Status)
+	case session.TranscriptKindFinish:
+		if entry.Finish == nil {
+			return "Finished"
+		}
+		return fmt.Sprintf("Finished: %s", entry.Finish.StopReason)
+	default:
+		return entry.Kind
+	}
+}
+
+// writeTranscriptTool writes the input, output, and file edit of a tool call.
+func writeTranscriptTool(w io.Writer, tool *session.TranscriptTool) {
+	if tool == nil {
+		return
+	}
+	blocks := 0
+	block := func(label, lang, content string) {
+		if blocks > 0 {
+			fmt.Fprintln(w)
+		}
+		blocks++
+		fmt.Fprintf(w, "%s:\n\n", label)
+		writeFenced(w, lang, content)
+	}
+	if len(tool.Input) > 0 {
+		input, err := json.MarshalIndent(tool.Input, "", "  ")
+		if err != nil {
+			input = []byte(fmt.Sprint(tool.Input))
+		}
+		block("Input", "json", string(input))
+	}
+	if tool.Output != "" {
+		block("Output", "text", tool.Output)
+	}
+	if diff := tool.Diff; diff != nil {
+		block(fmt.Sprintf("Edit of `%s` (+%d -%d)", diff.File, diff.Additions, diff.Deletions),
+			"diff", udiff.Unified(diff.File, diff.File, diff.Before, diff.After))
+	}
+}
+
+// writeTranscriptFinish writes how an agent turn ended.
+func writeTranscriptFinish(w io.Writer, finish *session.TranscriptFinish) {
+	if finish == nil {
+		return
+	}
+	if finish.Error != "" {
+		fmt.Fprintf(w, "- Error: %s\n", finish.Error)
+	}
+	if finish.Model != "" {
+		fmt.Fprintf(w, "- Model: %s\n", finish.Model)
+	}
+	fmt.Fprintf(w, "- Duration: %s\n", finish.Duration.Round(time.Millisecond))
+	if !finish.Usage.IsZero() {
+		fmt.Fprintf(w, "- Tokens: %s in, %s out\n",
+			session.FormatTokens(finish.Usage.InputTokens), session.FormatTokens(finish.Usage.OutputTokens))
+	}
+	if finish.Usage.Cost > 0 {
+		fmt.Fprintf(w, "- Cost: ~$%.4f\n", finish.Usage.Cost)
+	}
+}
+
+// writeFenced writes s as a fenced code block, with a fence longer than any
+// run of backticks in s.
+func writeFenced(w io.Writer, lang, s string) {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(w, "%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(s, "\n"), fence)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestWriteTranscriptMarkdown(t *testing.T) {
+	at := time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local)
+	tr := &session.Transcript{
+		Session:   "s",
+		Iteration: 3,
+		MaxBytes:  1000,
+		Size:      120,
+		Dropped:   2,
+		Entries: []session.TranscriptEntry{
+			{Time: at, Kind: session.TranscriptKindPrompt, Text: "Use ```go fences```"},
+			{Time: at, Kind: session.TranscriptKindThinking, Text: "first\nsecond"},
+			{Time: at, Kind: session.TranscriptKindText, Text: "Done.", Subagent: "call-7"},
+			{Time: at, Kind: session.TranscriptKindTool, Omitted: 42, Tool: &session.TranscriptTool{
+				ID: "t1", Title: "edit", Status: "completed",
+				Input:  map[string]any{"path": "a.txt"},
+				Output: "ok",
+				Diff:   &session.TranscriptDiff{File: "a.txt", Before: "old\n", After: "new\n", Additions: 1, Deletions: 1},
+			}},
+			{Time: at, Kind: session.TranscriptKindFinish, Finish: &session.TranscriptFinish{
+				StopReason: "end_turn", Model: "m", Duration: 1500 * time.Millisecond,
+				Usage: session.Usage{InputTokens: 1200, OutputTokens: 30, Cost: 0.01},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	writeTranscriptMarkdown(&buf, tr)
+	got := buf.String()
+
+	for _, want := range []string{
+		"# s: iteration #3\n",
+		"_5 entries, 120 bytes (cap 1000), 2 events dropped after the cap was reached_",
+		"## Prompt · 10:00:00\n\n````text\nUse ```go fences```\n````\n",
+		"> first\n> second\n",
+		"## Subagent call-7: Agent · 10:00:00\n\nDone.\n",
+		"## Tool: edit (completed) · 10:00:00",
+		"Input:\n\n```json\n{\n  \"path\": \"a.txt\"\n}\n```\n\nOutput:\n\n```text\nok\n```\n",
+		"Edit of `a.txt` (+1 -1):\n\n```diff\n",
+		"-old\n+new\n",
+		"_[42 bytes omitted]_",
+		"## Finished: end_turn · 10:00:00\n\n- Model: m\n- Duration: 1.5s\n- Tokens: 1.2k in, 30 out\n- Cost: ~$0.0100\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown missing %q\ngot:\n%s", want, got)
+		}
+	}
+}
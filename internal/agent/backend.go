@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// DefaultBackend is the backend used when none is configured.
+const DefaultBackend = "kit"
+
+// Backend drives an LLM agent for the orchestrator.
+// Implementations report output through the callbacks in BackendConfig and
+// must call OnFinish exactly once per RunIteration or SendMessages call.
+type Backend interface {
+	// Start initializes the backend. Must be called before RunIteration.
+	Start(ctx context.Context) error
+	// RunIteration starts a fresh conversation with the prompt.
+	// Optional hookOutput is prepended to the prompt.
+	RunIteration(ctx context.Context, prompt string, hookOutput string) error
+	// SendMessages sends user messages to the current conversation as a single prompt.
+	SendMessages(ctx context.Context, texts []string) error
+	// Stop releases all resources held by the backend.
+	Stop()
+}
+
+// BackendConfig holds configuration and callbacks shared by all backends.
+type BackendConfig struct {
+	Model        string              // LLM model to use (e.g., "anthropic/claude-sonnet-4-5")
+	WorkDir      string              // Working directory for agent
+	SessionName  string              // iteratr session name
+	NATSPort     int                 // NATS server port
+	MCPServerURL string              // MCP server URL for tool access
+	ScriptPath   string              // Script file for the replay backend
+	OnText       func(text string)   // Callback for text output
+	OnToolCall   func(ToolCallEvent) // Callback for tool lifecycle events
+	OnThinking   func(string)        // Callback for thinking/reasoning output
+	OnFinish     func(FinishEvent)   // Callback for iteration finish events
+	OnFileChange func(FileChange)    // Callback for file modifications
+
+	// Subagent live streaming callbacks. Called when a spawn_subagent tool
+	// is executing and its child events flow through the parent's event bus.
+	// The toolCallID identifies which spawn_subagent tool call owns the events.
+	OnSubagentText     func(toolCallID, text string)
+	OnSubagentToolCall func(toolCallID string, event ToolCallEvent)
+	OnSubagentThinking func(toolCallID, content string)
+}
+
+// BackendFactory creates a backend from its configuration.
+type BackendFactory func(cfg BackendConfig) (Backend, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{}
+)
+
+// RegisterBackend makes a backend available by name, replacing any existing
+// registration. Typically called from an init function.
+func RegisterBackend(name string, factory BackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = factory
+}
+
+// Backends returns the names of all registered backends, sorted.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasBackend reports whether a backend is registered under name.
+// The empty name refers to DefaultBackend.
+func HasBackend(name string) bool {
+	if name == "" {
+		name = DefaultBackend
+	}
+	return slices.Contains(Backends(), name)
+}
+
+// NewBackend creates the named backend. The empty name selects DefaultBackend.
+func NewBackend(name string, cfg BackendConfig) (Backend, error) {
+	if name == "" {
+		name = DefaultBackend
+	}
+	backendsMu.RLock()
+	factory, ok := backends[name]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown agent backend %q (available: %v)", name, Backends())
+	}
+	return factory(cfg)
+}
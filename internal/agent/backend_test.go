@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+type stubBackend struct{ cfg BackendConfig }
+
+func (s *stubBackend) Start(ctx context.Context) error                             { return nil }
+func (s *stubBackend) RunIteration(ctx context.Context, prompt, hook string) error { return nil }
+func (s *stubBackend) SendMessages(ctx context.Context, texts []string) error      { return nil }
+func (s *stubBackend) Stop()                                                       {}
+
+func TestBackendRegistry(t *testing.T) {
+	RegisterBackend("stub", func(cfg BackendConfig) (Backend, error) {
+		return &stubBackend{cfg: cfg}, nil
+	})
+
+	if !slices.Contains(Backends(), "kit") {
+		t.Errorf("Backends() = %v, want kit registered", Backends())
+	}
+	if !HasBackend("") {
+		t.Error("HasBackend(\"\") should resolve to the default backend")
+	}
+	if HasBackend("missing") {
+		t.Error("HasBackend(\"missing\") = true, want false")
+	}
+
+	b, err := NewBackend("stub", BackendConfig{Model: "m"})
+	if err != nil {
+		t.Fatalf("NewBackend(stub) error = %v", err)
+	}
+	if stub, ok := b.(*stubBackend); !ok || stub.cfg.Model != "m" {
+		t.Errorf("NewBackend(stub) = %#v, want stub with config", b)
+	}
+
+	b, err = NewBackend("", BackendConfig{})
+	if err != nil {
+		t.Fatalf("NewBackend(\"\") error = %v", err)
+	}
+	if _, ok := b.(*KitAgent); !ok {
+		t.Errorf("NewBackend(\"\") = %T, want *KitAgent", b)
+	}
+
+	if _, err := NewBackend("missing", BackendConfig{}); err == nil {
+		t.Error("NewBackend(missing) should fail")
+	}
+}
@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+)
+
+// retryablePatterns are lowercase fragments of provider and network error
+// messages that indicate a temporary failure. Used when the error chain
+// carries no type information (e.g. errors flattened to strings by a backend).
+var retryablePatterns = []string{
+	"rate limit",
+	"rate_limit",
+	"too many requests",
+	"overloaded",
+	"status 429",
+	"status code 429",
+	"status 500",
+	"status code 500",
+	"status 502",
+	"status code 502",
+	"status 503",
+	"status code 503",
+	"status 504",
+	"status code 504",
+	"status 529",
+	"status code 529",
+	"internal server error",
+	"bad gateway",
+	"service unavailable",
+	"gateway timeout",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"unexpected eof",
+	"i/o timeout",
+	"tls handshake timeout",
+	"temporarily unavailable",
+}
+
+// IsRetryable reports whether an agent run failed for a temporary reason
+// (rate limits, provider 5xx/overloaded responses, network resets) such that
+// running the same iteration again may succeed.
+// Cancellation, watchdog timeouts, panics, and permanent errors are never retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ierr.ErrTimeout) {
+		return false
+	}
+	var panicErr *ierr.PanicError
+	var permErr *ierr.PermanentError
+	if errors.As(err, &panicErr) || errors.As(err, &permErr) {
+		return false
+	}
+	if ierr.IsTransient(err) {
+		return true
+	}
+
+	// Provider errors (e.g. fantasy.ProviderError) know whether they are retryable
+	var retryable interface{ IsRetryable() bool }
+	if errors.As(err, &retryable) {
+		return retryable.IsRetryable()
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range retryablePatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+)
+
+// providerError mimics provider errors that report their own retryability.
+type providerError struct{ retryable bool }
+
+func (e *providerError) Error() string     { return "provider error" }
+func (e *providerError) IsRetryable() bool { return e.retryable }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("invalid api key"), false},
+		{"rate limit message", errors.New("KIT prompt failed: Rate limit exceeded"), true},
+		{"overloaded message", errors.New("anthropic: Overloaded"), true},
+		{"5xx message", errors.New("request failed with status code 503"), true},
+		{"connection reset", fmt.Errorf("KIT prompt failed: %w", syscall.ECONNRESET), true},
+		{"unexpected eof", fmt.Errorf("stream: %w", io.ErrUnexpectedEOF), true},
+		{"transient error", ierr.NewTransientError("op", errors.New("temp")), true},
+		{"provider says retry", fmt.Errorf("KIT prompt failed: %w", &providerError{retryable: true}), true},
+		{"provider says no retry", fmt.Errorf("KIT prompt failed: %w", &providerError{retryable: false}), false},
+		{"cancelled", fmt.Errorf("KIT prompt failed: %w", context.Canceled), false},
+		{"watchdog timeout", &ierr.TimeoutError{Kind: ierr.TimeoutIdle, Limit: time.Minute}, false},
+		{"panic", &ierr.PanicError{Value: "rate limit"}, false},
+		{"permanent", ierr.NewPermanentError("op", errors.New("rate limit")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -41,30 +41,18 @@ type KitAgent struct {
 	unsubscribes []func()
 }
 
-// KitAgentConfig holds configuration for creating a new KitAgent.
-type KitAgentConfig struct {
-	Model        string              // LLM model to use (e.g., "anthropic/claude-sonnet-4-5")
-	WorkDir      string              // Working directory for agent
-	SessionName  string              // Session name (unused by KIT but kept for API compat)
-	NATSPort     int                 // NATS server port (unused by KIT but kept for API compat)
-	MCPServerURL string              // MCP server URL for tool access
-	OnText       func(text string)   // Callback for text output
-	OnToolCall   func(ToolCallEvent) // Callback for tool lifecycle events
-	OnThinking   func(string)        // Callback for thinking/reasoning output
-	OnFinish     func(FinishEvent)   // Callback for iteration finish events
-	OnFileChange func(FileChange)    // Callback for file modifications
-
-	// Subagent live streaming callbacks. Called when a spawn_subagent tool
-	// is executing and its child events flow through the parent's event bus.
-	// The toolCallID identifies which spawn_subagent tool call owns the events.
-	OnSubagentText     func(toolCallID, text string)
-	OnSubagentToolCall func(toolCallID string, event ToolCallEvent)
-	OnSubagentThinking func(toolCallID, content string)
+// Compile-time check that KitAgent implements Backend.
+var _ Backend = (*KitAgent)(nil)
+
+func init() {
+	RegisterBackend("kit", func(cfg BackendConfig) (Backend, error) {
+		return NewKitAgent(cfg), nil
+	})
 }
 
 // NewKitAgent creates a new KitAgent instance. Call Start() to initialize the
 // KIT SDK and subscribe to events.
-func NewKitAgent(cfg KitAgentConfig) *KitAgent {
+func NewKitAgent(cfg BackendConfig) *KitAgent {
 	return &KitAgent{
 		model:              cfg.Model,
 		workDir:            cfg.WorkDir,
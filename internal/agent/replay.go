@@ -0,0 +1,414 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// ReplayScript is a deterministic agent transcript for the replay backend.
+// Scripts are YAML or JSON (JSON is valid YAML).
+type ReplayScript struct {
+	// Iterations are played by successive RunIteration calls, in order.
+	// RunIteration fails once they are exhausted so runaway loops end.
+	Iterations []ReplayTurn `yaml:"iterations"`
+	// Messages are played by successive SendMessages calls (auto-commit,
+	// hook output, user messages). Once exhausted, SendMessages just finishes.
+	Messages []ReplayTurn `yaml:"messages"`
+}
+
+// ReplayTurn is the output of one agent turn.
+type ReplayTurn struct {
+	Steps []ReplayStep `yaml:"steps"`
+}
+
+// ReplayStep is a single scripted action. Exactly one of its fields should be set.
+type ReplayStep struct {
+	Text     string         `yaml:"text,omitempty"`     // Emit assistant text
+	Thinking string         `yaml:"thinking,omitempty"` // Emit reasoning output
+	Tool     string         `yaml:"tool,omitempty"`     // Call a tool; iteratr MCP tools are executed for real
+	Input    map[string]any `yaml:"input,omitempty"`    // Tool arguments
+	Output   string         `yaml:"output,omitempty"`   // Result reported for tools not served by the MCP server
+	IsError  bool           `yaml:"is_error,omitempty"` // Report the simulated tool call as failed
+	Edit     *ReplayEdit    `yaml:"edit,omitempty"`     // Write a file in the working directory
+	Delay    time.Duration  `yaml:"delay,omitempty"`    // Sleep before continuing (e.g. "200ms")
+	Finish   *ReplayFinish  `yaml:"finish,omitempty"`   // End the turn early with the given finish event
+}
+
+// ReplayEdit writes Content to Path (relative to the working directory).
+type ReplayEdit struct {
+	Path    string `yaml:"path"`
+	Content string `yaml:"content"`
+}
+
+// ReplayFinish describes how a turn ends. Turns without one end with "end_turn".
+type ReplayFinish struct {
+	StopReason   string `yaml:"stop_reason,omitempty"` // Defaults to "end_turn", or "error" if Error is set
+	Error        string `yaml:"error,omitempty"`       // Fails the turn with this message
+	InputTokens  int64  `yaml:"input_tokens,omitempty"`
+	OutputTokens int64  `yaml:"output_tokens,omitempty"`
+}
+
+// LoadReplayScript reads and parses a replay script file.
+func LoadReplayScript(path string) (*ReplayScript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay script: %w", err)
+	}
+	var script ReplayScript
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse replay script %s: %w", path, err)
+	}
+	return &script, nil
+}
+
+// ReplayAgent is a Backend that plays a ReplayScript instead of calling an LLM.
+// It emits the same callbacks as KitAgent, so the orchestrator, TUI and hooks
+// run unchanged. Tool steps naming an iteratr MCP tool are sent to the session's
+// MCP server, so task and summary updates land in the event store.
+type ReplayAgent struct {
+	cfg    BackendConfig
+	script *ReplayScript
+
+	mu         sync.Mutex
+	iteration  int // Next entry in script.Iterations
+	message    int // Next entry in script.Messages
+	toolCallID int
+
+	mcp      *client.Client
+	mcpTools map[string]bool
+}
+
+// Compile-time check that ReplayAgent implements Backend.
+var _ Backend = (*ReplayAgent)(nil)
+
+func init() {
+	RegisterBackend("replay", func(cfg BackendConfig) (Backend, error) {
+		if cfg.ScriptPath == "" {
+			return nil, fmt.Errorf("replay backend requires a script (replay_script / --replay-script)")
+		}
+		script, err := LoadReplayScript(cfg.ScriptPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewReplayAgent(cfg, script), nil
+	})
+}
+
+// NewReplayAgent creates a replay backend for a script.
+func NewReplayAgent(cfg BackendConfig, script *ReplayScript) *ReplayAgent {
+	return &ReplayAgent{cfg: cfg, script: script}
+}
+
+// Start connects to the MCP server (if configured) and discovers its tools.
+func (a *ReplayAgent) Start(ctx context.Context) error {
+	if a.cfg.MCPServerURL == "" {
+		return nil
+	}
+
+	c, err := client.NewStreamableHttpClient(a.cfg.MCPServerURL)
+	if err != nil {
+		return fmt.Errorf("failed to create MCP client: %w", err)
+	}
+	if err := c.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start MCP client: %w", err)
+	}
+
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initReq.Params.ClientInfo = mcp.Implementation{Name: "iteratr-replay", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, initReq); err != nil {
+		_ = c.Close()
+		return fmt.Errorf("failed to initialize MCP session: %w", err)
+	}
+
+	tools, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		_ = c.Close()
+		return fmt.Errorf("failed to list MCP tools: %w", err)
+	}
+	a.mcpTools = make(map[string]bool, len(tools.Tools))
+	for _, tool := range tools.Tools {
+		a.mcpTools[tool.Name] = true
+	}
+	a.mcp = c
+
+	logger.Debug("Replay agent connected to MCP server with %d tools", len(a.mcpTools))
+	return nil
+}
+
+// RunIteration plays the next scripted iteration.
+func (a *ReplayAgent) RunIteration(ctx context.Context, prompt string, hookOutput string) error {
+	a.mu.Lock()
+	index := a.iteration
+	a.iteration++
+	a.mu.Unlock()
+
+	if index >= len(a.script.Iterations) {
+		err := fmt.Errorf("replay script exhausted after %d iteration(s)", len(a.script.Iterations))
+		a.finish(FinishEvent{StopReason: "error", Error: err.Error()})
+		return err
+	}
+
+	logger.Debug("Replaying iteration turn %d/%d", index+1, len(a.script.Iterations))
+	return a.play(ctx, a.script.Iterations[index])
+}
+
+// SendMessages plays the next scripted message turn, or finishes
+// immediately once the script has none left.
+func (a *ReplayAgent) SendMessages(ctx context.Context, texts []string) error {
+	if len(texts) == 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	index := a.message
+	a.message++
+	a.mu.Unlock()
+
+	if index >= len(a.script.Messages) {
+		a.finish(FinishEvent{StopReason: "end_turn"})
+		return nil
+	}
+
+	logger.Debug("Replaying message turn %d/%d", index+1, len(a.script.Messages))
+	return a.play(ctx, a.script.Messages[index])
+}
+
+// Stop closes the MCP client.
+func (a *ReplayAgent) Stop() {
+	if a.mcp != nil {
+		if err := a.mcp.Close(); err != nil {
+			logger.Debug("Failed to close replay MCP client: %v", err)
+		}
+		a.mcp = nil
+	}
+	logger.Debug("Replay agent stopped")
+}
+
+// play emits each step of a turn, then its finish event.
+func (a *ReplayAgent) play(ctx context.Context, turn ReplayTurn) error {
+	startTime := time.Now()
+	finish := ReplayFinish{}
+
+	for _, step := range turn.Steps {
+		if err := ctx.Err(); err != nil {
+			a.finish(FinishEvent{StopReason: "cancelled", Error: err.Error(), Duration: time.Since(startTime)})
+			return fmt.Errorf("replay cancelled: %w", err)
+		}
+
+		switch {
+		case step.Text != "":
+			if a.cfg.OnText != nil {
+				a.cfg.OnText(step.Text)
+			}
+		case step.Thinking != "":
+			if a.cfg.OnThinking != nil {
+				a.cfg.OnThinking(step.Thinking)
+			}
+		case step.Tool != "":
+			a.playTool(ctx, step)
+		case step.Edit != nil:
+			if err := a.playEdit(step.Edit); err != nil {
+				a.finish(FinishEvent{StopReason: "error", Error: err.Error(), Duration: time.Since(startTime)})
+				return err
+			}
+		case step.Delay > 0:
+			select {
+			case <-time.After(step.Delay):
+			case <-ctx.Done():
+				a.finish(FinishEvent{StopReason: "cancelled", Error: ctx.Err().Error(), Duration: time.Since(startTime)})
+				return fmt.Errorf("replay cancelled: %w", ctx.Err())
+			}
+		case step.Finish != nil:
+			finish = *step.Finish
+		}
+		if step.Finish != nil {
+			break
+		}
+	}
+
+	event := FinishEvent{
+		StopReason: finish.StopReason,
+		Error:      finish.Error,
+		Duration:   time.Since(startTime),
+	}
+	if event.StopReason == "" {
+		event.StopReason = "end_turn"
+		if finish.Error != "" {
+			event.StopReason = "error"
+		}
+	}
+	if finish.InputTokens > 0 || finish.OutputTokens > 0 {
+		event.Usage = &Usage{
+			InputTokens:  finish.InputTokens,
+			OutputTokens: finish.OutputTokens,
+			TotalTokens:  finish.InputTokens + finish.OutputTokens,
+		}
+	}
+	a.finish(event)
+
+	if finish.Error != "" {
+		return fmt.Errorf("replay turn failed: %s", finish.Error)
+	}
+	return nil
+}
+
+// finish dispatches a finish event with the model fields filled in.
+func (a *ReplayAgent) finish(event FinishEvent) {
+	if a.cfg.OnFinish == nil {
+		return
+	}
+	event.Model = a.cfg.Model
+	event.Provider = extractProvider(a.cfg.Model)
+	a.cfg.OnFinish(event)
+}
+
+// nextToolCallID returns a unique, deterministic tool call ID.
+func (a *ReplayAgent) nextToolCallID() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.toolCallID++
+	return fmt.Sprintf("replay-%d", a.toolCallID)
+}
+
+// playTool emits the tool call lifecycle, executing iteratr MCP tools for real.
+func (a *ReplayAgent) playTool(ctx context.Context, step ReplayStep) {
+	event := ToolCallEvent{
+		ToolCallID: a.nextToolCallID(),
+		Title:      step.Tool,
+		Kind:       "execute",
+		Status:     "pending",
+		RawInput:   step.Input,
+	}
+	a.emitToolCall(event)
+	event.Status = "in_progress"
+	a.emitToolCall(event)
+
+	output, isError := step.Output, step.IsError
+	if a.mcp != nil && a.mcpTools[step.Tool] {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = step.Tool
+		req.Params.Arguments = step.Input
+		result, err := a.mcp.CallTool(ctx, req)
+		if err != nil {
+			output, isError = err.Error(), true
+		} else {
+			output, isError = toolResultText(result), result.IsError
+		}
+	}
+
+	event.Output = output
+	event.Status = "completed"
+	if isError {
+		event.Status = "error"
+	}
+	a.emitToolCall(event)
+}
+
+// playEdit writes a file and reports it like an edit tool would.
+func (a *ReplayAgent) playEdit(edit *ReplayEdit) error {
+	if edit.Path == "" {
+		return fmt.Errorf("replay edit requires a path")
+	}
+	absPath := edit.Path
+	if !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(a.cfg.WorkDir, edit.Path)
+	}
+
+	before, err := os.ReadFile(absPath)
+	isNew := os.IsNotExist(err)
+	if err != nil && !isNew {
+		return fmt.Errorf("failed to read %s: %w", edit.Path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", edit.Path, err)
+	}
+	if err := os.WriteFile(absPath, []byte(edit.Content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", edit.Path, err)
+	}
+
+	additions, deletions := countLineChanges(string(before), edit.Content)
+	event := ToolCallEvent{
+		ToolCallID: a.nextToolCallID(),
+		Title:      "edit",
+		Kind:       "edit",
+		Status:     "completed",
+		RawInput:   map[string]any{"path": edit.Path},
+		FileDiff: &FileDiff{
+			File:      absPath,
+			Before:    string(before),
+			After:     edit.Content,
+			Additions: additions,
+			Deletions: deletions,
+		},
+		DiffBlocks: []DiffBlock{{Path: absPath, OldText: string(before), NewText: edit.Content}},
+	}
+	a.emitToolCall(event)
+
+	if a.cfg.OnFileChange != nil {
+		relPath, err := filepath.Rel(a.cfg.WorkDir, absPath)
+		if err != nil {
+			relPath = edit.Path
+		}
+		a.cfg.OnFileChange(FileChange{
+			Path:      relPath,
+			AbsPath:   absPath,
+			IsNew:     isNew,
+			Additions: additions,
+			Deletions: deletions,
+		})
+	}
+	return nil
+}
+
+func (a *ReplayAgent) emitToolCall(event ToolCallEvent) {
+	if a.cfg.OnToolCall != nil {
+		a.cfg.OnToolCall(event)
+	}
+}
+
+// toolResultText joins the text content of an MCP tool result.
+func toolResultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// countLineChanges approximates added and deleted line counts by comparing
+// the multisets of lines before and after.
+func countLineChanges(before, after string) (additions, deletions int) {
+	counts := make(map[string]int)
+	if before != "" {
+		for _, line := range strings.Split(strings.TrimSuffix(before, "\n"), "\n") {
+			counts[line]++
+		}
+	}
+	if after != "" {
+		for _, line := range strings.Split(strings.TrimSuffix(after, "\n"), "\n") {
+			counts[line]--
+		}
+	}
+	for _, n := range counts {
+		if n > 0 {
+			deletions += n
+		} else {
+			additions -= n
+		}
+	}
+	return additions, deletions
+}
@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testReplayScript = `
+iterations:
+  - steps:
+      - thinking: planning
+      - text: hello
+      - tool: bash
+        input: {command: ls}
+        output: main.go
+      - edit:
+          path: out/result.txt
+          content: "one\ntwo\n"
+      - finish:
+          input_tokens: 10
+          output_tokens: 5
+      - text: never emitted
+  - steps:
+      - finish:
+          error: boom
+messages:
+  - steps:
+      - text: committed
+`
+
+func loadTestReplayScript(t *testing.T) *ReplayScript {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.yml")
+	if err := os.WriteFile(path, []byte(testReplayScript), 0644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	script, err := LoadReplayScript(path)
+	if err != nil {
+		t.Fatalf("LoadReplayScript() error = %v", err)
+	}
+	return script
+}
+
+func TestReplayAgent_PlaysScript(t *testing.T) {
+	workDir := t.TempDir()
+	var (
+		texts    []string
+		thinking []string
+		tools    []ToolCallEvent
+		finishes []FinishEvent
+		changes  []FileChange
+	)
+	a := NewReplayAgent(BackendConfig{
+		Model:        "replay/test",
+		WorkDir:      workDir,
+		OnText:       func(s string) { texts = append(texts, s) },
+		OnThinking:   func(s string) { thinking = append(thinking, s) },
+		OnToolCall:   func(e ToolCallEvent) { tools = append(tools, e) },
+		OnFinish:     func(e FinishEvent) { finishes = append(finishes, e) },
+		OnFileChange: func(c FileChange) { changes = append(changes, c) },
+	}, loadTestReplayScript(t))
+
+	ctx := context.Background()
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer a.Stop()
+
+	if err := a.RunIteration(ctx, "prompt", ""); err != nil {
+		t.Fatalf("RunIteration() error = %v", err)
+	}
+
+	if len(texts) != 1 || texts[0] != "hello" {
+		t.Errorf("texts = %v, want [hello]", texts)
+	}
+	if len(thinking) != 1 || thinking[0] != "planning" {
+		t.Errorf("thinking = %v, want [planning]", thinking)
+	}
+	// bash: pending, in_progress, completed; edit: completed
+	if len(tools) != 4 {
+		t.Fatalf("got %d tool events, want 4", len(tools))
+	}
+	if tools[2].Status != "completed" || tools[2].Output != "main.go" {
+		t.Errorf("bash result = %+v, want completed with scripted output", tools[2])
+	}
+	if tools[3].Kind != "edit" || tools[3].FileDiff == nil || tools[3].FileDiff.Additions != 2 {
+		t.Errorf("edit event = %+v, want edit with 2 additions", tools[3])
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, "out", "result.txt"))
+	if err != nil || string(data) != "one\ntwo\n" {
+		t.Errorf("edited file = %q, %v", data, err)
+	}
+	if len(changes) != 1 || changes[0].Path != filepath.Join("out", "result.txt") || !changes[0].IsNew {
+		t.Errorf("file changes = %+v, want new out/result.txt", changes)
+	}
+
+	if len(finishes) != 1 {
+		t.Fatalf("got %d finish events, want 1", len(finishes))
+	}
+	f := finishes[0]
+	if f.StopReason != "end_turn" || f.Provider != "Replay" || f.Usage == nil || f.Usage.TotalTokens != 15 {
+		t.Errorf("finish = %+v, want end_turn from replay with 15 tokens", f)
+	}
+
+	if err := a.SendMessages(ctx, []string{"commit please"}); err != nil {
+		t.Fatalf("SendMessages() error = %v", err)
+	}
+	if texts[len(texts)-1] != "committed" {
+		t.Errorf("last text = %q, want committed", texts[len(texts)-1])
+	}
+	// Messages beyond the script just finish.
+	if err := a.SendMessages(ctx, []string{"again"}); err != nil {
+		t.Fatalf("SendMessages() past script error = %v", err)
+	}
+
+	if err := a.RunIteration(ctx, "prompt", ""); err == nil {
+		t.Error("RunIteration() with scripted error should fail")
+	}
+	if got := finishes[len(finishes)-1]; got.StopReason != "error" || got.Error != "boom" {
+		t.Errorf("error finish = %+v, want stop_reason error with boom", got)
+	}
+
+	if err := a.RunIteration(ctx, "prompt", ""); err == nil {
+		t.Error("RunIteration() past the script should fail")
+	}
+	if len(finishes) != 5 {
+		t.Errorf("got %d finish events, want one per call (5)", len(finishes))
+	}
+}
+
+func TestReplayAgent_Cancelled(t *testing.T) {
+	script := &ReplayScript{Iterations: []ReplayTurn{{Steps: []ReplayStep{
+		{Delay: time.Hour},
+		{Text: "late"},
+	}}}}
+	var finish FinishEvent
+	var texts []string
+	a := NewReplayAgent(BackendConfig{
+		OnText:   func(s string) { texts = append(texts, s) },
+		OnFinish: func(e FinishEvent) { finish = e },
+	}, script)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := a.RunIteration(ctx, "prompt", ""); err == nil {
+		t.Fatal("RunIteration() should fail when cancelled")
+	}
+	if finish.StopReason != "cancelled" || len(texts) != 0 {
+		t.Errorf("finish = %+v texts = %v, want cancelled with no text", finish, texts)
+	}
+}
+
+func TestReplayBackendRequiresScript(t *testing.T) {
+	if _, err := NewBackend("replay", BackendConfig{}); err == nil {
+		t.Error("NewBackend(replay) without a script should fail")
+	}
+}
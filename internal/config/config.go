@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
@@ -21,8 +22,69 @@ type Config struct {
 	Iterations    int    `mapstructure:"iterations" yaml:"iterations"`
 	Headless      bool   `mapstructure:"headless" yaml:"headless"`
 	Template      string `mapstructure:"template" yaml:"template"`
+	TemplateMode  string `mapstructure:"template_mode" yaml:"template_mode,omitempty"` // How the template is rendered: auto, legacy, or go
+	PromptBudget  int    `mapstructure:"prompt_budget" yaml:"prompt_budget,omitempty"` // Estimated prompt size limit in tokens, 0 = unlimited
 	SpecDir       string `mapstructure:"spec_dir" yaml:"spec_dir"`
 	CommitDataDir bool   `mapstructure:"commit_data_dir" yaml:"commit_data_dir"`
+	CommitMode    string `mapstructure:"commit_mode" yaml:"commit_mode,omitempty"` // How auto-commit commits: agent, native, or native-llm
+	Review        bool   `mapstructure:"review" yaml:"review,omitempty"`           // Review each iteration's diff before auto-commit
+	SpecSync      bool   `mapstructure:"spec_sync" yaml:"spec_sync,omitempty"`     // Check off the spec's checklist items as tasks are completed
+	Worktree      bool   `mapstructure:"worktree" yaml:"worktree,omitempty"`       // Run each session in its own git worktree and branch
+	Backend       string `mapstructure:"backend" yaml:"backend,omitempty"`
+	ReplayScript  string `mapstructure:"replay_script" yaml:"replay_script,omitempty"`
+
+	IterationTimeout time.Duration `mapstructure:"iteration_timeout" yaml:"iteration_timeout,omitempty"` // Cancel an iteration after this long, 0 = no limit
+	IdleTimeout      time.Duration `mapstructure:"idle_timeout" yaml:"idle_timeout,omitempty"`           // Cancel an iteration when the agent is silent this long, 0 = no limit
+
+	TranscriptMaxBytes int `mapstructure:"transcript_max_bytes" yaml:"transcript_max_bytes,omitempty"` // Size cap of each iteration's transcript, 0 = unlimited, -1 = off
+
+	TemplateVars map[string]string `mapstructure:"template_vars" yaml:"template_vars,omitempty"` // Custom variables for Go templates ({{.Vars.name}})
+
+	Retention RetentionConfig `mapstructure:"retention" yaml:"retention,omitempty"`
+	Prices    []PriceConfig   `mapstructure:"prices" yaml:"prices,omitempty"`
+	Budget    BudgetConfig    `mapstructure:"budget" yaml:"budget,omitempty"`
+	Retry     RetryConfig     `mapstructure:"retry" yaml:"retry,omitempty"`
+}
+
+// RetentionConfig controls how much session history the JetStream stream keeps.
+// Zero values mean unlimited. The size caps drop a session's oldest events and
+// can corrupt its replayed state (see nats.Retention).
+type RetentionConfig struct {
+	MaxAgeDays        int   `mapstructure:"max_age_days" yaml:"max_age_days"`                 // Drop events older than N days (default 30)
+	MaxBytes          int64 `mapstructure:"max_bytes" yaml:"max_bytes"`                       // Cap total stream size
+	MaxMsgsPerSubject int64 `mapstructure:"max_msgs_per_subject" yaml:"max_msgs_per_subject"` // Cap events per session/type subject
+}
+
+// MaxAge returns the configured maximum event age as a duration.
+func (r RetentionConfig) MaxAge() time.Duration {
+	return time.Duration(r.MaxAgeDays) * 24 * time.Hour
+}
+
+// PriceConfig is the price of a model in USD per million tokens, used to
+// estimate session spend. Model matches either the full model name
+// ("anthropic/claude-sonnet-4-5") or the name without its provider.
+type PriceConfig struct {
+	Model      string  `mapstructure:"model" yaml:"model"`
+	Input      float64 `mapstructure:"input" yaml:"input"`
+	Output     float64 `mapstructure:"output" yaml:"output"`
+	CacheRead  float64 `mapstructure:"cache_read" yaml:"cache_read,omitempty"`
+	CacheWrite float64 `mapstructure:"cache_write" yaml:"cache_write,omitempty"`
+}
+
+// BudgetConfig limits the resources a session may use. Zero values mean unlimited.
+type BudgetConfig struct {
+	MaxTokens   int64         `mapstructure:"max_tokens" yaml:"max_tokens,omitempty"`     // Session total of input + output tokens
+	MaxCost     float64       `mapstructure:"max_cost" yaml:"max_cost,omitempty"`         // Estimated session cost in USD (requires prices)
+	MaxDuration time.Duration `mapstructure:"max_duration" yaml:"max_duration,omitempty"` // Wall-clock time per build run (e.g. "8h")
+	Action      string        `mapstructure:"action" yaml:"action,omitempty"`             // "stop" (default) or "pause"
+}
+
+// RetryConfig controls how iterations that fail with transient provider
+// errors (rate limits, 5xx, network resets) are retried with backoff.
+type RetryConfig struct {
+	MaxAttempts int           `mapstructure:"max_attempts" yaml:"max_attempts,omitempty"` // Attempts per iteration including the first, 1 = no retry
+	InitialWait time.Duration `mapstructure:"initial_wait" yaml:"initial_wait,omitempty"` // Wait before the first retry, doubled after each
+	MaxWait     time.Duration `mapstructure:"max_wait" yaml:"max_wait,omitempty"`         // Upper bound on the wait between retries
 }
 
 // Load loads configuration with full precedence:
@@ -40,8 +102,29 @@ func Load() (*Config, error) {
 	v.SetDefault("iterations", 0)
 	v.SetDefault("headless", false)
 	v.SetDefault("template", "")
+	v.SetDefault("template_mode", "auto")
+	v.SetDefault("prompt_budget", 0)
+	v.SetDefault("transcript_max_bytes", 4*1024*1024)
 	v.SetDefault("spec_dir", "specs")
 	v.SetDefault("commit_data_dir", false)
+	v.SetDefault("commit_mode", "agent")
+	v.SetDefault("review", false)
+	v.SetDefault("spec_sync", false)
+	v.SetDefault("worktree", false)
+	v.SetDefault("backend", "kit")
+	v.SetDefault("replay_script", "")
+	v.SetDefault("iteration_timeout", 0)
+	v.SetDefault("idle_timeout", 0)
+	v.SetDefault("retention.max_age_days", 30)
+	v.SetDefault("retention.max_bytes", 0)
+	v.SetDefault("retention.max_msgs_per_subject", 0)
+	v.SetDefault("budget.max_tokens", 0)
+	v.SetDefault("budget.max_cost", 0)
+	v.SetDefault("budget.max_duration", 0)
+	v.SetDefault("budget.action", "stop")
+	v.SetDefault("retry.max_attempts", 4)
+	v.SetDefault("retry.initial_wait", 5*time.Second)
+	v.SetDefault("retry.max_wait", time.Minute)
 
 	// Setup ENV binding with ITERATR_ prefix
 	v.SetEnvPrefix("ITERATR")
@@ -74,12 +157,75 @@ func Load() (*Config, error) {
 	if err := v.BindEnv("template", "ITERATR_TEMPLATE"); err != nil {
 		return nil, fmt.Errorf("binding template env: %w", err)
 	}
+	if err := v.BindEnv("template_mode", "ITERATR_TEMPLATE_MODE"); err != nil {
+		return nil, fmt.Errorf("binding template_mode env: %w", err)
+	}
+	if err := v.BindEnv("prompt_budget", "ITERATR_PROMPT_BUDGET"); err != nil {
+		return nil, fmt.Errorf("binding prompt_budget env: %w", err)
+	}
+	if err := v.BindEnv("transcript_max_bytes", "ITERATR_TRANSCRIPT_MAX_BYTES"); err != nil {
+		return nil, fmt.Errorf("binding transcript_max_bytes env: %w", err)
+	}
 	if err := v.BindEnv("spec_dir", "ITERATR_SPEC_DIR"); err != nil {
 		return nil, fmt.Errorf("binding spec_dir env: %w", err)
 	}
 	if err := v.BindEnv("commit_data_dir", "ITERATR_COMMIT_DATA_DIR"); err != nil {
 		return nil, fmt.Errorf("binding commit_data_dir env: %w", err)
 	}
+	if err := v.BindEnv("commit_mode", "ITERATR_COMMIT_MODE"); err != nil {
+		return nil, fmt.Errorf("binding commit_mode env: %w", err)
+	}
+	if err := v.BindEnv("review", "ITERATR_REVIEW"); err != nil {
+		return nil, fmt.Errorf("binding review env: %w", err)
+	}
+	if err := v.BindEnv("spec_sync", "ITERATR_SPEC_SYNC"); err != nil {
+		return nil, fmt.Errorf("binding spec_sync env: %w", err)
+	}
+	if err := v.BindEnv("worktree", "ITERATR_WORKTREE"); err != nil {
+		return nil, fmt.Errorf("binding worktree env: %w", err)
+	}
+	if err := v.BindEnv("backend", "ITERATR_BACKEND"); err != nil {
+		return nil, fmt.Errorf("binding backend env: %w", err)
+	}
+	if err := v.BindEnv("replay_script", "ITERATR_REPLAY_SCRIPT"); err != nil {
+		return nil, fmt.Errorf("binding replay_script env: %w", err)
+	}
+	if err := v.BindEnv("iteration_timeout", "ITERATR_ITERATION_TIMEOUT"); err != nil {
+		return nil, fmt.Errorf("binding iteration_timeout env: %w", err)
+	}
+	if err := v.BindEnv("idle_timeout", "ITERATR_IDLE_TIMEOUT"); err != nil {
+		return nil, fmt.Errorf("binding idle_timeout env: %w", err)
+	}
+	if err := v.BindEnv("retention.max_age_days", "ITERATR_RETENTION_MAX_AGE_DAYS"); err != nil {
+		return nil, fmt.Errorf("binding retention.max_age_days env: %w", err)
+	}
+	if err := v.BindEnv("retention.max_bytes", "ITERATR_RETENTION_MAX_BYTES"); err != nil {
+		return nil, fmt.Errorf("binding retention.max_bytes env: %w", err)
+	}
+	if err := v.BindEnv("retention.max_msgs_per_subject", "ITERATR_RETENTION_MAX_MSGS_PER_SUBJECT"); err != nil {
+		return nil, fmt.Errorf("binding retention.max_msgs_per_subject env: %w", err)
+	}
+	if err := v.BindEnv("budget.max_tokens", "ITERATR_BUDGET_MAX_TOKENS"); err != nil {
+		return nil, fmt.Errorf("binding budget.max_tokens env: %w", err)
+	}
+	if err := v.BindEnv("budget.max_cost", "ITERATR_BUDGET_MAX_COST"); err != nil {
+		return nil, fmt.Errorf("binding budget.max_cost env: %w", err)
+	}
+	if err := v.BindEnv("budget.max_duration", "ITERATR_BUDGET_MAX_DURATION"); err != nil {
+		return nil, fmt.Errorf("binding budget.max_duration env: %w", err)
+	}
+	if err := v.BindEnv("budget.action", "ITERATR_BUDGET_ACTION"); err != nil {
+		return nil, fmt.Errorf("binding budget.action env: %w", err)
+	}
+	if err := v.BindEnv("retry.max_attempts", "ITERATR_RETRY_MAX_ATTEMPTS"); err != nil {
+		return nil, fmt.Errorf("binding retry.max_attempts env: %w", err)
+	}
+	if err := v.BindEnv("retry.initial_wait", "ITERATR_RETRY_INITIAL_WAIT"); err != nil {
+		return nil, fmt.Errorf("binding retry.initial_wait env: %w", err)
+	}
+	if err := v.BindEnv("retry.max_wait", "ITERATR_RETRY_MAX_WAIT"); err != nil {
+		return nil, fmt.Errorf("binding retry.max_wait env: %w", err)
+	}
 
 	// Load global config first (if exists)
 	globalPath := GlobalPath()
@@ -113,6 +259,47 @@ func (c *Config) Validate() error {
 	if c.Model == "" {
 		return fmt.Errorf("model is required")
 	}
+	if c.Retention.MaxAgeDays < 0 || c.Retention.MaxBytes < 0 || c.Retention.MaxMsgsPerSubject < 0 {
+		return fmt.Errorf("retention limits must be >= 0 (0 means unlimited)")
+	}
+	for _, p := range c.Prices {
+		if p.Model == "" {
+			return fmt.Errorf("prices: model is required")
+		}
+		if p.Input < 0 || p.Output < 0 || p.CacheRead < 0 || p.CacheWrite < 0 {
+			return fmt.Errorf("prices: %s has a negative price", p.Model)
+		}
+	}
+	if c.PromptBudget < 0 {
+		return fmt.Errorf("prompt budget must be >= 0 (0 means unlimited)")
+	}
+	if c.TranscriptMaxBytes < -1 {
+		return fmt.Errorf("transcript max bytes must be >= 0 (0 means unlimited), or -1 to disable transcripts")
+	}
+	if c.IterationTimeout < 0 || c.IdleTimeout < 0 {
+		return fmt.Errorf("iteration and idle timeouts must be >= 0 (0 means no limit)")
+	}
+	if c.Retry.MaxAttempts < 0 || c.Retry.InitialWait < 0 || c.Retry.MaxWait < 0 {
+		return fmt.Errorf("retry settings must be >= 0")
+	}
+	if c.Budget.MaxTokens < 0 || c.Budget.MaxCost < 0 || c.Budget.MaxDuration < 0 {
+		return fmt.Errorf("budget limits must be >= 0 (0 means unlimited)")
+	}
+	switch c.CommitMode {
+	case "", "agent", "native", "native-llm":
+	default:
+		return fmt.Errorf("commit mode must be \"agent\", \"native\", or \"native-llm\", got %q", c.CommitMode)
+	}
+	switch c.TemplateMode {
+	case "", "auto", "legacy", "go":
+	default:
+		return fmt.Errorf("template mode must be \"auto\", \"legacy\", or \"go\", got %q", c.TemplateMode)
+	}
+	switch c.Budget.Action {
+	case "", "stop", "pause":
+	default:
+		return fmt.Errorf("budget action must be \"stop\" or \"pause\", got %q", c.Budget.Action)
+	}
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestGlobalPath(t *testing.T) {
@@ -331,6 +332,254 @@ func TestLoad_NoConfig(t *testing.T) {
 	if cfg.CommitDataDir != false {
 		t.Errorf("Load() default CommitDataDir = %v, want false", cfg.CommitDataDir)
 	}
+	if cfg.Retention.MaxAgeDays != 30 {
+		t.Errorf("Load() default Retention.MaxAgeDays = %v, want 30", cfg.Retention.MaxAgeDays)
+	}
+	if cfg.Retention.MaxAge() != 30*24*time.Hour {
+		t.Errorf("Load() default Retention.MaxAge() = %v, want 720h", cfg.Retention.MaxAge())
+	}
+	if cfg.Retention.MaxBytes != 0 || cfg.Retention.MaxMsgsPerSubject != 0 {
+		t.Errorf("Load() default size limits = %v/%v, want 0/0", cfg.Retention.MaxBytes, cfg.Retention.MaxMsgsPerSubject)
+	}
+}
+
+func TestLoad_RetentionFromEnvAndFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	content := `retention:
+  max_age_days: 90
+  max_bytes: 1048576
+`
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Retention.MaxAgeDays != 90 {
+		t.Errorf("Retention.MaxAgeDays = %v, want 90", cfg.Retention.MaxAgeDays)
+	}
+	if cfg.Retention.MaxBytes != 1048576 {
+		t.Errorf("Retention.MaxBytes = %v, want 1048576", cfg.Retention.MaxBytes)
+	}
+
+	// Env overrides file; 0 means keep forever
+	t.Setenv("ITERATR_RETENTION_MAX_AGE_DAYS", "0")
+	t.Setenv("ITERATR_RETENTION_MAX_MSGS_PER_SUBJECT", "500")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Retention.MaxAge() != 0 {
+		t.Errorf("Retention.MaxAge() = %v, want 0", cfg.Retention.MaxAge())
+	}
+	if cfg.Retention.MaxMsgsPerSubject != 500 {
+		t.Errorf("Retention.MaxMsgsPerSubject = %v, want 500", cfg.Retention.MaxMsgsPerSubject)
+	}
+}
+
+func TestLoad_PricesFromFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	content := `model: anthropic/claude-sonnet-4.5
+prices:
+  - model: anthropic/claude-sonnet-4.5
+    input: 3
+    output: 15
+    cache_read: 0.3
+`
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := PriceConfig{Model: "anthropic/claude-sonnet-4.5", Input: 3, Output: 15, CacheRead: 0.3}
+	if len(cfg.Prices) != 1 || cfg.Prices[0] != want {
+		t.Errorf("Prices = %+v, want [%+v]", cfg.Prices, want)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.Prices[0].Output = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject negative prices")
+	}
+}
+
+func TestLoad_BudgetFromEnvAndFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	content := `budget:
+  max_tokens: 2000000
+  max_duration: 8h
+`
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := BudgetConfig{MaxTokens: 2000000, MaxDuration: 8 * time.Hour, Action: "stop"}
+	if cfg.Budget != want {
+		t.Errorf("Budget = %+v, want %+v", cfg.Budget, want)
+	}
+
+	// Env overrides file
+	t.Setenv("ITERATR_BUDGET_MAX_COST", "12.5")
+	t.Setenv("ITERATR_BUDGET_ACTION", "pause")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Budget.MaxCost != 12.5 || cfg.Budget.Action != "pause" {
+		t.Errorf("Budget = %+v, want max_cost 12.5 and action pause", cfg.Budget)
+	}
+}
+
+func TestLoad_TimeoutsFromEnvAndFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	if err := os.WriteFile("iteratr.yml", []byte("iteration_timeout: 45m\n"), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+	t.Setenv("ITERATR_IDLE_TIMEOUT", "10m")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.IterationTimeout != 45*time.Minute {
+		t.Errorf("IterationTimeout = %v, want 45m", cfg.IterationTimeout)
+	}
+	if cfg.IdleTimeout != 10*time.Minute {
+		t.Errorf("IdleTimeout = %v, want 10m", cfg.IdleTimeout)
+	}
+}
+
+func TestLoad_TranscriptMaxBytesDefaultAndEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.TranscriptMaxBytes != 4*1024*1024 {
+		t.Errorf("TranscriptMaxBytes = %d, want 4MiB", cfg.TranscriptMaxBytes)
+	}
+
+	t.Setenv("ITERATR_TRANSCRIPT_MAX_BYTES", "-1")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.TranscriptMaxBytes != -1 {
+		t.Errorf("TranscriptMaxBytes = %d, want -1", cfg.TranscriptMaxBytes)
+	}
+}
+
+func TestLoad_RetryDefaultsAndEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := RetryConfig{MaxAttempts: 4, InitialWait: 5 * time.Second, MaxWait: time.Minute}
+	if cfg.Retry != want {
+		t.Errorf("Retry = %+v, want %+v", cfg.Retry, want)
+	}
+
+	t.Setenv("ITERATR_RETRY_MAX_ATTEMPTS", "1")
+	t.Setenv("ITERATR_RETRY_INITIAL_WAIT", "30s")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Retry.MaxAttempts != 1 || cfg.Retry.InitialWait != 30*time.Second {
+		t.Errorf("Retry = %+v, want max_attempts 1 and initial_wait 30s", cfg.Retry)
+	}
+}
+
+func TestLoad_WorktreeFromFileAndEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Worktree {
+		t.Error("Worktree should default to false")
+	}
+
+	if err := os.WriteFile("iteratr.yml", []byte("worktree: true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Worktree {
+		t.Error("Worktree = false, want true from config file")
+	}
+
+	t.Setenv("ITERATR_WORKTREE", "false")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Worktree {
+		t.Error("Worktree = true, want ENV override to false")
+	}
 }
 
 func TestLoad_WithGlobalConfig(t *testing.T) {
@@ -416,6 +665,62 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "invalid config with negative retention",
+			config: &Config{
+				Model:     "anthropic/claude-sonnet-4-5",
+				Retention: RetentionConfig{MaxAgeDays: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid config with negative budget",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Budget: BudgetConfig{MaxCost: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid config with unknown budget action",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Budget: BudgetConfig{Action: "halt"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid config with native commit mode",
+			config: &Config{
+				Model:      "anthropic/claude-sonnet-4-5",
+				CommitMode: "native-llm",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid config with unknown commit mode",
+			config: &Config{
+				Model:      "anthropic/claude-sonnet-4-5",
+				CommitMode: "manual",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid config with transcripts off",
+			config: &Config{
+				Model:              "anthropic/claude-sonnet-4-5",
+				TranscriptMaxBytes: -1,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid config with negative transcript cap",
+			config: &Config{
+				Model:              "anthropic/claude-sonnet-4-5",
+				TranscriptMaxBytes: -2,
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid config with empty model",
 			config: &Config{
@@ -450,3 +755,39 @@ func containsAt(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoad_TemplateSettingsFromFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	content := `template_mode: go
+prompt_budget: 30000
+template_vars:
+  team: platform
+  test_command: make test
+`
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.TemplateMode != "go" || cfg.PromptBudget != 30000 {
+		t.Errorf("TemplateMode = %q, PromptBudget = %d, want go and 30000", cfg.TemplateMode, cfg.PromptBudget)
+	}
+	if cfg.TemplateVars["team"] != "platform" || cfg.TemplateVars["test_command"] != "make test" {
+		t.Errorf("TemplateVars = %v", cfg.TemplateVars)
+	}
+
+	cfg.TemplateMode = "jinja"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject unknown template modes")
+	}
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Sentinel errors for common failure conditions
@@ -95,6 +96,30 @@ func NewPermanentError(op string, err error) *PermanentError {
 	return &PermanentError{Op: op, Err: err}
 }
 
+// Timeout kinds reported by TimeoutError
+const (
+	TimeoutIteration = "iteration_timeout" // Iteration exceeded its wall-clock limit
+	TimeoutIdle      = "idle_timeout"      // Agent produced no output for too long
+)
+
+// TimeoutError represents an agent run cancelled by a watchdog
+type TimeoutError struct {
+	Kind  string        // TimeoutIteration or TimeoutIdle
+	Limit time.Duration // Limit that was exceeded
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Kind == TimeoutIdle {
+		return fmt.Sprintf("agent idle for %s (no text or tool activity)", e.Limit)
+	}
+	return fmt.Sprintf("iteration exceeded time limit of %s", e.Limit)
+}
+
+// Is implements error comparison for errors.Is
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
 // MultiError aggregates multiple errors
 type MultiError struct {
 	Errors []error
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 )
 
 func TestValidationError(t *testing.T) {
@@ -72,6 +74,29 @@ func TestPermanentError(t *testing.T) {
 	})
 }
 
+func TestTimeoutError(t *testing.T) {
+	t.Run("Error message format", func(t *testing.T) {
+		err := &TimeoutError{Kind: TimeoutIteration, Limit: 30 * time.Minute}
+		expected := "iteration exceeded time limit of 30m0s"
+		if err.Error() != expected {
+			t.Errorf("expected %q, got %q", expected, err.Error())
+		}
+
+		err = &TimeoutError{Kind: TimeoutIdle, Limit: 5 * time.Minute}
+		expected = "agent idle for 5m0s (no text or tool activity)"
+		if err.Error() != expected {
+			t.Errorf("expected %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Is ErrTimeout", func(t *testing.T) {
+		err := fmt.Errorf("iteration #3 failed: %w", &TimeoutError{Kind: TimeoutIdle, Limit: time.Minute})
+		if !errors.Is(err, ErrTimeout) {
+			t.Error("wrapped TimeoutError should match ErrTimeout")
+		}
+	})
+}
+
 func TestMultiError(t *testing.T) {
 	t.Run("Empty MultiError", func(t *testing.T) {
 		me := &MultiError{}
@@ -13,6 +13,9 @@ type RetryConfig struct {
 	InitialWait time.Duration // Initial wait before first retry
 	MaxWait     time.Duration // Maximum wait between retries
 	Multiplier  float64       // Backoff multiplier (e.g., 2.0 for exponential)
+
+	// OnRetry is called after a failed attempt, before waiting to retry (optional)
+	OnRetry func(attempt int, wait time.Duration, err error)
 }
 
 // DefaultRetryConfig returns sensible defaults for retry behavior
@@ -58,6 +61,10 @@ func Retry(ctx context.Context, cfg RetryConfig, fn func() error) error {
 		default:
 		}
 
+		if cfg.OnRetry != nil {
+			cfg.OnRetry(attempt, wait, err)
+		}
+
 		// Wait before retry
 		timer := time.NewTimer(wait)
 		select {
@@ -110,6 +117,10 @@ func RetryWithResult[T any](ctx context.Context, cfg RetryConfig, fn func() (T,
 		default:
 		}
 
+		if cfg.OnRetry != nil {
+			cfg.OnRetry(attempt, wait, err)
+		}
+
 		// Wait before retry
 		timer := time.NewTimer(wait)
 		select {
@@ -134,6 +134,33 @@ func TestRetry(t *testing.T) {
 			t.Errorf("expected at most 2 attempts before cancellation, got %d", attempts)
 		}
 	})
+
+	t.Run("OnRetry reports each retry with its wait", func(t *testing.T) {
+		ctx := context.Background()
+		type retry struct {
+			attempt int
+			wait    time.Duration
+		}
+		var retries []retry
+		cfg := RetryConfig{
+			MaxAttempts: 3,
+			InitialWait: 10 * time.Millisecond,
+			MaxWait:     15 * time.Millisecond,
+			Multiplier:  2.0,
+			OnRetry: func(attempt int, wait time.Duration, err error) {
+				retries = append(retries, retry{attempt, wait})
+			},
+		}
+
+		_ = Retry(ctx, cfg, func() error {
+			return NewTransientError("op", errors.New("temp"))
+		})
+
+		want := []retry{{1, 10 * time.Millisecond}, {2, 15 * time.Millisecond}}
+		if len(retries) != len(want) || retries[0] != want[0] || retries[1] != want[1] {
+			t.Errorf("expected retries %v, got %v", want, retries)
+		}
+	})
 }
 
 func TestRetryWithResult(t *testing.T) {
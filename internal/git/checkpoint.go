@@ -0,0 +1,232 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CheckpointRefPrefix is the ref namespace holding iteration checkpoints.
+const CheckpointRefPrefix = "refs/iteratr/"
+
+// checkpointIdentity lets commit-tree work in repositories without a
+// configured user; checkpoint commits never land on a branch.
+var checkpointIdentity = []string{
+	"GIT_AUTHOR_NAME=iteratr",
+	"GIT_AUTHOR_EMAIL=iteratr@localhost",
+	"GIT_COMMITTER_NAME=iteratr",
+	"GIT_COMMITTER_EMAIL=iteratr@localhost",
+}
+
+// CheckpointRef returns the ref for the checkpoint taken before an iteration
+// of a session.
+func CheckpointRef(session string, iteration int) string {
+	return fmt.Sprintf("%s%s/%d", CheckpointRefPrefix, session, iteration)
+}
+
+// CheckpointExists reports whether the repository containing dir has a
+// checkpoint under ref.
+func CheckpointExists(dir, ref string) bool {
+	root, err := repoRoot(dir)
+	if err != nil {
+		return false
+	}
+	_, err = gitCommand(root, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return err == nil
+}
+
+// RestoreResult describes what RestoreCheckpoint changed.
+type RestoreResult struct {
+	Restored  []string // Paths written back from the checkpoint (repo-relative)
+	Removed   []string // Paths created after the checkpoint and deleted
+	HeadMoved bool     // HEAD no longer points at the commit the checkpoint was taken on
+}
+
+// Checkpoint records the working tree of the repository containing dir as a
+// commit under ref, like `git stash` but without touching HEAD, the index, or
+// any files. Tracked and untracked files are included; ignored files and the
+// exclude paths (e.g. the data directory) are not. Returns the commit hash.
+func Checkpoint(dir, ref string, exclude ...string) (string, error) {
+	root, err := repoRoot(dir)
+	if err != nil {
+		return "", err
+	}
+	tree, err := snapshotTree(root, exclude)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"commit-tree", tree, "-m", "iteratr checkpoint " + ref}
+	if head, err := gitCommand(root, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		args = append(args, "-p", head)
+	}
+	commit, err := gitCommandEnv(root, checkpointIdentity, args...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := gitCommand(root, "update-ref", ref, commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// RestoreCheckpoint makes the working tree of the repository containing dir
+// match the checkpoint under ref: files changed or deleted since are written
+// back and files created since are removed. HEAD, the index, ignored files,
+// and the exclude paths are left alone, so commits made after the checkpoint
+// stay in history and show up as local changes.
+func RestoreCheckpoint(dir, ref string, exclude ...string) (*RestoreResult, error) {
+	root, err := repoRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := gitCommand(root, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint %s not found", ref)
+	}
+	current, err := snapshotTree(root, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := gitCommand(root, "diff-tree", "-r", "-z", "--no-renames", "--name-status", commit, current)
+	if err != nil {
+		return nil, err
+	}
+	result := &RestoreResult{}
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, path := fields[i], fields[i+1]
+		if status == "A" {
+			result.Removed = append(result.Removed, path)
+		} else {
+			result.Restored = append(result.Restored, path)
+		}
+	}
+
+	if len(result.Restored) > 0 {
+		args := append([]string{"--literal-pathspecs", "restore", "--source=" + commit, "--worktree", "--"}, result.Restored...)
+		if _, err := gitCommand(root, args...); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range result.Removed {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removeEmptyParents(root, filepath.Dir(full))
+	}
+
+	parent, _ := gitCommand(root, "rev-parse", "--verify", "--quiet", commit+"^")
+	head, _ := gitCommand(root, "rev-parse", "--verify", "--quiet", "HEAD")
+	result.HeadMoved = parent != head
+	return result, nil
+}
+
+// snapshotTree writes the working tree of root to a tree object using a
+// throwaway index, and returns the tree hash.
+func snapshotTree(root string, exclude []string) (string, error) {
+	tmp, err := os.CreateTemp("", "iteratr-index-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	indexPath := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(indexPath) }()
+
+	// Start from the real index so unchanged files need not be rehashed
+	env := []string{"GIT_INDEX_FILE=" + indexPath}
+	if err := copyIndex(root, indexPath); err != nil {
+		_ = os.Remove(indexPath)
+		if _, err := gitCommand(root, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+			if _, err := gitCommandEnv(root, env, "read-tree", "HEAD"); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	args := []string{"add", "-A", "--", "."}
+	for _, path := range exclude {
+		rel, ok := relativeTo(root, path)
+		if !ok {
+			continue
+		}
+		// git add refuses pathspecs naming ignored paths, even exclusions;
+		// ignored paths are left out anyway
+		if _, err := gitCommand(root, "check-ignore", "-q", "--", rel); err == nil {
+			continue
+		}
+		args = append(args, ":(exclude,literal)"+filepath.ToSlash(rel))
+	}
+	if _, err := gitCommandEnv(root, env, args...); err != nil {
+		return "", err
+	}
+	return gitCommandEnv(root, env, "write-tree")
+}
+
+// copyIndex copies the repository's index file to dst.
+func copyIndex(root, dst string) error {
+	src, err := gitCommand(root, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(src) {
+		src = filepath.Join(root, src)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// repoRoot returns the top-level directory of the repository containing dir.
+func repoRoot(dir string) (string, error) {
+	if !isGitRepo(dir) {
+		return "", fmt.Errorf("%s is not a git repository", dir)
+	}
+	return gitCommand(dir, "rev-parse", "--show-toplevel")
+}
+
+// relativeTo returns path relative to root if path lies inside root.
+func relativeTo(root, path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	// Compare resolved paths (e.g. /tmp vs /private/tmp on macOS)
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// removeEmptyParents removes dir and its parents up to (not including) root
+// while they are empty.
+func removeEmptyParents(root, dir string) {
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,150 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCheckpointRestore(t *testing.T) {
+	repo := initRepo(t)
+	if err := writeFile(filepath.Join(repo, "tracked.txt"), "v1\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(repo, ".gitignore"), "ignored.txt\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitCommand(repo, "add", "-A"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitCommandEnv(repo, checkpointIdentity, "commit", "-q", "-m", "add files"); err != nil {
+		t.Fatal(err)
+	}
+	// Uncommitted work and an untracked file are part of the checkpoint
+	if err := writeFile(filepath.Join(repo, "tracked.txt"), "v2\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(repo, "notes.txt"), "keep me\n"); err != nil {
+		t.Fatal(err)
+	}
+	dataDir := filepath.Join(repo, ".iteratr")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(dataDir, "state"), "a\n"); err != nil {
+		t.Fatal(err)
+	}
+	statusBefore, _ := gitCommand(repo, "status", "--porcelain")
+
+	ref := CheckpointRef("demo", 2)
+	if ref != "refs/iteratr/demo/2" {
+		t.Errorf("CheckpointRef() = %q", ref)
+	}
+	if _, err := Checkpoint(repo, ref, dataDir); err != nil {
+		t.Fatalf("Checkpoint() error: %v", err)
+	}
+	if statusAfter, _ := gitCommand(repo, "status", "--porcelain"); statusAfter != statusBefore {
+		t.Errorf("Checkpoint() changed git status:\nbefore: %s\nafter:  %s", statusBefore, statusAfter)
+	}
+
+	// A bad iteration edits, deletes, and creates files
+	if err := writeFile(filepath.Join(repo, "tracked.txt"), "broken\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(repo, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "new", "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(repo, "new", "dir", "junk.go"), "junk\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(repo, "ignored.txt"), "build output\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(dataDir, "state"), "b\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RestoreCheckpoint(repo, ref, dataDir)
+	if err != nil {
+		t.Fatalf("RestoreCheckpoint() error: %v", err)
+	}
+	slices.Sort(result.Restored)
+	if !slices.Equal(result.Restored, []string{"notes.txt", "tracked.txt"}) {
+		t.Errorf("Restored = %v", result.Restored)
+	}
+	if !slices.Equal(result.Removed, []string{"new/dir/junk.go"}) {
+		t.Errorf("Removed = %v", result.Removed)
+	}
+	if result.HeadMoved {
+		t.Error("HeadMoved should be false")
+	}
+
+	for path, want := range map[string]string{
+		"tracked.txt":    "v2\n",
+		"notes.txt":      "keep me\n",
+		"ignored.txt":    "build output\n",
+		".iteratr/state": "b\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(repo, path))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q (%v), want %q", path, got, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo, "new")); !os.IsNotExist(err) {
+		t.Error("empty directories left by removed files should be cleaned up")
+	}
+	if statusAfter, _ := gitCommand(repo, "status", "--porcelain"); statusAfter != statusBefore {
+		t.Errorf("restore did not bring back the checkpointed status:\nwant: %s\ngot:  %s", statusBefore, statusAfter)
+	}
+
+	// Commits made after the checkpoint are reported, not undone
+	if _, err := gitCommandEnv(repo, checkpointIdentity, "commit", "-q", "-am", "later"); err != nil {
+		t.Fatal(err)
+	}
+	result, err = RestoreCheckpoint(repo, ref, dataDir)
+	if err != nil {
+		t.Fatalf("RestoreCheckpoint() after commit error: %v", err)
+	}
+	if !result.HeadMoved {
+		t.Error("HeadMoved should be true after a commit")
+	}
+}
+
+func TestRestoreCheckpoint_Missing(t *testing.T) {
+	repo := initRepo(t)
+	if _, err := RestoreCheckpoint(repo, CheckpointRef("demo", 9)); err == nil {
+		t.Error("expected error for a missing checkpoint")
+	}
+	if CheckpointExists(repo, CheckpointRef("demo", 9)) {
+		t.Error("CheckpointExists() = true for a missing checkpoint")
+	}
+	if _, err := Checkpoint(repo, CheckpointRef("demo", 9)); err != nil {
+		t.Fatalf("Checkpoint() error: %v", err)
+	}
+	if !CheckpointExists(repo, CheckpointRef("demo", 9)) {
+		t.Error("CheckpointExists() = false after Checkpoint")
+	}
+}
+
+func TestCheckpointIgnoredDataDir(t *testing.T) {
+	repo := initRepo(t)
+	if err := writeFile(filepath.Join(repo, ".gitignore"), ".iteratr/\n"); err != nil {
+		t.Fatal(err)
+	}
+	dataDir := filepath.Join(repo, ".iteratr")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(dataDir, "state"), "a\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// git add rejects an exclusion pathspec that names an ignored path
+	if _, err := Checkpoint(repo, CheckpointRef("demo", 1), dataDir); err != nil {
+		t.Fatalf("Checkpoint() error: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CommitPaths stages exactly paths (relative to dir) and commits them with
+// message. Changes to other files, staged or not, are left out of the commit.
+// Ignored files, paths outside dir, and paths that neither exist nor are
+// tracked (created and deleted again) are skipped. Returns the new commit
+// hash, or "" if none of the paths had changes to commit.
+func CommitPaths(dir string, paths []string, message string) (string, error) {
+	if !isGitRepo(dir) {
+		return "", fmt.Errorf("%s is not a git repository", dir)
+	}
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	paths = localPaths(paths)
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	tracked, err := lsFiles(dir, paths)
+	if err != nil {
+		return "", err
+	}
+	ignored, err := lsFiles(dir, paths, "--others", "--ignored", "--exclude-standard")
+	if err != nil {
+		return "", err
+	}
+	var kept []string
+	for _, path := range paths {
+		path = filepath.ToSlash(path)
+		if tracked[path] {
+			kept = append(kept, path)
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(dir, path)); err == nil && !ignored[path] {
+			kept = append(kept, path)
+		}
+	}
+	if len(kept) == 0 {
+		return "", nil
+	}
+
+	if _, err := gitCommand(dir, append([]string{"--literal-pathspecs", "add", "-A", "--"}, kept...)...); err != nil {
+		return "", err
+	}
+	// Exit status 0 means nothing staged for these paths
+	if _, err := gitCommand(dir, append([]string{"--literal-pathspecs", "diff", "--cached", "--quiet", "--"}, kept...)...); err == nil {
+		return "", nil
+	}
+	if _, err := gitCommand(dir, append([]string{"--literal-pathspecs", "commit", "-q", "--only", "-m", message, "--"}, kept...)...); err != nil {
+		return "", err
+	}
+	return gitCommand(dir, "rev-parse", "HEAD")
+}
+
+// localPaths returns the relative paths in paths that do not climb out of
+// their base directory.
+func localPaths(paths []string) []string {
+	var local []string
+	for _, path := range paths {
+		if !filepath.IsAbs(path) && path != ".." && !strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+			local = append(local, path)
+		}
+	}
+	return local
+}
+
+// lsFiles returns the set of paths (slash-separated, relative to dir) that
+// `git ls-files` lists for the given paths and extra options.
+func lsFiles(dir string, paths []string, opts ...string) (map[string]bool, error) {
+	args := append([]string{"--literal-pathspecs", "ls-files", "-z"}, opts...)
+	args = append(args, "--")
+	args = append(args, paths...)
+	out, err := gitCommand(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool)
+	for path := range strings.SplitSeq(out, "\x00") {
+		if path != "" {
+			result[path] = true
+		}
+	}
+	return result, nil
+}
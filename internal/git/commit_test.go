@@ -0,0 +1,76 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommitPaths(t *testing.T) {
+	repo := initRepo(t)
+	for name, content := range map[string]string{
+		"keep.txt":   "v1\n",
+		"gone.txt":   "bye\n",
+		"other.txt":  "other\n",
+		".gitignore": "build.log\n",
+	} {
+		if err := writeFile(filepath.Join(repo, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := gitCommand(repo, "add", "-A"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitCommandEnv(repo, checkpointIdentity, "commit", "-q", "-m", "files"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The iteration edits, deletes, and creates files; the user has
+	// unrelated work staged that must stay out of the commit
+	if err := writeFile(filepath.Join(repo, "keep.txt"), "v2\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(repo, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(repo, "new.txt"), "new\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(repo, "build.log"), "noise\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(repo, "other.txt"), "user edit\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitCommand(repo, "add", "other.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{"keep.txt", "gone.txt", "new.txt", "build.log", "temp.txt"}
+	hash, err := CommitPaths(repo, paths, "feat: do the thing\n\nIteratr-Session: demo")
+	if err != nil {
+		t.Fatalf("CommitPaths() error: %v", err)
+	}
+	if hash == "" {
+		t.Fatal("expected a commit")
+	}
+
+	files, _ := gitCommand(repo, "show", "--name-status", "--format=", "HEAD")
+	want := "D\tgone.txt\nM\tkeep.txt\nA\tnew.txt"
+	if files != want {
+		t.Errorf("committed files:\n%s\nwant:\n%s", files, want)
+	}
+	if msg, _ := gitCommand(repo, "log", "-1", "--format=%B"); !strings.Contains(msg, "Iteratr-Session: demo") {
+		t.Errorf("commit message = %q", msg)
+	}
+	if status, _ := gitCommand(repo, "status", "--porcelain"); !strings.Contains(status, "M  other.txt") {
+		t.Errorf("user's staged change should remain staged, status:\n%s", status)
+	}
+
+	// Nothing left to commit for these paths
+	hash, err = CommitPaths(repo, paths, "again")
+	if err != nil || hash != "" {
+		t.Errorf("second CommitPaths() = %q, %v; want no commit", hash, err)
+	}
+}
@@ -0,0 +1,32 @@
+package git
+
+// emptyTree is the hash of the empty tree, the diff base in a repository
+// without commits.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// Diff returns a unified diff of paths (relative to dir) between HEAD and the
+// working tree, including new and deleted files. Neither the index nor any
+// file is touched. Ignored files and paths outside dir are skipped.
+func Diff(dir string, paths []string) (string, error) {
+	root, err := repoRoot(dir)
+	if err != nil {
+		return "", err
+	}
+
+	paths = localPaths(paths)
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	current, err := snapshotTree(root, nil)
+	if err != nil {
+		return "", err
+	}
+	base := emptyTree
+	if head, err := gitCommand(root, "rev-parse", "--verify", "--quiet", "HEAD^{tree}"); err == nil {
+		base = head
+	}
+
+	args := append([]string{"--literal-pathspecs", "diff", "--no-color", "--no-ext-diff", base, current, "--"}, paths...)
+	return gitCommand(dir, args...)
+}
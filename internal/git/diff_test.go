@@ -0,0 +1,66 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	repo := initRepo(t)
+	for name, content := range map[string]string{
+		"edit.txt":  "old\n",
+		"gone.txt":  "bye\n",
+		"other.txt": "other\n",
+	} {
+		if err := writeFile(filepath.Join(repo, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := gitCommand(repo, "add", "-A"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitCommandEnv(repo, checkpointIdentity, "commit", "-q", "-m", "files"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFile(filepath.Join(repo, "edit.txt"), "new\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(repo, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(repo, "added.txt"), "hello\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(repo, "other.txt"), "not reviewed\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := Diff(repo, []string{"edit.txt", "gone.txt", "added.txt", "../outside.txt"})
+	if err != nil {
+		t.Fatalf("Diff() error: %v", err)
+	}
+	for _, want := range []string{
+		"diff --git a/edit.txt b/edit.txt", "-old", "+new",
+		"diff --git a/gone.txt b/gone.txt", "deleted file mode", "-bye",
+		"diff --git a/added.txt b/added.txt", "new file mode", "+hello",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "other.txt") {
+		t.Errorf("diff should only cover the given paths:\n%s", diff)
+	}
+
+	// The real index is untouched: the new file is still untracked
+	if status, _ := gitCommand(repo, "status", "--porcelain", "--", "added.txt"); status != "?? added.txt" {
+		t.Errorf("added.txt status = %q, want untracked", status)
+	}
+
+	if diff, err := Diff(repo, nil); err != nil || diff != "" {
+		t.Errorf("Diff(nil) = %q, %v; want empty", diff, err)
+	}
+}
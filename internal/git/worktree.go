@@ -0,0 +1,131 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WorktreeBranchPrefix is prepended to session names to form worktree branch names.
+const WorktreeBranchPrefix = "iteratr/"
+
+// Worktree is a linked git worktree with its own branch checked out.
+type Worktree struct {
+	Path   string // Absolute worktree directory
+	Branch string // Branch checked out in the worktree
+	Reused bool   // True if the worktree already existed (resumed session)
+}
+
+// WorktreeBranch returns the branch name for a session's worktree.
+func WorktreeBranch(session string) string {
+	return WorktreeBranchPrefix + session
+}
+
+// AddWorktree creates a worktree at path with branch checked out, branching
+// from the current HEAD of repoDir if the branch does not exist yet.
+// An existing worktree at path on the same branch is reused, so resumed
+// sessions pick up where they left off.
+func AddWorktree(repoDir, path, branch string) (*Worktree, error) {
+	if !isGitRepo(repoDir) {
+		return nil, fmt.Errorf("%s is not a git repository", repoDir)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve worktree path: %w", err)
+	}
+
+	// Forget worktrees whose directories were deleted by hand
+	if _, err := gitCommand(repoDir, "worktree", "prune"); err != nil {
+		return nil, err
+	}
+
+	existing, err := worktreeBranches(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	resolved := absPath
+	if r, err := filepath.EvalSymlinks(absPath); err == nil {
+		resolved = r
+	}
+	if current, ok := existing[resolved]; ok {
+		if current != branch {
+			return nil, fmt.Errorf("worktree %s has branch %q checked out, expected %q", absPath, current, branch)
+		}
+		return &Worktree{Path: absPath, Branch: branch, Reused: true}, nil
+	}
+	if _, err := os.Stat(absPath); err == nil {
+		return nil, fmt.Errorf("worktree path %s already exists and is not a worktree of this repository", absPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create worktree parent directory: %w", err)
+	}
+	args := []string{"worktree", "add", absPath, branch}
+	if _, err := gitCommand(repoDir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err != nil {
+		args = []string{"worktree", "add", "-b", branch, absPath, "HEAD"}
+	}
+	if _, err := gitCommand(repoDir, args...); err != nil {
+		return nil, err
+	}
+	return &Worktree{Path: absPath, Branch: branch}, nil
+}
+
+// RemoveWorktree removes a clean worktree directory. Its branch is kept.
+// Fails (leaving the worktree in place) if it has uncommitted changes.
+func RemoveWorktree(repoDir, path string) error {
+	_, err := gitCommand(repoDir, "worktree", "remove", path)
+	return err
+}
+
+// worktreeBranches maps absolute worktree paths to their checked-out branch
+// (empty for detached HEAD), parsed from `git worktree list --porcelain`.
+func worktreeBranches(repoDir string) (map[string]string, error) {
+	out, err := gitCommand(repoDir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	var path string
+	for line := range strings.SplitSeq(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path = filepath.Clean(strings.TrimPrefix(line, "worktree "))
+			// Compare resolved paths (e.g. /tmp vs /private/tmp on macOS)
+			if resolved, err := filepath.EvalSymlinks(path); err == nil {
+				path = resolved
+			}
+			result[path] = ""
+		case strings.HasPrefix(line, "branch "):
+			result[path] = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		}
+	}
+	return result, nil
+}
+
+// gitCommand runs git in dir and returns trimmed stdout.
+// Unlike runGit, errors include git's stderr for display to the user.
+func gitCommand(dir string, args ...string) (string, error) {
+	return gitCommandEnv(dir, nil, args...)
+}
+
+// gitCommandEnv is gitCommand with extra environment variables.
+func gitCommandEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
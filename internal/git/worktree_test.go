@@ -0,0 +1,95 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initRepo creates a git repository with one commit in a temp directory.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestAddWorktree(t *testing.T) {
+	repo := initRepo(t)
+	path := filepath.Join(repo, ".iteratr", "worktrees", "my-session")
+	branch := WorktreeBranch("my-session")
+
+	wt, err := AddWorktree(repo, path, branch)
+	if err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	if wt.Branch != "iteratr/my-session" || wt.Reused {
+		t.Errorf("AddWorktree = %+v, want new worktree on iteratr/my-session", wt)
+	}
+	info, err := GetInfo(wt.Path)
+	if err != nil || info == nil || info.Branch != branch {
+		t.Fatalf("GetInfo(worktree) = %+v, %v, want branch %s", info, err, branch)
+	}
+
+	// Adding again reuses the worktree
+	again, err := AddWorktree(repo, path, branch)
+	if err != nil {
+		t.Fatalf("AddWorktree (reuse) failed: %v", err)
+	}
+	if !again.Reused {
+		t.Errorf("AddWorktree (reuse) = %+v, want Reused", again)
+	}
+
+	// A different branch at the same path is rejected
+	if _, err := AddWorktree(repo, path, "other"); err == nil {
+		t.Error("AddWorktree with a different branch should fail")
+	}
+
+	// Removing keeps the branch, so the worktree can be recreated from it
+	if err := RemoveWorktree(repo, wt.Path); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Errorf("worktree directory still exists after remove: %v", err)
+	}
+	recreated, err := AddWorktree(repo, path, branch)
+	if err != nil {
+		t.Fatalf("AddWorktree from existing branch failed: %v", err)
+	}
+	if recreated.Reused {
+		t.Error("recreated worktree should not be marked reused")
+	}
+}
+
+func TestRemoveWorktree_Dirty(t *testing.T) {
+	repo := initRepo(t)
+	wt, err := AddWorktree(repo, filepath.Join(t.TempDir(), "wt"), WorktreeBranch("dirty"))
+	if err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(wt.Path, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := RemoveWorktree(repo, wt.Path); err == nil {
+		t.Error("RemoveWorktree should refuse a worktree with uncommitted changes")
+	}
+}
+
+func TestAddWorktree_NotARepo(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := AddWorktree(dir, filepath.Join(dir, "wt"), "b"); err == nil {
+		t.Error("AddWorktree outside a git repository should fail")
+	}
+}
@@ -3,6 +3,7 @@ package hooks
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -48,6 +49,8 @@ type Variables struct {
 	TaskID      string
 	TaskContent string
 	Error       string
+	ErrorType   string // "error", "panic", "iteration_timeout", or "idle_timeout"
+	Budget      string // Crossed budget limit, e.g. "tokens 1.2M exceeds limit 1M"
 }
 
 // Execute runs a hook command and returns its output.
@@ -118,6 +121,54 @@ func Execute(ctx context.Context, hook *HookConfig, workDir string, vars Variabl
 	return output, nil
 }
 
+// ExecuteReview runs a review hook with the iteration's diff on stdin and
+// returns its stdout (the reviewer's feedback) and exit code. A hook that
+// times out or cannot be started reports exit code -1.
+// Only returns error for context cancellation.
+func ExecuteReview(ctx context.Context, hook *HookConfig, workDir string, vars Variables, diff string) (string, int, error) {
+	if hook == nil || hook.Command == "" {
+		return "", ReviewExitApprove, nil
+	}
+
+	command := expandVariables(hook.Command, vars)
+	logger.Debug("Executing review hook command: %s", command)
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(execCtx, "sh", "-c", command)
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(diff)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", -1, ctx.Err()
+	}
+	if stderr.Len() > 0 {
+		logger.Debug("Review hook stderr: %s", stderr.String())
+	}
+	if execCtx.Err() == context.DeadlineExceeded {
+		logger.Warn("Review hook timed out after %ds: %s", timeout, command)
+		return fmt.Sprintf("[Review hook timed out after %ds]\n%s", timeout, stdout.String()), -1, nil
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return stdout.String(), exitErr.ExitCode(), nil
+		}
+		logger.Warn("Review hook failed to run: %v", err)
+		return fmt.Sprintf("[Review hook failed: %v]\n%s", err, stdout.String()), -1, nil
+	}
+	return stdout.String(), ReviewExitApprove, nil
+}
+
 // ExecuteAll runs multiple hook commands and concatenates their output.
 // Returns combined output from all hooks separated by newlines.
 // Only returns error for context cancellation.
@@ -323,6 +374,8 @@ func expandVariables(command string, vars Variables) string {
 		"{{task_id}}":      vars.TaskID,
 		"{{task_content}}": vars.TaskContent,
 		"{{error}}":        vars.Error,
+		"{{error_type}}":   vars.ErrorType,
+		"{{budget}}":       vars.Budget,
 	}
 
 	result := command
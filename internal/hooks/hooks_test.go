@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -94,6 +95,71 @@ func TestExecuteAllPiped_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestExecuteReview(t *testing.T) {
+	ctx := context.Background()
+	workDir := t.TempDir()
+	vars := Variables{Session: "test", Iteration: "3"}
+	diff := "diff --git a/main.go b/main.go\n+TODO\n"
+
+	tests := []struct {
+		name     string
+		hook     *HookConfig
+		output   string
+		exitCode int
+	}{
+		{
+			name:     "approve",
+			hook:     &HookConfig{Command: "cat > /dev/null", Timeout: 5},
+			output:   "",
+			exitCode: ReviewExitApprove,
+		},
+		{
+			name:     "reject with feedback",
+			hook:     &HookConfig{Command: "grep -q TODO && echo 'iteration {{iteration}} left a TODO' && exit 3", Timeout: 5},
+			output:   "iteration 3 left a TODO\n",
+			exitCode: ReviewExitReject,
+		},
+		{
+			name:     "annotate",
+			hook:     &HookConfig{Command: "echo 'add tests'; exit 2", Timeout: 5},
+			output:   "add tests\n",
+			exitCode: ReviewExitAnnotate,
+		},
+		{
+			name:     "failing script",
+			hook:     &HookConfig{Command: "set -e; cat > /dev/null; false", Timeout: 5},
+			output:   "",
+			exitCode: 1,
+		},
+		{
+			name:     "nil hook approves",
+			hook:     nil,
+			output:   "",
+			exitCode: ReviewExitApprove,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, exitCode, err := ExecuteReview(ctx, tt.hook, workDir, vars, diff)
+			if err != nil {
+				t.Fatalf("ExecuteReview() error = %v", err)
+			}
+			if output != tt.output || exitCode != tt.exitCode {
+				t.Errorf("ExecuteReview() = %q, %d; expected %q, %d", output, exitCode, tt.output, tt.exitCode)
+			}
+		})
+	}
+
+	output, exitCode, err := ExecuteReview(ctx, &HookConfig{Command: "sleep 5", Timeout: 1}, workDir, vars, diff)
+	if err != nil {
+		t.Fatalf("ExecuteReview() error = %v", err)
+	}
+	if exitCode != -1 || !strings.Contains(output, "timed out") {
+		t.Errorf("timed out hook = %q, %d; expected exit code -1", output, exitCode)
+	}
+}
+
 func TestConfigParsing(t *testing.T) {
 	yamlContent := `
 version: 1
@@ -120,6 +186,11 @@ hooks:
     - command: "git diff HEAD"
       timeout: 10
       pipe_output: true
+  on_budget_exceeded:
+    - command: "./notify.sh '{{budget}}'"
+  review:
+    - command: "./review.sh"
+      timeout: 300
 `
 
 	var cfg Config
@@ -198,6 +269,20 @@ hooks:
 			t.Error("OnError[0].PipeOutput = false, expected true")
 		}
 	}
+
+	// Verify on_budget_exceeded
+	if len(cfg.Hooks.OnBudgetExceeded) != 1 {
+		t.Errorf("OnBudgetExceeded length = %d, expected 1", len(cfg.Hooks.OnBudgetExceeded))
+	} else if hook := cfg.Hooks.OnBudgetExceeded[0]; hook.Command != "./notify.sh '{{budget}}'" {
+		t.Errorf("OnBudgetExceeded[0].Command = %q, expected %q", hook.Command, "./notify.sh '{{budget}}'")
+	}
+
+	// Verify review
+	if len(cfg.Hooks.Review) != 1 {
+		t.Errorf("Review length = %d, expected 1", len(cfg.Hooks.Review))
+	} else if hook := cfg.Hooks.Review[0]; hook.Command != "./review.sh" || hook.Timeout != 300 {
+		t.Errorf("Review[0] = %+v, expected ./review.sh with timeout 300", hook)
+	}
 }
 
 func TestConfigParsing_EmptyHooks(t *testing.T) {
@@ -290,6 +375,18 @@ func TestExpandVariables(t *testing.T) {
 			vars:     Variables{Error: "connection timeout"},
 			expected: "echo 'Error occurred: connection timeout'",
 		},
+		{
+			name:     "error type variable",
+			command:  "./on-error.sh {{error_type}} '{{error}}'",
+			vars:     Variables{Error: "agent idle for 5m0s", ErrorType: "idle_timeout"},
+			expected: "./on-error.sh idle_timeout 'agent idle for 5m0s'",
+		},
+		{
+			name:     "budget variable",
+			command:  "notify 'Budget: {{budget}}'",
+			vars:     Variables{Budget: "cost $5.10 exceeds limit $5.00"},
+			expected: "notify 'Budget: cost $5.10 exceeds limit $5.00'",
+		},
 		{
 			name:     "all variables",
 			command:  "{{session}}/{{iteration}}/{{task_id}}/{{task_content}}/{{error}}",
@@ -8,12 +8,14 @@ type Config struct {
 
 // HooksConfig contains all hook configurations.
 type HooksConfig struct {
-	SessionStart   []*HookConfig `yaml:"session_start"`
-	PreIteration   []*HookConfig `yaml:"pre_iteration"`
-	PostIteration  []*HookConfig `yaml:"post_iteration"`
-	SessionEnd     []*HookConfig `yaml:"session_end"`
-	OnTaskComplete []*HookConfig `yaml:"on_task_complete"`
-	OnError        []*HookConfig `yaml:"on_error"`
+	SessionStart     []*HookConfig `yaml:"session_start"`
+	PreIteration     []*HookConfig `yaml:"pre_iteration"`
+	PostIteration    []*HookConfig `yaml:"post_iteration"`
+	SessionEnd       []*HookConfig `yaml:"session_end"`
+	OnTaskComplete   []*HookConfig `yaml:"on_task_complete"`
+	OnError          []*HookConfig `yaml:"on_error"`
+	OnBudgetExceeded []*HookConfig `yaml:"on_budget_exceeded"`
+	Review           []*HookConfig `yaml:"review"` // Review gate in headless mode (see ExecuteReview)
 }
 
 // HookConfig defines a single hook's configuration.
@@ -23,5 +25,15 @@ type HookConfig struct {
 	PipeOutput bool   `yaml:"pipe_output"` // default false
 }
 
+// Review hook exit codes. Reject has a dedicated code so that a failing
+// script (exit 1 from set -e, a missing binary, ...) never reverts an
+// iteration; any code not listed here is a hook error and keeps the changes
+// uncommitted like annotate.
+const (
+	ReviewExitApprove  = 0 // Keep the changes (auto-commit proceeds)
+	ReviewExitAnnotate = 2 // Keep the changes uncommitted and send the output as feedback
+	ReviewExitReject   = 3 // Revert the iteration
+)
+
 // DefaultTimeout is the default timeout for hook execution in seconds.
 const DefaultTimeout = 30
@@ -89,10 +89,28 @@ func (s *Server) handleTaskAdd(ctx context.Context, request mcp.CallToolRequest)
 			priority = int(priorityVal)
 		}
 
+		// Extract optional parent and details
+		parentID, _ := taskMap["parent_id"].(string)
+		description, _ := taskMap["description"].(string)
+		estimate, _ := taskMap["estimate"].(string)
+		criteria, err := stringList(taskMap["acceptance_criteria"])
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: task %d: 'acceptance_criteria' %v", i, err)), nil
+		}
+		labels, err := stringList(taskMap["labels"])
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: task %d: 'labels' %v", i, err)), nil
+		}
+
 		taskParams = append(taskParams, session.TaskAddParams{
-			Content:  content,
-			Status:   status,
-			Priority: priority,
+			Content:            content,
+			Status:             status,
+			Priority:           priority,
+			ParentID:           parentID,
+			Description:        description,
+			AcceptanceCriteria: criteria,
+			Labels:             labels,
+			Estimate:           estimate,
 			// Iteration will be set by store based on current iteration
 		})
 	}
@@ -138,7 +156,7 @@ func (s *Server) handleTaskAdd(ctx context.Context, request mcp.CallToolRequest)
 	return mcp.NewToolResultText(result), nil
 }
 
-// handleTaskUpdate updates a task's status, priority, or dependencies.
+// handleTaskUpdate updates a task's status, priority, dependencies, parent, or details.
 func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract arguments
 	args := request.GetArguments()
@@ -167,6 +185,20 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 	// Track what we updated for the success message
 	updated := []string{}
 
+	// Update parent first so a status change closes the right parent
+	// (empty string makes the task top-level)
+	if parentID, ok := args["parent_id"].(string); ok {
+		err := s.store.TaskParent(ctx, s.sessName, session.TaskParentParams{
+			ID:        id,
+			ParentID:  parentID,
+			Iteration: currentIteration,
+		})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: failed to update parent: %v", err)), nil
+		}
+		updated = append(updated, fmt.Sprintf("parent_id=%s", parentID))
+	}
+
 	// Update status if provided
 	if status, ok := args["status"].(string); ok && status != "" {
 		// Validate in_progress transitions
@@ -223,9 +255,43 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 		updated = append(updated, fmt.Sprintf("depends_on=%s", dependsOn))
 	}
 
+	// Update details if any were provided
+	details := session.TaskDetailsParams{ID: id, Iteration: currentIteration}
+	var detailNames []string
+	if description, ok := args["description"].(string); ok {
+		details.Description = &description
+		detailNames = append(detailNames, "description")
+	}
+	if raw, ok := args["acceptance_criteria"]; ok {
+		criteria, err := stringList(raw)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: 'acceptance_criteria' %v", err)), nil
+		}
+		details.AcceptanceCriteria = append([]string{}, criteria...)
+		detailNames = append(detailNames, "acceptance_criteria")
+	}
+	if raw, ok := args["labels"]; ok {
+		labels, err := stringList(raw)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: 'labels' %v", err)), nil
+		}
+		details.Labels = append([]string{}, labels...)
+		detailNames = append(detailNames, "labels")
+	}
+	if estimate, ok := args["estimate"].(string); ok {
+		details.Estimate = &estimate
+		detailNames = append(detailNames, "estimate")
+	}
+	if len(detailNames) > 0 {
+		if err := s.store.TaskDetails(ctx, s.sessName, details); err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: failed to update details: %v", err)), nil
+		}
+		updated = append(updated, detailNames...)
+	}
+
 	// Check if anything was actually updated
 	if len(updated) == 0 {
-		return mcp.NewToolResultText("error: no valid update parameters provided (status, priority, or depends_on required)"), nil
+		return mcp.NewToolResultText("error: no valid update parameters provided (status, priority, depends_on, parent_id, or a task detail required)"), nil
 	}
 
 	// Return success message
@@ -237,6 +303,27 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 	return mcp.NewToolResultText(result), nil
 }
 
+// stringList converts an optional JSON array argument to a string slice.
+// A missing argument yields nil.
+func stringList(raw any) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("is not an array")
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("must contain only strings")
+		}
+		list = append(list, str)
+	}
+	return list, nil
+}
+
 // handleTaskList returns all tasks grouped by status.
 func (s *Server) handleTaskList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Call TaskList
@@ -288,12 +375,19 @@ func (s *Server) handleTaskNext(ctx context.Context, request mcp.CallToolRequest
 	}
 
 	// Output JSON for parsing (matching CLI format)
-	output, err := json.Marshal(map[string]any{
+	next := map[string]any{
 		"id":       task.ID,
 		"content":  task.Content,
 		"priority": task.Priority,
 		"status":   task.Status,
-	})
+	}
+	if task.Description != "" {
+		next["description"] = task.Description
+	}
+	if len(task.AcceptanceCriteria) > 0 {
+		next["acceptance_criteria"] = task.AcceptanceCriteria
+	}
+	output, err := json.Marshal(next)
 	if err != nil {
 		return mcp.NewToolResultText(fmt.Sprintf("error: failed to marshal task: %v", err)), nil
 	}
@@ -2245,3 +2245,185 @@ func TestHandleTaskUpdate_AllowsNonInProgressUpdates(t *testing.T) {
 		t.Errorf("expected success for non-in_progress update, got: %s", text)
 	}
 }
+
+func TestHandleTaskAdd_WithDetails(t *testing.T) {
+	srv, store, cleanup := setupTestServerWithStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{
+						"content":             "Add login endpoint",
+						"description":         "POST /login returning a session cookie",
+						"acceptance_criteria": []any{"returns 401 on bad password", "has tests"},
+						"labels":              []any{"backend"},
+						"estimate":            "2h",
+					},
+				},
+			},
+		},
+	}
+	result, err := srv.handleTaskAdd(ctx, req)
+	if err != nil {
+		t.Fatalf("handleTaskAdd returned error: %v", err)
+	}
+	if text := extractText(result); !strings.Contains(text, "Added 1 task(s)") {
+		t.Fatalf("unexpected result: %s", text)
+	}
+
+	state, err := store.LoadState(ctx, "test-session")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	task := state.Tasks["TAS-1"]
+	if task.Description != "POST /login returning a session cookie" || task.Estimate != "2h" ||
+		strings.Join(task.AcceptanceCriteria, "|") != "returns 401 on bad password|has tests" ||
+		strings.Join(task.Labels, ",") != "backend" {
+		t.Errorf("task details not stored: %+v", task)
+	}
+
+	// Non-string criteria are rejected
+	badReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{"content": "Other", "acceptance_criteria": []any{float64(1)}},
+				},
+			},
+		},
+	}
+	result, err = srv.handleTaskAdd(ctx, badReq)
+	if err != nil {
+		t.Fatalf("handleTaskAdd returned error: %v", err)
+	}
+	if text := extractText(result); !strings.Contains(text, "error:") || !strings.Contains(text, "acceptance_criteria") {
+		t.Errorf("expected acceptance_criteria error, got: %s", text)
+	}
+}
+
+func TestHandleTaskUpdate_Details(t *testing.T) {
+	srv, store, cleanup := setupTestServerWithStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	addReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{"content": "Test task", "labels": []any{"old"}, "estimate": "1d"},
+				},
+			},
+		},
+	}
+	if _, err := srv.handleTaskAdd(ctx, addReq); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-update",
+			Arguments: map[string]any{
+				"id":                  "TAS-1",
+				"description":         "More context",
+				"acceptance_criteria": []any{"go test passes"},
+				"labels":              []any{},
+			},
+		},
+	}
+	result, err := srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	text := extractText(result)
+	if text != "Updated task TAS-1: description, acceptance_criteria, labels" {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	state, err := store.LoadState(ctx, "test-session")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	task := state.Tasks["TAS-1"]
+	if task.Description != "More context" || len(task.Labels) != 0 || task.Estimate != "1d" ||
+		strings.Join(task.AcceptanceCriteria, "|") != "go test passes" {
+		t.Errorf("task details not updated: %+v", task)
+	}
+
+	// task-next surfaces the criteria so the agent sees them before starting
+	nextResult, err := srv.handleTaskNext(ctx, mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handleTaskNext returned error: %v", err)
+	}
+	if text := extractText(nextResult); !strings.Contains(text, `"acceptance_criteria":["go test passes"]`) {
+		t.Errorf("task-next missing acceptance criteria: %s", text)
+	}
+}
+
+func TestHandleTaskUpdate_ParentAutoCompletes(t *testing.T) {
+	srv, store, cleanup := setupTestServerWithStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	addReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{"content": "Parent"},
+					map[string]any{"content": "Child", "parent_id": "TAS-1"},
+					map[string]any{"content": "Stray"},
+				},
+			},
+		},
+	}
+	if _, err := srv.handleTaskAdd(ctx, addReq); err != nil {
+		t.Fatalf("failed to add tasks: %v", err)
+	}
+
+	// Adopt TAS-3 and complete it in one call, then complete TAS-2
+	for _, update := range []map[string]any{
+		{"id": "TAS-3", "parent_id": "TAS-1", "status": "completed"},
+		{"id": "TAS-2", "status": "completed"},
+	} {
+		result, err := srv.handleTaskUpdate(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "task-update", Arguments: update},
+		})
+		if err != nil {
+			t.Fatalf("handleTaskUpdate returned error: %v", err)
+		}
+		if text := extractText(result); !strings.HasPrefix(text, "Updated task") {
+			t.Fatalf("unexpected result: %s", text)
+		}
+	}
+
+	state, err := store.LoadState(ctx, "test-session")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if state.Tasks["TAS-3"].ParentID != "TAS-1" {
+		t.Errorf("expected TAS-3 under TAS-1, got parent %q", state.Tasks["TAS-3"].ParentID)
+	}
+	if state.Tasks["TAS-1"].Status != "completed" {
+		t.Errorf("expected parent auto-completed, got %s", state.Tasks["TAS-1"].Status)
+	}
+
+	// Cycles are rejected
+	result, err := srv.handleTaskUpdate(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "task-update", Arguments: map[string]any{"id": "TAS-1", "parent_id": "TAS-2"}},
+	})
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	if text := extractText(result); !strings.Contains(text, "error: failed to update parent") {
+		t.Errorf("expected parent error, got: %s", text)
+	}
+}
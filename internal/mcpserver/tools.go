@@ -27,6 +27,28 @@ func (s *Server) registerTools() error {
 							"type":        "integer",
 							"description": "Priority level (0=critical, 1=high, 2=medium, 3=low, 4=backlog)",
 						},
+						"parent_id": map[string]any{
+							"type":        "string",
+							"description": "ID of the task this is a subtask of (may be an earlier task in the same call)",
+						},
+						"description": map[string]any{
+							"type":        "string",
+							"description": "Optional long-form details: context, approach, pointers",
+						},
+						"acceptance_criteria": map[string]any{
+							"type":        "array",
+							"items":       map[string]any{"type": "string"},
+							"description": "Conditions that must hold before the task may be completed",
+						},
+						"labels": map[string]any{
+							"type":        "array",
+							"items":       map[string]any{"type": "string"},
+							"description": "Free-form labels (e.g. backend, docs)",
+						},
+						"estimate": map[string]any{
+							"type":        "string",
+							"description": "Size estimate (e.g. 2h, S/M/L)",
+						},
 					},
 					"required": []string{"content"},
 				})),
@@ -37,11 +59,16 @@ func (s *Server) registerTools() error {
 	// task-update: id required, other fields optional
 	s.mcpServer.AddTool(
 		mcp.NewTool("task-update",
-			mcp.WithDescription("Update task status, priority, or dependencies"),
+			mcp.WithDescription("Update task status, priority, dependencies, parent, or details"),
 			mcp.WithString("id", mcp.Required(), mcp.Description("Task ID or prefix")),
 			mcp.WithString("status", mcp.Description("New status (remaining, in_progress, completed, blocked, cancelled)")),
 			mcp.WithNumber("priority", mcp.Description("New priority (0-4)")),
 			mcp.WithString("depends_on", mcp.Description("Task ID this task depends on")),
+			mcp.WithString("parent_id", mcp.Description("Task ID to make this a subtask of (empty string makes it top-level)")),
+			mcp.WithString("description", mcp.Description("New long-form description (empty string clears it)")),
+			mcp.WithArray("acceptance_criteria", mcp.WithStringItems(), mcp.Description("Replacement list of acceptance criteria (empty array clears it)")),
+			mcp.WithArray("labels", mcp.WithStringItems(), mcp.Description("Replacement list of labels (empty array clears it)")),
+			mcp.WithString("estimate", mcp.Description("New size estimate (empty string clears it)")),
 		),
 		s.handleTaskUpdate,
 	)
@@ -2,6 +2,7 @@ package nats
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,6 +15,12 @@ const (
 	// StreamName is the name of the JetStream stream for iteratr events
 	StreamName = "iteratr_events"
 
+	// SnapshotBucket is the name of the KV bucket holding session state snapshots
+	SnapshotBucket = "iteratr_snapshots"
+
+	// TranscriptBucket is the name of the object store holding iteration transcripts
+	TranscriptBucket = "iteratr_transcripts"
+
 	// Event types
 	EventTypeTask      = "task"
 	EventTypeNote      = "note"
@@ -33,17 +40,58 @@ func SubjectForEvent(session, eventType string) string {
 	return fmt.Sprintf("iteratr.%s.%s", session, eventType)
 }
 
-// SetupStream creates or updates the JetStream stream for iteratr events.
-// The stream captures all events for all sessions with 30-day retention.
+// DefaultMaxAge is the default retention period for stream messages.
+const DefaultMaxAge = 30 * 24 * time.Hour
+
+// Retention configures how long and how much event history the stream keeps.
+// Zero values mean unlimited.
+type Retention struct {
+	MaxAge            time.Duration // Maximum age of any event (0 = keep forever)
+	MaxBytes          int64         // Maximum total stream size in bytes (0 = unlimited)
+	MaxMsgsPerSubject int64         // Maximum events kept per session/type subject (0 = unlimited)
+}
+
+// Size limits (MaxBytes, MaxMsgsPerSubject) make JetStream drop the oldest
+// events of a subject while keeping later ones, so a session that hits them
+// replays without its first events (e.g. the task-add events its later task
+// updates refer to). They are opt-in for disk-constrained setups, and
+// `iteratr doctor` warns while either is in effect.
+
+// DefaultRetention returns the retention used when none is configured (30 days, no size limits).
+func DefaultRetention() Retention {
+	return Retention{MaxAge: DefaultMaxAge}
+}
+
+// SetupStream creates or updates the JetStream stream for iteratr events
+// using the default retention policy.
 // Subject pattern: iteratr.> matches all sessions and event types.
 func SetupStream(ctx context.Context, js jetstream.JetStream) (jetstream.Stream, error) {
-	logger.Debug("Setting up JetStream stream: %s", StreamName)
-	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
-		Name:     StreamName,
-		Subjects: []string{"iteratr.>"}, // Match all iteratr events
-		Storage:  jetstream.FileStorage,
-		MaxAge:   30 * 24 * time.Hour, // 30 day retention
-	})
+	return SetupStreamWithRetention(ctx, js, DefaultRetention())
+}
+
+// SetupStreamWithRetention creates or updates the JetStream stream for iteratr events,
+// applying the given retention limits. Because the stream is shared by all sessions,
+// the most recent call wins when processes disagree on retention.
+func SetupStreamWithRetention(ctx context.Context, js jetstream.JetStream, retention Retention) (jetstream.Stream, error) {
+	logger.Debug("Setting up JetStream stream: %s (max_age=%s max_bytes=%d max_msgs_per_subject=%d)",
+		StreamName, retention.MaxAge, retention.MaxBytes, retention.MaxMsgsPerSubject)
+
+	cfg := jetstream.StreamConfig{
+		Name:              StreamName,
+		Subjects:          []string{"iteratr.>"}, // Match all iteratr events
+		Storage:           jetstream.FileStorage,
+		MaxAge:            retention.MaxAge,
+		MaxBytes:          -1, // Unlimited
+		MaxMsgsPerSubject: -1, // Unlimited
+	}
+	if retention.MaxBytes > 0 {
+		cfg.MaxBytes = retention.MaxBytes
+	}
+	if retention.MaxMsgsPerSubject > 0 {
+		cfg.MaxMsgsPerSubject = retention.MaxMsgsPerSubject
+	}
+
+	stream, err := js.CreateOrUpdateStream(ctx, cfg)
 	if err != nil {
 		logger.Error("Failed to create/update stream: %v", err)
 		return nil, err
@@ -52,6 +100,54 @@ func SetupStream(ctx context.Context, js jetstream.JetStream) (jetstream.Stream,
 	return stream, nil
 }
 
+// OldestEventTime returns the timestamp of the oldest event still stored for a session.
+// Returns the zero time if the session has no events.
+func OldestEventTime(ctx context.Context, stream jetstream.Stream, session string) (time.Time, error) {
+	msg, err := stream.GetMsg(ctx, 1, jetstream.WithGetMsgSubject(SubjectForSession(session)))
+	if err != nil {
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return msg.Time, nil
+}
+
+// SetupSnapshotBucket creates or opens the KV bucket for session state snapshots.
+// Only the latest snapshot per session is kept.
+func SetupSnapshotBucket(ctx context.Context, js jetstream.JetStream) (jetstream.KeyValue, error) {
+	logger.Debug("Setting up snapshot bucket: %s", SnapshotBucket)
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      SnapshotBucket,
+		Description: "Session state snapshots",
+		History:     1,
+		Storage:     jetstream.FileStorage,
+	})
+	if err != nil {
+		logger.Error("Failed to create/update snapshot bucket: %v", err)
+		return nil, err
+	}
+	return kv, nil
+}
+
+// SetupTranscriptBucket creates or opens the object store for iteration
+// transcripts. Transcripts expire with the event stream's max age, so they
+// are kept exactly as long as the events of their session.
+func SetupTranscriptBucket(ctx context.Context, js jetstream.JetStream, retention Retention) (jetstream.ObjectStore, error) {
+	logger.Debug("Setting up transcript bucket: %s (ttl=%s)", TranscriptBucket, retention.MaxAge)
+	obs, err := js.CreateOrUpdateObjectStore(ctx, jetstream.ObjectStoreConfig{
+		Bucket:      TranscriptBucket,
+		Description: "Agent transcripts per session iteration",
+		TTL:         retention.MaxAge,
+		Storage:     jetstream.FileStorage,
+	})
+	if err != nil {
+		logger.Error("Failed to create/update transcript bucket: %v", err)
+		return nil, err
+	}
+	return obs, nil
+}
+
 // CreateConsumer creates a durable consumer for reading event history.
 // The consumer starts from the beginning and requires explicit acknowledgment.
 func CreateConsumer(ctx context.Context, stream jetstream.Stream, name string) (jetstream.Consumer, error) {
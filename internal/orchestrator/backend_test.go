@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+)
+
+// fakeBackend is an in-memory agent.Backend that records the prompts it receives
+// and finishes every turn immediately.
+type fakeBackend struct {
+	cfg agent.BackendConfig
+
+	mu       sync.Mutex
+	started  bool
+	stopped  bool
+	prompts  []string
+	messages []string
+}
+
+func (f *fakeBackend) Start(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = true
+	return nil
+}
+
+func (f *fakeBackend) RunIteration(ctx context.Context, prompt string, hookOutput string) error {
+	f.mu.Lock()
+	f.prompts = append(f.prompts, prompt)
+	f.mu.Unlock()
+	if f.cfg.OnText != nil {
+		f.cfg.OnText("working\n")
+	}
+	if f.cfg.OnFinish != nil {
+		f.cfg.OnFinish(agent.FinishEvent{StopReason: "end_turn", Model: f.cfg.Model})
+	}
+	return nil
+}
+
+func (f *fakeBackend) SendMessages(ctx context.Context, texts []string) error {
+	f.mu.Lock()
+	f.messages = append(f.messages, texts...)
+	f.mu.Unlock()
+	if f.cfg.OnFinish != nil {
+		f.cfg.OnFinish(agent.FinishEvent{StopReason: "end_turn", Model: f.cfg.Model})
+	}
+	return nil
+}
+
+func (f *fakeBackend) Stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = true
+}
+
+// TestPluggableBackend verifies that the iteration loop drives whichever
+// backend is selected in the config.
+func TestPluggableBackend(t *testing.T) {
+	var fake *fakeBackend
+	agent.RegisterBackend("test-fake", func(cfg agent.BackendConfig) (agent.Backend, error) {
+		fake = &fakeBackend{cfg: cfg}
+		return fake, nil
+	})
+
+	t.Run("unknown backend is rejected", func(t *testing.T) {
+		_, err := New(Config{SessionName: "test", Backend: "no-such-backend", WorkDir: t.TempDir()})
+		if err == nil {
+			t.Fatal("expected error for unknown backend")
+		}
+	})
+
+	t.Run("iteration loop uses selected backend", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		specPath := filepath.Join(tmpDir, "spec.md")
+		if err := os.WriteFile(specPath, []byte("# Spec\nFake backend test.\n"), 0644); err != nil {
+			t.Fatalf("failed to write spec file: %v", err)
+		}
+
+		orch, err := New(Config{
+			SessionName: "test-backend",
+			SpecPath:    specPath,
+			Iterations:  2,
+			DataDir:     filepath.Join(tmpDir, ".iteratr"),
+			WorkDir:     tmpDir,
+			Headless:    true,
+			Model:       "fake/model",
+			Backend:     "test-fake",
+		})
+		if err != nil {
+			t.Fatalf("failed to create orchestrator: %v", err)
+		}
+		if err := orch.Start(); err != nil {
+			t.Fatalf("failed to start orchestrator: %v", err)
+		}
+		defer func() { _ = orch.Stop() }()
+
+		if err := orch.Run(); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+
+		if fake == nil {
+			t.Fatal("fake backend was never created")
+		}
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		if !fake.started || !fake.stopped {
+			t.Errorf("expected backend started and stopped, got started=%v stopped=%v", fake.started, fake.stopped)
+		}
+		// Iteration #0 (planning) plus two regular iterations
+		if len(fake.prompts) != 3 {
+			t.Errorf("expected 3 iteration prompts, got %d", len(fake.prompts))
+		}
+		if fake.cfg.MCPServerURL == "" {
+			t.Error("expected backend config to carry the MCP server URL")
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// Budget actions taken when a limit is crossed.
+const (
+	BudgetActionStop  = "stop"  // End the iteration loop (session_end hooks still run)
+	BudgetActionPause = "pause" // Pause until resumed from the TUI
+)
+
+// Budget limits the resources a session may use. Zero values mean unlimited.
+// Limits are checked after each iteration, so the iteration that crosses
+// a limit always runs to completion.
+type Budget struct {
+	MaxTokens   int64         // Session total of input + output tokens
+	MaxCost     float64       // Session estimated cost in USD (requires prices)
+	MaxDuration time.Duration // Wall-clock time of this build run
+	Action      string        // BudgetActionStop (default) or BudgetActionPause
+}
+
+// budgetExceeded describes a crossed budget limit.
+type budgetExceeded struct {
+	Limit string // "tokens", "cost", or "duration"
+	Value string // Current usage
+	Max   string // Configured limit
+}
+
+// String returns a human-readable explanation, e.g. "tokens 1.2M exceeds limit 1M".
+func (b budgetExceeded) String() string {
+	return fmt.Sprintf("%s %s exceeds limit %s", b.Limit, b.Value, b.Max)
+}
+
+// exceeded returns the limits crossed by the given session usage and run time.
+func (b Budget) exceeded(usage session.Usage, elapsed time.Duration) []budgetExceeded {
+	var result []budgetExceeded
+	if b.MaxTokens > 0 && usage.TotalTokens() >= b.MaxTokens {
+		result = append(result, budgetExceeded{
+			Limit: "tokens",
+			Value: session.FormatTokens(usage.TotalTokens()),
+			Max:   session.FormatTokens(b.MaxTokens),
+		})
+	}
+	if b.MaxCost > 0 && usage.Cost >= b.MaxCost {
+		result = append(result, budgetExceeded{
+			Limit: "cost",
+			Value: fmt.Sprintf("$%.2f", usage.Cost),
+			Max:   fmt.Sprintf("$%.2f", b.MaxCost),
+		})
+	}
+	if b.MaxDuration > 0 && elapsed >= b.MaxDuration {
+		result = append(result, budgetExceeded{
+			Limit: "duration",
+			Value: elapsed.Round(time.Second).String(),
+			Max:   b.MaxDuration.String(),
+		})
+	}
+	return result
+}
+
+// stopAfterFailedIteration enforces the budget after an iteration that failed
+// without ending the session (on_error hooks or a timeout), so a provider that
+// keeps failing cannot run up usage past the limits. Usage of the failed turns
+// is already recorded by then. It also honors a pause, as the loop does after
+// a successful iteration. Returns true if the loop should stop.
+func (o *Orchestrator) stopAfterFailedIteration(iteration int) bool {
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		logger.Warn("Failed to load session state for budget check: %v", err)
+	} else if o.checkBudget(state, iteration) {
+		return true
+	}
+	if err := o.waitIfPaused(); err != nil {
+		logger.Info("Context cancelled during pause, stopping iteration loop")
+		return true
+	}
+	return false
+}
+
+// checkBudget compares session usage and run time against the budget after an
+// iteration. For each newly crossed limit it records a control event and runs
+// on_budget_exceeded hooks. Returns true if the loop should stop; pausing is
+// requested through RequestPause so the caller's waitIfPaused blocks.
+// A limit that already paused the session is not enforced again after resume.
+func (o *Orchestrator) checkBudget(state *session.State, iteration int) bool {
+	crossed := o.cfg.Budget.exceeded(state.Usage, time.Since(o.runStartedAt))
+
+	var fresh []budgetExceeded
+	for _, b := range crossed {
+		if !o.budgetTripped[b.Limit] {
+			o.budgetTripped[b.Limit] = true
+			fresh = append(fresh, b)
+		}
+	}
+	if len(fresh) == 0 {
+		return false
+	}
+
+	action := o.cfg.Budget.Action
+	if action == "" {
+		action = BudgetActionStop
+	}
+	if action == BudgetActionPause && o.cfg.Headless {
+		// Nothing can resume a headless run
+		logger.Warn("Budget action 'pause' is not available in headless mode, stopping instead")
+		action = BudgetActionStop
+	}
+
+	for _, b := range fresh {
+		reason := b.String()
+		logger.Warn("Budget exceeded after iteration #%d: %s (action: %s)", iteration, reason, action)
+		if err := o.store.BudgetExceeded(o.ctx, o.cfg.SessionName, b.Limit, reason, action); err != nil {
+			logger.Warn("Failed to record budget exceeded event: %v", err)
+		}
+
+		if o.cfg.Headless {
+			fmt.Fprintf(o.out, "Budget exceeded: %s (%s)\n", reason, action)
+		}
+		if o.tuiProgram != nil {
+			o.tuiProgram.Send(tui.ShowToastMsg{Text: "Budget exceeded: " + reason})
+		}
+
+		if o.hooksConfig != nil && len(o.hooksConfig.Hooks.OnBudgetExceeded) > 0 {
+			logger.Info("Executing on_budget_exceeded hooks")
+			hookVars := hooks.Variables{
+				Session:   o.cfg.SessionName,
+				Iteration: strconv.Itoa(iteration),
+				Budget:    reason,
+			}
+			onStart, onComplete, _ := o.hookCallbacks("on_budget_exceeded")
+			if _, err := hooks.ExecuteAllWithCallbacks(o.ctx, o.hooksConfig.Hooks.OnBudgetExceeded, o.cfg.WorkDir, hookVars, onStart, onComplete); err != nil {
+				if o.ctx.Err() != nil {
+					return true
+				}
+				logger.Error("on_budget_exceeded hook execution failed: %v", err)
+			}
+		}
+	}
+
+	if action == BudgetActionPause {
+		o.RequestPause()
+		return false
+	}
+	return true
+}
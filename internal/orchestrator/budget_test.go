@@ -0,0 +1,232 @@
+package orchestrator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestBudgetExceeded(t *testing.T) {
+	usage := session.Usage{InputTokens: 900000, OutputTokens: 200000, Cost: 5.25}
+
+	tests := []struct {
+		name    string
+		budget  Budget
+		elapsed time.Duration
+		want    []string
+	}{
+		{name: "unlimited", budget: Budget{}, elapsed: 24 * time.Hour},
+		{name: "under all limits", budget: Budget{MaxTokens: 2000000, MaxCost: 10, MaxDuration: time.Hour}, elapsed: time.Minute},
+		{name: "tokens", budget: Budget{MaxTokens: 1000000}, want: []string{"tokens 1.1M exceeds limit 1M"}},
+		{name: "cost", budget: Budget{MaxCost: 5}, want: []string{"cost $5.25 exceeds limit $5.00"}},
+		{name: "duration", budget: Budget{MaxDuration: time.Hour}, elapsed: 90 * time.Minute, want: []string{"duration 1h30m0s exceeds limit 1h0m0s"}},
+		{
+			name:    "several limits",
+			budget:  Budget{MaxTokens: 1000000, MaxCost: 5, MaxDuration: time.Hour},
+			elapsed: 2 * time.Hour,
+			want:    []string{"tokens 1.1M exceeds limit 1M", "cost $5.25 exceeds limit $5.00", "duration 2h0m0s exceeds limit 1h0m0s"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, b := range tt.budget.exceeded(usage, tt.elapsed) {
+				got = append(got, b.String())
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+				t.Errorf("exceeded() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBudgetStopsLoop runs the replay backend with a token budget that the
+// first working iteration exceeds. The loop must stop before the next
+// iteration, record a budget_exceeded event, and run on_budget_exceeded hooks.
+func TestBudgetStopsLoop(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, ".iteratr")
+
+	specPath := filepath.Join(tmpDir, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] One\n- [ ] Two\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+
+	hooksYAML := `
+version: 1
+hooks:
+  on_budget_exceeded:
+    - command: "echo '{{iteration}}: {{budget}}' > budget.txt"
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".iteratr.hooks.yml"), []byte(hooksYAML), 0644); err != nil {
+		t.Fatalf("failed to write hooks file: %v", err)
+	}
+
+	scriptPath := filepath.Join(tmpDir, "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}, {content: Two}]}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - finish: {input_tokens: 600000, output_tokens: 50000}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-2, status: completed}
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "test-budget",
+		SpecPath:     specPath,
+		Iterations:   5,
+		DataDir:      dataDir,
+		WorkDir:      tmpDir,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+		Budget:       Budget{MaxTokens: 500000},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	state, err := orch.store.LoadState(orch.ctx, "test-budget")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if len(state.Iterations) != 2 {
+		t.Fatalf("expected 2 iterations (planning + 1) before the budget stopped the loop, got %d", len(state.Iterations))
+	}
+	if task := state.Tasks["TAS-2"]; task == nil || task.Status != "remaining" {
+		t.Errorf("TAS-2 = %+v, want remaining", task)
+	}
+
+	events, err := orch.store.Events(orch.ctx, "test-budget")
+	if err != nil {
+		t.Fatalf("failed to load events: %v", err)
+	}
+	var recorded []string
+	for _, e := range events {
+		if e.Action == "budget_exceeded" {
+			recorded = append(recorded, e.Data)
+		}
+	}
+	if len(recorded) != 1 || !strings.Contains(recorded[0], "tokens 650k exceeds limit 500k") {
+		t.Errorf("budget_exceeded events = %q, want one tokens event", recorded)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "budget.txt"))
+	if err != nil {
+		t.Fatalf("on_budget_exceeded hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "1: tokens 650k exceeds limit 500k" {
+		t.Errorf("hook output = %q", got)
+	}
+}
+
+func TestBudgetStopsFailingLoop(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	specPath := filepath.Join(tmpDir, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] One\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+
+	// on_error hooks keep the session going after every failure
+	hooksYAML := `
+version: 1
+hooks:
+  on_error:
+    - command: "echo '{{iteration}}' >> errors.txt"
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".iteratr.hooks.yml"), []byte(hooksYAML), 0644); err != nil {
+		t.Fatalf("failed to write hooks file: %v", err)
+	}
+
+	scriptPath := filepath.Join(tmpDir, "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - finish: {error: "provider exploded", input_tokens: 200000}
+  - steps:
+      - finish: {error: "provider exploded", input_tokens: 200000}
+  - steps:
+      - finish: {error: "provider exploded", input_tokens: 200000}
+  - steps:
+      - finish: {error: "provider exploded", input_tokens: 200000}
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "test-budget-fail",
+		SpecPath:     specPath,
+		Iterations:   10,
+		DataDir:      filepath.Join(tmpDir, ".iteratr"),
+		WorkDir:      tmpDir,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+		Budget:       Budget{MaxTokens: 500000},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	orch.out = io.Discard
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	// Three failed iterations cross the 500k token limit
+	data, err := os.ReadFile(filepath.Join(tmpDir, "errors.txt"))
+	if err != nil {
+		t.Fatalf("on_error hook did not run: %v", err)
+	}
+	if got := strings.Fields(string(data)); strings.Join(got, ",") != "1,2,3" {
+		t.Errorf("failed iterations = %v, want 1,2,3 each counted once", got)
+	}
+
+	events, err := orch.store.Events(orch.ctx, "test-budget-fail")
+	if err != nil {
+		t.Fatalf("failed to load events: %v", err)
+	}
+	exceeded := 0
+	for _, e := range events {
+		if e.Action == "budget_exceeded" {
+			exceeded++
+		}
+	}
+	if exceeded != 1 {
+		t.Errorf("budget_exceeded events = %d, want 1", exceeded)
+	}
+}
@@ -0,0 +1,289 @@
+package orchestrator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// Auto-commit modes.
+const (
+	CommitModeAgent     = "agent"      // Ask the agent to stage and commit the files (default)
+	CommitModeNative    = "native"     // iteratr commits the files with a generated message
+	CommitModeNativeLLM = "native-llm" // iteratr commits the files, the agent only writes the message
+)
+
+// maxSubjectLength is the longest commit subject line iteratr generates, in characters.
+const maxSubjectLength = 72
+
+// captureText wraps the OnText callback in cfg so the agent's reply can be
+// collected while it writes a commit message.
+func (o *Orchestrator) captureText(cfg *agent.BackendConfig) {
+	onText := cfg.OnText
+	cfg.OnText = func(text string) {
+		o.captureMu.Lock()
+		if o.capture != nil {
+			o.capture.WriteString(text)
+		}
+		o.captureMu.Unlock()
+		if onText != nil {
+			onText(text)
+		}
+	}
+}
+
+// runNativeCommit stages exactly the files tracked for this iteration and
+// commits them itself, with trailers linking the commit to the session,
+// iteration, and task. In native-llm mode the agent is asked for the message
+// text only; its reply is discarded in favor of the generated message if it
+// is empty or the request fails.
+func (o *Orchestrator) runNativeCommit(ctx context.Context) error {
+	iteration := int(o.iteration.Load())
+	paths := o.fileTracker.ModifiedPaths()
+	if o.cfg.CommitDataDir {
+		if rel, err := filepath.Rel(o.cfg.WorkDir, o.cfg.DataDir); err == nil {
+			paths = append(paths, rel)
+		} else {
+			paths = append(paths, o.cfg.DataDir)
+		}
+	}
+
+	state, err := o.store.LoadState(ctx, o.cfg.SessionName)
+	if err != nil {
+		logger.Warn("Failed to load session state for commit message: %v", err)
+	}
+	task := commitTask(state, iteration)
+	summary := iterationSummary(state, iteration)
+
+	message := generateCommitMessage(task, summary, iteration, paths)
+	if o.cfg.CommitMode == CommitModeNativeLLM {
+		if text, err := o.askCommitMessage(ctx, paths, task, summary); err != nil {
+			logger.Warn("Failed to get commit message from agent, using generated message: %v", err)
+		} else if text != "" {
+			message = text
+		}
+	}
+	message += "\n\n" + commitTrailers(o.cfg.SessionName, iteration, task)
+
+	hash, err := git.CommitPaths(o.cfg.WorkDir, paths, message)
+	if err != nil {
+		return err
+	}
+	if hash == "" {
+		logger.Info("Auto-commit: no changes to commit")
+		return nil
+	}
+	logger.Info("Auto-commit created %s for %d file(s)", shortHash(hash), len(paths))
+	if o.cfg.Headless {
+		subject, _, _ := strings.Cut(message, "\n")
+		fmt.Fprintf(o.out, "Committed %s %s\n", shortHash(hash), subject)
+	}
+	return nil
+}
+
+// askCommitMessage asks the agent to write a commit message and returns its
+// cleaned-up reply.
+func (o *Orchestrator) askCommitMessage(ctx context.Context, paths []string, task *session.Task, summary string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("Write a commit message for the following modified files:\n\n")
+	for _, p := range paths {
+		fmt.Fprintf(&sb, "- %s\n", p)
+	}
+	if task != nil || summary != "" {
+		sb.WriteString("\nContext:\n")
+		if task != nil {
+			fmt.Fprintf(&sb, "- Task: %s\n", task.Content)
+		}
+		if summary != "" {
+			fmt.Fprintf(&sb, "- Summary: %s\n", summary)
+		}
+	}
+	sb.WriteString("\nInstructions:\n")
+	fmt.Fprintf(&sb, "1. Reply with the commit message only: a conventional commit subject line of at most %d characters, optionally followed by a blank line and a short body\n", maxSubjectLength)
+	sb.WriteString("2. Do NOT run git or any other tool; iteratr stages and commits the files itself\n")
+
+	var reply strings.Builder
+	o.captureMu.Lock()
+	o.capture = &reply
+	o.captureMu.Unlock()
+	defer func() {
+		o.captureMu.Lock()
+		o.capture = nil
+		o.captureMu.Unlock()
+	}()
+
+	if err := o.runner.SendMessages(ctx, []string{sb.String()}); err != nil {
+		return "", err
+	}
+	o.captureMu.Lock()
+	text := reply.String()
+	o.captureMu.Unlock()
+	return cleanCommitMessage(text), nil
+}
+
+// cleanCommitMessage strips code fences, trailers, and surrounding blank
+// lines from an agent-written commit message.
+func cleanCommitMessage(text string) string {
+	var lines []string
+	for line := range strings.SplitSeq(strings.TrimSpace(text), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "Iteratr-") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// generateCommitMessage builds a conventional commit message from the task
+// and iteration summary, e.g. "feat: add login form" followed by the summary.
+func generateCommitMessage(task *session.Task, summary string, iteration int, paths []string) string {
+	description := fmt.Sprintf("iteration #%d changes", iteration)
+	switch {
+	case task != nil:
+		description = task.Content
+	case summary != "":
+		description = summary
+	}
+	description, _, _ = strings.Cut(strings.TrimSpace(description), "\n")
+	description = strings.TrimSuffix(description, ".")
+	if r, size := utf8.DecodeRuneInString(description); r != utf8.RuneError {
+		description = string(unicode.ToLower(r)) + description[size:]
+	}
+
+	subject := commitType(task, paths) + ": " + description
+	if utf8.RuneCountInString(subject) > maxSubjectLength {
+		// Cut at a rune boundary so multi-byte characters stay intact
+		runes := []rune(subject)
+		subject = strings.TrimSpace(string(runes[:maxSubjectLength-3])) + "..."
+	}
+	if summary == "" || task == nil {
+		return subject
+	}
+	return subject + "\n\n" + summary
+}
+
+// commitType picks the conventional commit type: docs or test when only
+// documentation or test files changed, fix for tasks that start with "fix",
+// feat otherwise.
+func commitType(task *session.Task, paths []string) string {
+	if len(paths) > 0 && !slices.ContainsFunc(paths, func(p string) bool { return !isDocPath(p) }) {
+		return "docs"
+	}
+	if len(paths) > 0 && !slices.ContainsFunc(paths, func(p string) bool { return !isTestPath(p) }) {
+		return "test"
+	}
+	if task != nil && strings.HasPrefix(strings.ToLower(task.Content), "fix") {
+		return "fix"
+	}
+	return "feat"
+}
+
+// isDocPath reports whether p is a documentation file.
+func isDocPath(p string) bool {
+	p = filepath.ToSlash(p)
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".mdx", ".rst", ".txt", ".adoc":
+		return true
+	}
+	return strings.HasPrefix(p, "docs/")
+}
+
+// isTestPath reports whether p is a test file.
+func isTestPath(p string) bool {
+	base := path.Base(filepath.ToSlash(p))
+	return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") ||
+		strings.Contains(base, ".spec.") || strings.HasPrefix(base, "test_")
+}
+
+// commitTrailers returns the git trailers linking a commit to its session,
+// iteration, and (if known) task.
+func commitTrailers(sessionName string, iteration int, task *session.Task) string {
+	trailers := fmt.Sprintf("Iteratr-Session: %s\nIteratr-Iteration: %d", sessionName, iteration)
+	if task != nil {
+		trailers += "\nIteratr-Task: " + task.ID
+	}
+	return trailers
+}
+
+// commitTask returns the task an iteration worked on: the in-progress task
+// it touched, or else the task it last touched, preferring completed ones.
+// A task left in progress by an earlier iteration is only used when this
+// iteration touched no task. Ties go to the lowest task number.
+func commitTask(state *session.State, iteration int) *session.Task {
+	if state == nil {
+		return nil
+	}
+	ids := slices.SortedFunc(maps.Keys(state.Tasks), compareTaskIDs)
+	var best, stale *session.Task
+	for _, id := range ids {
+		task := state.Tasks[id]
+		if task.Iteration != iteration {
+			if task.Status == "in_progress" && stale == nil {
+				stale = task
+			}
+			continue
+		}
+		if task.Status == "in_progress" {
+			return task
+		}
+		if best == nil || (task.Status == "completed" && best.Status != "completed") ||
+			(task.Status == best.Status && task.UpdatedAt.After(best.UpdatedAt)) {
+			best = task
+		}
+	}
+	if best == nil {
+		return stale
+	}
+	return best
+}
+
+// compareTaskIDs orders task IDs by number, so TAS-2 sorts before TAS-10.
+// IDs without a number sort after numbered ones, by text.
+func compareTaskIDs(a, b string) int {
+	na, errA := strconv.Atoi(strings.TrimPrefix(a, "TAS-"))
+	nb, errB := strconv.Atoi(strings.TrimPrefix(b, "TAS-"))
+	switch {
+	case errA == nil && errB == nil && na != nb:
+		return cmp.Compare(na, nb)
+	case errA == nil && errB != nil:
+		return -1
+	case errA != nil && errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// iterationSummary returns the summary recorded for an iteration, if any.
+func iterationSummary(state *session.State, iteration int) string {
+	if state == nil {
+		return ""
+	}
+	for _, iter := range state.Iterations {
+		if iter.Number == iteration {
+			return iter.Summary
+		}
+	}
+	return ""
+}
+
+// shortHash abbreviates a commit hash for display.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,253 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// runCommitSession runs a replay session with auto-commit in the given mode
+// in a fresh git repository and returns the repository path.
+func runCommitSession(t *testing.T, mode, script string) string {
+	t.Helper()
+	repo := t.TempDir()
+	runGitT(t, repo, "init", "-q", "-b", "main")
+	runGitT(t, repo, "config", "user.email", "test@example.com")
+	runGitT(t, repo, "config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(repo, ".gitignore"), []byte(".iteratr/\n"), 0644); err != nil {
+		t.Fatalf("failed to write .gitignore: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "user.txt"), []byte("v1\n"), 0644); err != nil {
+		t.Fatalf("failed to write user.txt: %v", err)
+	}
+	runGitT(t, repo, "add", "-A")
+	runGitT(t, repo, "commit", "-q", "-m", "initial")
+	// Unrelated work in progress that must stay out of the commit
+	if err := os.WriteFile(filepath.Join(repo, "user.txt"), []byte("v2\n"), 0644); err != nil {
+		t.Fatalf("failed to write user.txt: %v", err)
+	}
+
+	specPath := filepath.Join(t.TempDir(), "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] Login\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	scriptPath := filepath.Join(t.TempDir(), "replay.yml")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "commit",
+		SpecPath:     specPath,
+		Iterations:   1,
+		DataDir:      filepath.Join(repo, ".iteratr"),
+		WorkDir:      repo,
+		Headless:     true,
+		AutoCommit:   true,
+		CommitMode:   mode,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+	return repo
+}
+
+const commitScript = `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: Add login form, status: in_progress}]}
+      - edit: {path: login.go, content: "package main\n"}
+      - edit: {path: docs/login.md, content: "# Login\n"}
+      - tool: iteration-summary
+        input: {summary: "Added the login form"}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`
+
+func TestNativeAutoCommit(t *testing.T) {
+	repo := runCommitSession(t, CommitModeNative, commitScript)
+
+	msg := runGitT(t, repo, "log", "-1", "--format=%B")
+	subject, _, _ := strings.Cut(msg, "\n")
+	if subject != "feat: add login form" {
+		t.Errorf("subject = %q, want generated conventional subject", subject)
+	}
+	for _, want := range []string{"Added the login form", "Iteratr-Session: commit", "Iteratr-Iteration: ", "Iteratr-Task: TAS-1"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("commit message missing %q:\n%s", want, msg)
+		}
+	}
+
+	files := runGitT(t, repo, "show", "--name-only", "--format=", "HEAD")
+	if files != "docs/login.md\nlogin.go" {
+		t.Errorf("committed files = %q, want exactly the edited files", files)
+	}
+	if diff := runGitT(t, repo, "diff", "--name-only"); diff != "user.txt" {
+		t.Errorf("unstaged changes = %q, want user.txt left uncommitted", diff)
+	}
+}
+
+func TestNativeLLMAutoCommit(t *testing.T) {
+	script := commitScript + `
+messages:
+  - steps:
+      - text: "` + "```" + `\nfeat(auth): add login form with validation\n` + "```" + `"
+`
+	repo := runCommitSession(t, CommitModeNativeLLM, script)
+
+	msg := runGitT(t, repo, "log", "-1", "--format=%B")
+	subject, _, _ := strings.Cut(msg, "\n")
+	if subject != "feat(auth): add login form with validation" {
+		t.Errorf("subject = %q, want the agent's message", subject)
+	}
+	if !strings.Contains(msg, "Iteratr-Task: TAS-1") {
+		t.Errorf("commit message missing trailers:\n%s", msg)
+	}
+	if files := runGitT(t, repo, "show", "--name-only", "--format=", "HEAD"); files != "docs/login.md\nlogin.go" {
+		t.Errorf("committed files = %q, want exactly the edited files", files)
+	}
+}
+
+func TestGenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		task    *session.Task
+		summary string
+		paths   []string
+		want    string
+	}{
+		{
+			name:  "task only",
+			task:  &session.Task{Content: "Add login form."},
+			paths: []string{"login.go"},
+			want:  "feat: add login form",
+		},
+		{
+			name:    "task and summary",
+			task:    &session.Task{Content: "Fix crash on empty input"},
+			summary: "Guarded against nil input",
+			paths:   []string{"parse.go", "parse_test.go"},
+			want:    "fix: fix crash on empty input\n\nGuarded against nil input",
+		},
+		{
+			name:    "summary only",
+			summary: "Documented the CLI",
+			paths:   []string{"README.md", "docs/cli.md"},
+			want:    "docs: documented the CLI",
+		},
+		{
+			name:  "tests only",
+			paths: []string{"parse_test.go"},
+			want:  "test: iteration #3 changes",
+		},
+		{
+			name:  "long subject",
+			task:  &session.Task{Content: strings.Repeat("word ", 30)},
+			paths: []string{"main.go"},
+			want:  "feat: " + strings.TrimSpace(strings.Repeat("word ", 30)[:maxSubjectLength-9]) + "...",
+		},
+		{
+			name:  "non-ASCII title",
+			task:  &session.Task{Content: "Écrire la page d'accueil"},
+			paths: []string{"index.html"},
+			want:  "feat: écrire la page d'accueil",
+		},
+		{
+			name:  "long non-ASCII subject",
+			task:  &session.Task{Content: strings.Repeat("Ünïcödé ", 12)},
+			paths: []string{"main.go"},
+			want:  "feat: ünïcödé" + strings.Repeat(" Ünïcödé", 7) + "...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generateCommitMessage(tt.task, tt.summary, 3, tt.paths)
+			if got != tt.want {
+				t.Errorf("generateCommitMessage() = %q, want %q", got, tt.want)
+			}
+			subject, _, _ := strings.Cut(got, "\n")
+			if n := utf8.RuneCountInString(subject); n > maxSubjectLength {
+				t.Errorf("subject is %d characters, want at most %d", n, maxSubjectLength)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("generateCommitMessage() = %q, not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestCommitTask(t *testing.T) {
+	now := time.Now()
+	task := func(id, status string, iteration int, age time.Duration) *session.Task {
+		return &session.Task{ID: id, Status: status, Iteration: iteration, UpdatedAt: now.Add(-age)}
+	}
+	tests := []struct {
+		name  string
+		tasks []*session.Task
+		want  string
+	}{
+		{
+			name:  "in-progress task touched this iteration",
+			tasks: []*session.Task{task("TAS-1", "completed", 3, 0), task("TAS-2", "in_progress", 3, time.Minute)},
+			want:  "TAS-2",
+		},
+		{
+			name:  "stale in-progress task loses to a task completed this iteration",
+			tasks: []*session.Task{task("TAS-1", "in_progress", 2, 0), task("TAS-5", "completed", 3, time.Minute)},
+			want:  "TAS-5",
+		},
+		{
+			name:  "stale in-progress task when nothing was touched",
+			tasks: []*session.Task{task("TAS-1", "completed", 1, 0), task("TAS-4", "in_progress", 2, 0)},
+			want:  "TAS-4",
+		},
+		{
+			name:  "numeric order on ties",
+			tasks: []*session.Task{task("TAS-10", "in_progress", 3, 0), task("TAS-2", "in_progress", 3, 0)},
+			want:  "TAS-2",
+		},
+		{
+			name:  "latest completed task",
+			tasks: []*session.Task{task("TAS-2", "completed", 3, time.Minute), task("TAS-10", "completed", 3, 0), task("TAS-3", "remaining", 3, 0)},
+			want:  "TAS-10",
+		},
+		{
+			name:  "no task touched",
+			tasks: []*session.Task{task("TAS-1", "completed", 1, 0)},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &session.State{Tasks: make(map[string]*session.Task)}
+			for _, task := range tt.tasks {
+				state.Tasks[task.ID] = task
+			}
+			var got string
+			if task := commitTask(state, 3); task != nil {
+				got = task.ID
+			}
+			if got != tt.want {
+				t.Errorf("commitTask() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
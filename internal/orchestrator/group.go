@@ -0,0 +1,278 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/term"
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// Group runs several sessions concurrently in one process. The orchestrators
+// share one NATS server (the first to start owns it, the rest connect to it
+// as nodes) and, unless headless, one TUI with a session switcher. Each keeps
+// its own MCP server, worktree, and session.
+type Group struct {
+	orchs      []*Orchestrator
+	writers    []*prefixWriter    // Session-prefixed headless output, flushed on Stop
+	headless   bool               // All sessions run without TUI
+	ctx        context.Context    // Context for the shared TUI
+	cancel     context.CancelFunc // Cancel function
+	tuiProgram *tea.Program       // Shared Bubbletea program (nil if headless)
+	tuiDone    chan struct{}      // TUI completion signal
+	tuiInput   io.Reader          // Bubbletea input source (set in tests to avoid stdin races)
+	stopped    bool               // Track if Stop() was already called
+}
+
+// NewGroup creates orchestrators for cfgs, which must have distinct session
+// names and agree on headless mode.
+func NewGroup(cfgs []Config) (*Group, error) {
+	if len(cfgs) == 0 {
+		return nil, errors.New("no sessions to run")
+	}
+
+	headless := cfgs[0].Headless
+	width := 0
+	seen := make(map[string]bool, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg.Headless != headless {
+			return nil, errors.New("sessions in a group must all be headless or all use the TUI")
+		}
+		if seen[cfg.SessionName] {
+			return nil, fmt.Errorf("duplicate session name %q", cfg.SessionName)
+		}
+		seen[cfg.SessionName] = true
+		width = max(width, len(cfg.SessionName))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &Group{
+		headless: headless,
+		ctx:      ctx,
+		cancel:   cancel,
+		tuiDone:  make(chan struct{}),
+	}
+	var outMu sync.Mutex
+	for _, cfg := range cfgs {
+		o, err := New(cfg)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("session %s: %w", cfg.SessionName, err)
+		}
+		if headless {
+			w := newPrefixWriter(os.Stdout, fmt.Sprintf("[%-*s] ", width, cfg.SessionName), &outMu)
+			g.writers = append(g.writers, w)
+			o.out = w
+		} else {
+			o.sharedTUI = true
+		}
+		g.orchs = append(g.orchs, o)
+	}
+	return g, nil
+}
+
+// Start starts every orchestrator in order, then the shared TUI.
+// If any session fails to start, the ones already started are stopped.
+func (g *Group) Start() error {
+	logger.Info("Starting %d sessions", len(g.orchs))
+	for _, o := range g.orchs {
+		if err := o.Start(); err != nil {
+			if stopErr := g.Stop(); stopErr != nil {
+				logger.Warn("Failed to stop sessions after start error: %v", stopErr)
+			}
+			return fmt.Errorf("session %s: %w", o.cfg.SessionName, err)
+		}
+	}
+
+	if !g.headless {
+		g.startTUI()
+	}
+	return nil
+}
+
+// Run runs every session's iteration loop concurrently and waits for all of
+// them. A failing session does not stop the others; its error is included in
+// the combined error.
+func (g *Group) Run() error {
+	errs := make([]error, len(g.orchs))
+	var wg sync.WaitGroup
+	for i, o := range g.orchs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = o.Run()
+			if errs[i] == nil {
+				return
+			}
+			logger.Error("Session '%s' failed: %v", o.cfg.SessionName, errs[i])
+			if g.headless {
+				fmt.Fprintf(o.out, "Session failed: %v\n", errs[i])
+			} else if g.ctx.Err() == nil {
+				o.tuiProgram.Send(tui.ShowToastMsg{Text: fmt.Sprintf("Session %s stopped: %v", o.cfg.SessionName, errs[i])})
+			}
+		}()
+	}
+	wg.Wait()
+
+	multiErr := &ierr.MultiError{}
+	for i, err := range errs {
+		if err != nil {
+			multiErr.Append(fmt.Errorf("session %s: %w", g.orchs[i].cfg.SessionName, err))
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// Stop shuts down the TUI and every orchestrator.
+// Multiple calls to Stop() are safe and idempotent.
+func (g *Group) Stop() error {
+	if g.stopped {
+		return nil
+	}
+	g.stopped = true
+
+	multiErr := &ierr.MultiError{}
+	g.cancel()
+
+	if g.tuiProgram != nil {
+		logger.Debug("Waiting for TUI to finish")
+		select {
+		case <-g.tuiDone:
+			logger.Debug("TUI stopped successfully")
+		case <-time.After(2 * time.Second):
+			logger.Warn("TUI shutdown timed out after 2s, forcing quit")
+			g.tuiProgram.Quit()
+			multiErr.Append(ierr.NewTransientError("TUI shutdown", fmt.Errorf("timed out after 2s")))
+		}
+		g.tuiProgram = nil
+	}
+
+	// Stop in reverse start order: the first orchestrator usually owns the
+	// NATS server the others are connected to
+	for i := len(g.orchs) - 1; i >= 0; i-- {
+		if err := g.orchs[i].Stop(); err != nil {
+			multiErr.Append(fmt.Errorf("session %s: %w", g.orchs[i].cfg.SessionName, err))
+		}
+	}
+
+	for _, w := range g.writers {
+		if err := w.Flush(); err != nil {
+			logger.Warn("Failed to flush session output: %v", err)
+		}
+	}
+
+	return multiErr.ErrorOrNil()
+}
+
+// startTUI runs one Bubbletea program with a session switcher over every
+// orchestrator's App, and points each orchestrator at its tab.
+func (g *Group) startTUI() {
+	apps := make([]*tui.App, 0, len(g.orchs))
+	for _, o := range g.orchs {
+		apps = append(apps, o.tuiApp)
+	}
+
+	programOptions := []tea.ProgramOption{tea.WithContext(g.ctx)}
+	if g.tuiInput != nil {
+		programOptions = append(programOptions, tea.WithInput(g.tuiInput))
+	} else if !term.IsTerminal(os.Stdin.Fd()) {
+		logger.Debug("stdin is not a terminal, disabling TUI input")
+		programOptions = append(programOptions, tea.WithInput(nil))
+	}
+	program := tea.NewProgram(tui.NewSwitcher(apps), programOptions...)
+	g.tuiProgram = program
+
+	for _, o := range g.orchs {
+		o.tuiProgram = tui.NewSessionSender(program, o.cfg.SessionName)
+		o.tuiDone = g.tuiDone
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("TUI panic: %v", r)
+			}
+			close(g.tuiDone)
+		}()
+
+		if _, err := program.Run(); err != nil {
+			if g.ctx.Err() == nil && !errors.Is(err, tea.ErrInterrupted) {
+				logger.Error("TUI error: %v", err)
+			}
+		}
+	}()
+
+	// Quitting the TUI stops every session
+	go func() {
+		<-g.tuiDone
+		logger.Debug("TUI quit detected, cancelling all sessions")
+		for _, o := range g.orchs {
+			o.cancel()
+		}
+	}()
+}
+
+// prefixWriter prefixes every line with a session tag so interleaved headless
+// output from a Group stays readable. Partial lines are held until their
+// newline; the mutex is shared between writers so lines never interleave.
+type prefixWriter struct {
+	w      io.Writer
+	prefix []byte
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+func newPrefixWriter(w io.Writer, prefix string, mu *sync.Mutex) *prefixWriter {
+	return &prefixWriter{w: w, prefix: []byte(prefix), mu: mu}
+}
+
+// Write buffers p and writes out every completed line.
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+	p.buf = append([]byte(nil), p.buf...)
+	return len(b), nil
+}
+
+// Flush writes out a trailing partial line, if any.
+func (p *prefixWriter) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+// writeLine writes one prefixed line. Callers hold mu.
+func (p *prefixWriter) writeLine(line []byte) error {
+	if _, err := p.w.Write(p.prefix); err != nil {
+		return err
+	}
+	_, err := p.w.Write(line)
+	return err
+}
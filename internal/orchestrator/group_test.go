@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestGroupRunsSessionsConcurrently runs two headless replay sessions in one
+// group and checks both complete in their own worktrees on one NATS server.
+func TestGroupRunsSessionsConcurrently(t *testing.T) {
+	repo := t.TempDir()
+	runGitT(t, repo, "init", "-q", "-b", "main")
+	runGitT(t, repo, "config", "user.email", "test@example.com")
+	runGitT(t, repo, "config", "user.name", "Test")
+	runGitT(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	scriptPath := filepath.Join(t.TempDir(), "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - edit: {path: out.txt, content: "done\n"}
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	var cfgs []Config
+	for _, name := range []string{"alpha", "beta"} {
+		specPath := filepath.Join(t.TempDir(), name+".md")
+		if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] One\n"), 0644); err != nil {
+			t.Fatalf("failed to write spec file: %v", err)
+		}
+		cfgs = append(cfgs, Config{
+			SessionName:  name,
+			SpecPath:     specPath,
+			Iterations:   3,
+			DataDir:      filepath.Join(repo, ".iteratr"),
+			WorkDir:      repo,
+			Headless:     true,
+			Model:        "replay/test",
+			Backend:      "replay",
+			ReplayScript: scriptPath,
+			Worktree:     true,
+		})
+	}
+
+	group, err := NewGroup(cfgs)
+	if err != nil {
+		t.Fatalf("NewGroup() error: %v", err)
+	}
+	if err := group.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer func() { _ = group.Stop() }()
+
+	if !group.orchs[0].isPrimary || group.orchs[1].isPrimary {
+		t.Error("expected the first session to own the NATS server and the second to connect to it")
+	}
+	if group.orchs[1].natsPort != group.orchs[0].natsPort {
+		t.Errorf("node session port = %d, want shared port %d", group.orchs[1].natsPort, group.orchs[0].natsPort)
+	}
+
+	if err := group.Run(); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	for _, o := range group.orchs {
+		state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+		if err != nil {
+			t.Fatalf("failed to load state for %s: %v", o.cfg.SessionName, err)
+		}
+		if !state.Complete {
+			t.Errorf("session %s not complete", o.cfg.SessionName)
+		}
+		if _, err := os.Stat(filepath.Join(o.cfg.WorkDir, "out.txt")); err != nil {
+			t.Errorf("expected out.txt in %s worktree: %v", o.cfg.SessionName, err)
+		}
+	}
+	if group.orchs[0].cfg.WorkDir == group.orchs[1].cfg.WorkDir {
+		t.Error("sessions should run in separate worktrees")
+	}
+}
+
+func TestNewGroup_Validation(t *testing.T) {
+	if _, err := NewGroup(nil); err == nil {
+		t.Error("expected error for an empty group")
+	}
+	dup := []Config{{SessionName: "a", Headless: true}, {SessionName: "a", Headless: true}}
+	if _, err := NewGroup(dup); err == nil {
+		t.Error("expected error for duplicate session names")
+	}
+	mixed := []Config{{SessionName: "a", Headless: true}, {SessionName: "b"}}
+	if _, err := NewGroup(mixed); err == nil {
+		t.Error("expected error for mixed headless and TUI sessions")
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	a := newPrefixWriter(&out, "[a] ", &mu)
+	b := newPrefixWriter(&out, "[b] ", &mu)
+
+	_, _ = a.Write([]byte("hello "))
+	_, _ = b.Write([]byte("one\ntwo\n"))
+	_, _ = a.Write([]byte("world\npartial"))
+	if err := a.Flush(); err != nil {
+		t.Fatalf("Flush() error: %v", err)
+	}
+
+	want := "[b] one\n[b] two\n[a] hello world\n[a] partial\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
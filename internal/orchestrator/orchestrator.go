@@ -18,12 +18,12 @@ import (
 	"github.com/charmbracelet/x/term"
 	"github.com/mark3labs/iteratr/internal/agent"
 	ierr "github.com/mark3labs/iteratr/internal/errors"
+	"github.com/mark3labs/iteratr/internal/git"
 	"github.com/mark3labs/iteratr/internal/hooks"
 	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/mcpserver"
 	"github.com/mark3labs/iteratr/internal/nats"
 	"github.com/mark3labs/iteratr/internal/session"
-	"github.com/mark3labs/iteratr/internal/template"
 	"github.com/mark3labs/iteratr/internal/tui"
 	natsserver "github.com/nats-io/nats-server/v2/server"
 	natsgo "github.com/nats-io/nats.go"
@@ -35,44 +35,87 @@ type Config struct {
 	SessionName       string // Name of the session
 	SpecPath          string // Path to spec file
 	TemplatePath      string // Path to custom template (optional)
+	TemplateMode      string // Template rendering: template.ModeAuto (default), ModeLegacy, or ModeGo
 	ExtraInstructions string // Extra instructions (optional)
 	Iterations        int    // Max iterations (0 = infinite)
 	DataDir           string // Data directory for persistent storage
 	WorkDir           string // Working directory for agent
 	Headless          bool   // Run without TUI
 	Model             string // Model to use (e.g., anthropic/claude-sonnet-4-5)
+	Backend           string // Agent backend name (empty = agent.DefaultBackend)
+	ReplayScript      string // Script file for the replay backend
 	Reset             bool   // Reset session data before starting
 	AutoCommit        bool   // Auto-commit modified files after iteration
+	CommitMode        string // CommitModeAgent (default), CommitModeNative, or CommitModeNativeLLM
 	CommitDataDir     bool   // Include data_dir in auto-commit (default false)
+	Review            bool   // Review each iteration's diff before auto-commit (TUI modal or review hooks)
+	SpecSync          bool   // Check off the spec's checklist items as their tasks are completed
+	Worktree          bool   // Run the agent in a git worktree on branch iteratr/<session>
+
+	TemplateVars  map[string]string // Custom variables for Go templates (optional)
+	PromptBudget  int               // Estimated prompt size limit in tokens (0 = unlimited)
+	TranscriptMax int               // Size cap of each iteration's transcript in bytes (0 = unlimited, -1 = no transcripts)
+
+	Retention *nats.Retention    // JetStream retention limits (nil = nats.DefaultRetention)
+	Prices    session.PriceTable // Model prices for cost estimates (nil = costs not estimated)
+	Budget    Budget             // Session resource limits (zero = unlimited)
+
+	IterationTimeout time.Duration    // Cancel an iteration after this long (0 = no limit)
+	IdleTimeout      time.Duration    // Cancel an iteration when the agent is silent this long (0 = no limit)
+	Retry            ierr.RetryConfig // Retry policy for transient agent failures (zero = DefaultIterationRetry)
+}
+
+// tuiSender delivers messages to the TUI: the Bubbletea program itself, or a
+// session-tagged handle on a program shared by a Group.
+type tuiSender interface {
+	Send(msg tea.Msg)
+	Quit()
 }
 
 // Orchestrator manages the iteration loop with embedded NATS, agent runner, and TUI.
 type Orchestrator struct {
 	cfg               Config
-	ns                *natsserver.Server // Embedded NATS server (nil if node mode)
-	natsPort          int                // NATS server port
-	nc                *natsgo.Conn       // NATS connection
-	store             *session.Store     // Session store
-	mcpServer         *mcpserver.Server  // MCP tools server
-	runner            *agent.KitAgent    // Agent runner (KIT SDK in-process)
-	tuiApp            *tui.App           // TUI application (nil if headless)
-	tuiProgram        *tea.Program       // Bubbletea program
-	tuiDone           chan struct{}      // TUI completion signal
-	tuiInput          io.Reader          // Bubbletea input source (set in tests to avoid stdin races)
-	sendChan          chan string        // Channel for user input messages from TUI to orchestrator
-	ctx               context.Context    // Context for cancellation
-	cancel            context.CancelFunc // Cancel function
-	stopped           bool               // Track if Stop() was already called
-	isPrimary         bool               // True if this instance owns the NATS server
-	hooksConfig       *hooks.Config      // Hooks configuration (nil if no hooks file)
-	fileTracker       *agent.FileTracker // Tracks files modified during iteration (ACP events)
-	fileWatcher       *agent.FileWatcher // Watches filesystem for all file changes (fsnotify)
-	autoCommit        bool               // Auto-commit modified files after iteration
-	pendingHookOutput string             // Buffer for hook output to be sent in next iteration
-	pendingMu         sync.Mutex         // Protects pendingHookOutput (needed for NATS callback)
-	paused            atomic.Bool        // Pause state (atomic for thread-safe access)
-	resumeChan        chan struct{}      // Signals resume from pause
-	hookCounter       atomic.Int64       // Counter for generating unique hook IDs
+	ns                *natsserver.Server  // Embedded NATS server (nil if node mode)
+	natsPort          int                 // NATS server port
+	nc                *natsgo.Conn        // NATS connection
+	store             *session.Store      // Session store
+	mcpServer         *mcpserver.Server   // MCP tools server
+	runner            agent.Backend       // Agent runner (KIT SDK in-process by default)
+	tuiApp            *tui.App            // TUI application (nil if headless)
+	tuiProgram        tuiSender           // Bubbletea program (or Group session handle)
+	tuiDone           chan struct{}       // TUI completion signal
+	tuiInput          io.Reader           // Bubbletea input source (set in tests to avoid stdin races)
+	sharedTUI         bool                // TUI program is owned by a Group (Start only creates the App)
+	out               io.Writer           // Headless output (stdout, or a session-prefixed writer in a Group)
+	sendChan          chan string         // Channel for user input messages from TUI to orchestrator
+	ctx               context.Context     // Context for cancellation
+	cancel            context.CancelFunc  // Cancel function
+	stopped           bool                // Track if Stop() was already called
+	isPrimary         bool                // True if this instance owns the NATS server
+	hooksConfig       *hooks.Config       // Hooks configuration (nil if no hooks file)
+	fileTracker       *agent.FileTracker  // Tracks files modified during iteration (ACP events)
+	fileWatcher       *agent.FileWatcher  // Watches filesystem for all file changes (fsnotify)
+	autoCommit        bool                // Auto-commit modified files after iteration
+	pendingHookOutput string              // Buffer for hook output to be sent in next iteration
+	pendingMu         sync.Mutex          // Protects pendingHookOutput (needed for NATS callback)
+	paused            atomic.Bool         // Pause state (atomic for thread-safe access)
+	resumeChan        chan struct{}       // Signals resume from pause
+	hookCounter       atomic.Int64        // Counter for generating unique hook IDs
+	iteration         atomic.Int64        // Current iteration number (usage from agent turns is recorded against it)
+	runStartedAt      time.Time           // Start of this Run (duration budget is measured from it)
+	budgetTripped     map[string]bool     // Budget limits already enforced this run
+	lastActivity      atomic.Int64        // Unix nanos of the last agent event (idle watchdog)
+	worktree          *git.Worktree       // Session worktree (nil unless Config.Worktree)
+	repoDir           string              // Original working directory when running in a worktree
+	waiting           atomic.Bool         // True while the loop waits between iterations (rollback is safe)
+	capture           *strings.Builder    // Collects agent text while it writes a commit message (nil otherwise)
+	captureMu         sync.Mutex          // Protects capture
+	reviewChan        chan reviewDecision // Review decisions from the TUI
+	heldPaths         []string            // Uncommitted files kept by a review, tracked into the next iteration
+	specSynced        bool                // Spec was rewritten since it was last added to the file tracker
+	promptTrimmed     string              // Sections trimmed from the last prompt (reported when it changes)
+	transcript        *session.Transcript // Transcript of the iteration the agent is working on (nil before its first event)
+	transcriptMu      sync.Mutex          // Protects transcript
 }
 
 // New creates a new Orchestrator with the given configuration.
@@ -88,19 +131,25 @@ func New(cfg Config) (*Orchestrator, error) {
 		}
 		cfg.WorkDir = wd
 	}
+	if !agent.HasBackend(cfg.Backend) {
+		return nil, fmt.Errorf("unknown agent backend %q (available: %v)", cfg.Backend, agent.Backends())
+	}
 
 	// Create context for lifecycle management
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Orchestrator{
-		cfg:         cfg,
-		ctx:         ctx,
-		cancel:      cancel,
-		tuiDone:     make(chan struct{}),
-		sendChan:    make(chan string, 10), // Buffered channel for user input messages
-		fileTracker: agent.NewFileTracker(cfg.WorkDir),
-		autoCommit:  cfg.AutoCommit,
-		resumeChan:  make(chan struct{}, 1), // Buffered to prevent blocking on Resume()
+		cfg:           cfg,
+		ctx:           ctx,
+		cancel:        cancel,
+		tuiDone:       make(chan struct{}),
+		sendChan:      make(chan string, 10), // Buffered channel for user input messages
+		out:           os.Stdout,
+		fileTracker:   agent.NewFileTracker(cfg.WorkDir),
+		autoCommit:    cfg.AutoCommit,
+		resumeChan:    make(chan struct{}, 1), // Buffered to prevent blocking on Resume()
+		reviewChan:    make(chan reviewDecision, 1),
+		budgetTripped: make(map[string]bool),
 	}, nil
 }
 
@@ -154,7 +203,7 @@ func (o *Orchestrator) Start() error {
 			return fmt.Errorf("failed to reset session: %w", err)
 		}
 		logger.Info("Session '%s' reset successfully", o.cfg.SessionName)
-		fmt.Printf("Session '%s' reset successfully.\n", o.cfg.SessionName)
+		fmt.Fprintf(o.out, "Session '%s' reset successfully.\n", o.cfg.SessionName)
 	}
 
 	// 4. Check if session is already complete (before TUI starts)
@@ -187,25 +236,44 @@ func (o *Orchestrator) Start() error {
 		fmt.Println("Session restarted.")
 	}
 
+	// 4.5. Move the agent into its own git worktree if requested
+	if o.cfg.Worktree {
+		logger.Debug("Setting up git worktree")
+		if err := o.setupWorktree(); err != nil {
+			logger.Error("Failed to set up worktree: %v", err)
+			return fmt.Errorf("failed to set up worktree: %w", err)
+		}
+	}
+
 	// 5. Create agent runner (don't start yet - will start in Run())
 	logger.Debug("Creating agent runner")
 	// Runner will be initialized in Run() with proper callbacks after TUI is ready
 
 	// 6. Start TUI if not headless
-	if !o.cfg.Headless {
+	switch {
+	case o.cfg.Headless:
+		logger.Info("Running in headless mode")
+	case o.sharedTUI:
+		// The Group runs one program for all its sessions; only create the App
+		o.tuiApp = o.newTUIApp()
+	default:
 		logger.Debug("Starting TUI")
 		if err := o.startTUI(); err != nil {
 			logger.Error("Failed to start TUI: %v", err)
 			return fmt.Errorf("failed to start TUI: %w", err)
 		}
 		logger.Debug("TUI started")
-	} else {
-		logger.Info("Running in headless mode")
 	}
 
 	// 7. Load hooks configuration (optional)
 	logger.Debug("Loading hooks configuration")
-	hooksConfig, err := hooks.LoadConfig(o.cfg.WorkDir)
+	// Hooks config is read from the original checkout so uncommitted hook
+	// files still apply in worktree mode; hooks themselves run in WorkDir.
+	hooksDir := o.cfg.WorkDir
+	if o.worktree != nil {
+		hooksDir = o.repoDir
+	}
+	hooksConfig, err := hooks.LoadConfig(hooksDir)
 	if err != nil {
 		// Log warning but continue - hooks are optional
 		logger.Warn("Failed to load hooks config: %v", err)
@@ -213,6 +281,9 @@ func (o *Orchestrator) Start() error {
 		o.hooksConfig = hooksConfig
 		logger.Info("Hooks configuration loaded")
 	}
+	if o.cfg.Review && o.cfg.Headless && (o.hooksConfig == nil || len(o.hooksConfig.Hooks.Review) == 0) {
+		logger.Warn("Review is enabled but no review hooks are configured; headless iterations are approved automatically")
+	}
 
 	logger.Info("Orchestrator started successfully")
 	return nil
@@ -221,6 +292,7 @@ func (o *Orchestrator) Start() error {
 // Run executes the main iteration loop.
 func (o *Orchestrator) Run() error {
 	logger.Info("Starting iteration loop for session '%s'", o.cfg.SessionName)
+	o.runStartedAt = time.Now()
 
 	// Load current session state to determine starting iteration
 	logger.Debug("Loading session state")
@@ -231,12 +303,11 @@ func (o *Orchestrator) Run() error {
 	}
 
 	// Determine starting iteration number
-	// Fresh sessions start at iteration 0 (planning phase); resumed sessions skip to next iteration
-	startIteration := len(state.Iterations)
-	if startIteration == 0 {
+	startIteration := NextIteration(state)
+	if len(state.Iterations) == 0 && startIteration == 1 {
+		logger.Info("Fresh session has %d imported tasks, skipping Iteration #0 (planning phase)", len(state.Tasks))
+	} else if startIteration == 0 {
 		logger.Debug("Fresh session, will run Iteration #0 (planning phase)")
-	} else {
-		startIteration++ // Resume at next iteration after last completed
 	}
 	logger.Debug("Starting from iteration %d (found %d previous iterations)", startIteration, len(state.Iterations))
 
@@ -261,21 +332,22 @@ func (o *Orchestrator) Run() error {
 			}
 		}
 
-		fmt.Printf("=== Session: %s ===\n", o.cfg.SessionName)
-		fmt.Printf("Starting at iteration #%d\n", startIteration)
+		fmt.Fprintf(o.out, "=== Session: %s ===\n", o.cfg.SessionName)
+		fmt.Fprintf(o.out, "Starting at iteration #%d\n", startIteration)
 		if o.cfg.Iterations > 0 {
-			fmt.Printf("Max iterations: %d\n", o.cfg.Iterations)
+			fmt.Fprintf(o.out, "Max iterations: %d\n", o.cfg.Iterations)
 		} else {
-			fmt.Println("Max iterations: unlimited")
+			fmt.Fprintln(o.out, "Max iterations: unlimited")
 		}
-		fmt.Printf("Tasks: %d remaining, %d completed\n\n", remainingCount, completedCount)
+		fmt.Fprintf(o.out, "Tasks: %d remaining, %d completed\n\n", remainingCount, completedCount)
 	}
 
 	// Setup runner with callbacks based on headless mode
 	logger.Debug("Setting up agent runner with callbacks")
+	var backendCfg agent.BackendConfig
 	if o.tuiProgram != nil {
 		// TUI mode - send output to TUI
-		o.runner = agent.NewKitAgent(agent.KitAgentConfig{
+		backendCfg = agent.BackendConfig{
 			Model:        o.cfg.Model,
 			WorkDir:      o.cfg.WorkDir,
 			SessionName:  o.cfg.SessionName,
@@ -309,6 +381,7 @@ func (o *Orchestrator) Run() error {
 				o.tuiProgram.Send(tui.AgentThinkingMsg{Content: content})
 			},
 			OnFinish: func(event agent.FinishEvent) {
+				o.recordUsage(event)
 				msg := tui.AgentFinishMsg{
 					Reason:   event.StopReason,
 					Error:    event.Error,
@@ -348,72 +421,86 @@ func (o *Orchestrator) Run() error {
 			OnSubagentThinking: func(toolCallID, content string) {
 				o.tuiProgram.Send(tui.SubagentThinkingMsg{Content: content})
 			},
-		})
+		}
 	} else {
 		// Headless mode - print to stdout
-		o.runner = agent.NewKitAgent(agent.KitAgentConfig{
+		backendCfg = agent.BackendConfig{
 			Model:        o.cfg.Model,
 			WorkDir:      o.cfg.WorkDir,
 			SessionName:  o.cfg.SessionName,
 			NATSPort:     o.natsPort,
 			MCPServerURL: o.mcpServer.URL(),
 			OnText: func(content string) {
-				fmt.Print(content)
+				fmt.Fprint(o.out, content)
 			},
 			OnToolCall: func(event agent.ToolCallEvent) {
 				// Simple tool lifecycle output for headless mode
 				switch event.Status {
 				case "pending":
-					fmt.Printf("\n[tool: %s] ...\n", event.Title)
+					fmt.Fprintf(o.out, "\n[tool: %s] ...\n", event.Title)
 				case "in_progress":
 					if cmd, ok := event.RawInput["command"].(string); ok {
-						fmt.Printf("[tool: %s] command: %s\n", event.Title, cmd)
+						fmt.Fprintf(o.out, "[tool: %s] command: %s\n", event.Title, cmd)
 					}
 				case "completed":
 					outputLines := len(event.Output)
 					if outputLines > 0 {
-						fmt.Printf("[tool: %s] ✓ (output: %d bytes)\n", event.Title, len(event.Output))
+						fmt.Fprintf(o.out, "[tool: %s] ✓ (output: %d bytes)\n", event.Title, len(event.Output))
 					} else {
-						fmt.Printf("[tool: %s] ✓\n", event.Title)
+						fmt.Fprintf(o.out, "[tool: %s] ✓\n", event.Title)
 					}
 				}
 			},
 			OnThinking: func(content string) {
 				// Print thinking content dimmed in headless mode
-				fmt.Printf("\033[2m%s\033[0m", content)
+				fmt.Fprintf(o.out, "\033[2m%s\033[0m", content)
 			},
 			OnFinish: func(event agent.FinishEvent) {
+				usage := o.recordUsage(event)
 				// Print finish summary in headless mode
-				fmt.Printf("\n--- Agent finished: %s", event.StopReason)
+				fmt.Fprintf(o.out, "\n--- Agent finished: %s", event.StopReason)
 				if event.Error != "" {
-					fmt.Printf(" (error: %s)", event.Error)
+					fmt.Fprintf(o.out, " (error: %s)", event.Error)
 				}
-				fmt.Printf(" | Duration: %s", event.Duration.Round(time.Millisecond))
+				fmt.Fprintf(o.out, " | Duration: %s", event.Duration.Round(time.Millisecond))
 				if event.Model != "" {
-					fmt.Printf(" | Model: %s", event.Model)
+					fmt.Fprintf(o.out, " | Model: %s", event.Model)
 				}
 				if event.Usage != nil {
-					fmt.Printf(" | Tokens: in=%d out=%d", event.Usage.InputTokens, event.Usage.OutputTokens)
+					fmt.Fprintf(o.out, " | Tokens: in=%d out=%d", event.Usage.InputTokens, event.Usage.OutputTokens)
 					if event.Usage.CacheReadTokens > 0 || event.Usage.CacheCreationTokens > 0 {
-						fmt.Printf(" cache_read=%d cache_write=%d", event.Usage.CacheReadTokens, event.Usage.CacheCreationTokens)
+						fmt.Fprintf(o.out, " cache_read=%d cache_write=%d", event.Usage.CacheReadTokens, event.Usage.CacheCreationTokens)
 					}
 				}
-				fmt.Println(" ---")
+				if usage.Cost > 0 {
+					fmt.Fprintf(o.out, " | Cost: ~$%.4f", usage.Cost)
+				}
+				fmt.Fprintln(o.out, " ---")
 			},
 			OnFileChange: func(change agent.FileChange) {
 				// Record change in tracker
 				o.fileTracker.RecordChange(change.AbsPath, change.IsNew, change.Additions, change.Deletions)
 			},
-		})
+		}
 	}
 
-	// Start the KIT agent
-	logger.Debug("Starting KIT agent")
+	backendCfg.ScriptPath = o.cfg.ReplayScript
+	o.captureText(&backendCfg)
+	o.trackActivity(&backendCfg)
+	o.recordTranscript(&backendCfg)
+	runner, err := agent.NewBackend(o.cfg.Backend, backendCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create agent backend: %w", err)
+	}
+	o.runner = o.transcriptRunner(runner)
+
+	// Start the agent backend
+	logger.Debug("Starting agent backend %q", o.cfg.Backend)
 	if err := o.runner.Start(o.ctx); err != nil {
-		logger.Error("Failed to start KIT agent: %v", err)
-		return fmt.Errorf("failed to start KIT agent: %w", err)
+		logger.Error("Failed to start agent backend: %v", err)
+		return fmt.Errorf("failed to start agent backend: %w", err)
 	}
-	logger.Debug("KIT agent started successfully")
+	logger.Debug("Agent backend started successfully")
 	// Ensure runner is stopped on exit
 	defer func() {
 		if o.runner != nil {
@@ -442,6 +529,13 @@ func (o *Orchestrator) Run() error {
 		}
 	}
 
+	// Bring the spec's checklist in line with tasks that already exist (e.g.
+	// imported or from a previous run); after that it is synced at the end of
+	// each iteration, between the agent's turns and the auto-commit
+	if o.cfg.SpecSync && o.cfg.SpecPath != "" {
+		o.syncSpec()
+	}
+
 	// Run Iteration #0 (planning phase) for fresh sessions
 	// No hooks are executed during iteration #0 — hooks are set up after.
 	if startIteration == 0 {
@@ -583,24 +677,25 @@ func (o *Orchestrator) Run() error {
 		// Check iteration limit (0 = infinite)
 		if o.cfg.Iterations > 0 && iterationCount >= o.cfg.Iterations {
 			logger.Info("Reached iteration limit of %d", o.cfg.Iterations)
-			fmt.Printf("Reached iteration limit of %d\n", o.cfg.Iterations)
+			fmt.Fprintf(o.out, "Reached iteration limit of %d\n", o.cfg.Iterations)
 			break
 		}
 
 		logger.Info("=== Starting iteration #%d ===", currentIteration)
 
 		// Clear file tracker and watcher for new iteration
-		o.fileTracker.Clear()
-		if o.fileWatcher != nil {
-			o.fileWatcher.Clear()
-		}
+		o.resetFileTracking()
 		logger.Debug("File tracker cleared for iteration #%d", currentIteration)
 
+		// Record the working tree so this iteration can be rolled back
+		o.checkpoint(currentIteration)
+
 		// Log iteration start
 		if err := o.store.IterationStart(o.ctx, o.cfg.SessionName, currentIteration); err != nil {
 			logger.Error("Failed to log iteration start: %v", err)
 			return fmt.Errorf("failed to log iteration start: %w", err)
 		}
+		o.iteration.Store(int64(currentIteration))
 
 		// Send iteration start message to TUI
 		if o.tuiProgram != nil {
@@ -651,27 +746,19 @@ func (o *Orchestrator) Run() error {
 
 		// Build prompt with current state
 		logger.Debug("Building prompt for iteration #%d", currentIteration)
-		prompt, err := template.BuildPrompt(o.ctx, template.BuildConfig{
-			SessionName:       o.cfg.SessionName,
-			Store:             o.store,
-			IterationNumber:   currentIteration,
-			SpecPath:          o.cfg.SpecPath,
-			TemplatePath:      o.cfg.TemplatePath,
-			ExtraInstructions: o.cfg.ExtraInstructions,
-			NATSPort:          o.natsPort,
-		})
+		prompt, report, err := o.buildPrompt(currentIteration)
 		if err != nil {
 			logger.Error("Failed to build prompt: %v", err)
 			return fmt.Errorf("failed to build prompt: %w", err)
 		}
+		o.reportPromptTrim(currentIteration, report)
 		logger.Debug("Prompt built, length: %d characters", len(prompt))
 
 		// Run agent iteration with panic recovery (reusing persistent ACP session)
+		// under the timeout watchdog, retrying transient provider failures.
 		// Hook output is sent as a separate content block before the main prompt
 		logger.Info("Running agent for iteration #%d", currentIteration)
-		err = ierr.Recover(func() error {
-			return o.runner.RunIteration(o.ctx, prompt, hookOutput)
-		})
+		err = o.runIteration(currentIteration, prompt, hookOutput)
 		if err != nil {
 			// Check if context was cancelled (TUI quit, signal, etc.) - exit gracefully
 			if o.ctx.Err() != nil {
@@ -688,6 +775,21 @@ func (o *Orchestrator) Run() error {
 				logger.Error("Iteration #%d panicked with stack trace: %s", currentIteration, panicErr.StackTrace)
 			}
 
+			// Record watchdog timeouts so the iteration no longer looks running
+			var timeoutErr *ierr.TimeoutError
+			timedOut := errors.As(err, &timeoutErr)
+			if timedOut {
+				if err := o.store.IterationTimeout(o.ctx, o.cfg.SessionName, currentIteration, timeoutErr.Kind, timeoutErr.Error()); err != nil {
+					logger.Warn("Failed to record iteration timeout: %v", err)
+				}
+				if o.cfg.Headless {
+					fmt.Fprintf(o.out, "\n✗ Iteration #%d timed out: %s\n\n", currentIteration, timeoutErr)
+				}
+				if o.tuiProgram != nil {
+					o.tuiProgram.Send(tui.ShowToastMsg{Text: fmt.Sprintf("Iteration #%d timed out: %s", currentIteration, timeoutErr)})
+				}
+			}
+
 			// Execute on_error hooks if configured
 			if o.hooksConfig != nil && len(o.hooksConfig.Hooks.OnError) > 0 {
 				logger.Info("Executing on_error hooks for iteration #%d", currentIteration)
@@ -695,6 +797,7 @@ func (o *Orchestrator) Run() error {
 					Session:   o.cfg.SessionName,
 					Iteration: strconv.Itoa(currentIteration),
 					Error:     err.Error(),
+					ErrorType: errorType(err),
 				}
 				onStart, onComplete, _ := o.hookCallbacks("on_error")
 				hookOutput, hookErr := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.OnError, o.cfg.WorkDir, hookVars, onStart, onComplete)
@@ -708,6 +811,28 @@ func (o *Orchestrator) Run() error {
 					// Continue despite hook failure
 				}
 
+				// A timed-out agent is not asked to recover immediately (it may
+				// get stuck again); piped output goes to the next iteration instead.
+				if timedOut {
+					if hookOutput != "" {
+						o.appendPendingOutput(fmt.Sprintf(
+							"[ON-ERROR HOOKS - iteration #%d]\n"+
+								"The previous iteration was cancelled: %s\n\n"+
+								"Diagnostic output from error hooks:\n%s",
+							currentIteration, err.Error(), hookOutput,
+						))
+					}
+					logger.Info("Continuing to next iteration after timeout")
+					if o.stopAfterFailedIteration(currentIteration) {
+						if o.ctx.Err() != nil {
+							return nil
+						}
+						break
+					}
+					iterationCount++
+					continue
+				}
+
 				// If hooks produced piped output, send to agent for immediate recovery
 				if hookOutput != "" {
 					logger.Info("Sending on_error hook output to agent for recovery attempt")
@@ -771,6 +896,26 @@ func (o *Orchestrator) Run() error {
 
 				// Continue to next iteration (don't exit session when hooks configured)
 				logger.Info("Continuing to next iteration after error")
+				if o.stopAfterFailedIteration(currentIteration) {
+					if o.ctx.Err() != nil {
+						return nil
+					}
+					break
+				}
+				iterationCount++
+				continue
+			}
+
+			// Timeouts never end the session - move on to the next iteration
+			if timedOut {
+				logger.Info("Continuing to next iteration after timeout")
+				if o.stopAfterFailedIteration(currentIteration) {
+					if o.ctx.Err() != nil {
+						return nil
+					}
+					break
+				}
+				iterationCount++
 				continue
 			}
 
@@ -834,9 +979,20 @@ func (o *Orchestrator) Run() error {
 			logger.Debug("File watcher detected %d changed paths, merging into tracker", len(watcherPaths))
 			o.fileTracker.MergeWatcherPaths(watcherPaths)
 		}
+		o.trackSpecSync()
+
+		// Gate auto-commit on a review of the iteration's changes
+		approved := true
+		if o.cfg.Review && o.fileTracker.HasChanges() {
+			approved = o.reviewIteration(currentIteration)
+			if o.ctx.Err() != nil {
+				logger.Info("Context cancelled during review")
+				return nil
+			}
+		}
 
-		// Run auto-commit if enabled and files were modified
-		if o.autoCommit && o.fileTracker.HasChanges() {
+		// Run auto-commit if enabled, approved, and files were modified
+		if o.autoCommit && approved && o.fileTracker.HasChanges() {
 			logger.Info("Auto-commit enabled with %d modified files, running commit", o.fileTracker.Count())
 			if err := o.runAutoCommit(o.ctx); err != nil {
 				logger.Warn("Auto-commit failed: %v", err)
@@ -846,7 +1002,7 @@ func (o *Orchestrator) Run() error {
 
 		// Print completion message in headless mode
 		if o.cfg.Headless {
-			fmt.Printf("\n✓ Iteration #%d complete\n\n", currentIteration)
+			fmt.Fprintf(o.out, "\n✓ Iteration #%d complete\n\n", currentIteration)
 		}
 
 		// Check if session_complete was signaled by checking session state
@@ -861,16 +1017,23 @@ func (o *Orchestrator) Run() error {
 			if o.tuiProgram != nil {
 				o.tuiProgram.Send(tui.SessionCompleteMsg{})
 			}
+			// Headless mode has no TUI to send follow-up messages - we're done
+			if o.cfg.Headless {
+				break
+			}
 			// Continue processing user messages after completion
 			// If agent restarts session, resume normal iteration
+			o.waiting.Store(true)
 		postCompletionLoop:
 			for {
 				select {
 				case <-o.tuiDone:
 					break postCompletionLoop
 				case <-o.ctx.Done():
+					o.waiting.Store(false)
 					return nil
 				case userMsg := <-o.sendChan:
+					o.waiting.Store(false)
 					logger.Info("Processing user message after completion")
 					if o.tuiProgram != nil {
 						o.tuiProgram.Send(tui.QueuedMessageProcessingMsg{Text: userMsg})
@@ -890,8 +1053,10 @@ func (o *Orchestrator) Run() error {
 							break postCompletionLoop
 						}
 					}
+					o.waiting.Store(true)
 				}
 			}
+			o.waiting.Store(false)
 			// Only exit main loop if session is still complete
 			// If restarted, continue iterating
 			state, err = o.store.LoadState(o.ctx, o.cfg.SessionName)
@@ -904,6 +1069,14 @@ func (o *Orchestrator) Run() error {
 			}
 		}
 
+		// Enforce session budget (stop ends the loop, pause is handled by waitIfPaused below)
+		if o.checkBudget(state, currentIteration) {
+			if o.ctx.Err() != nil {
+				return nil
+			}
+			break
+		}
+
 		// After iteration completes, process ALL queued user messages
 		if err := o.processUserMessages(); err != nil {
 			if errors.Is(err, context.Canceled) {
@@ -979,15 +1152,16 @@ func (o *Orchestrator) runIteration0() error {
 	logger.Info("=== Starting Iteration #0 (Planning Phase) ===")
 
 	// Clear file tracker and watcher for iteration #0
-	o.fileTracker.Clear()
-	if o.fileWatcher != nil {
-		o.fileWatcher.Clear()
-	}
+	o.resetFileTracking()
+
+	// Record the working tree so this iteration can be rolled back
+	o.checkpoint(0)
 
 	// Log iteration start
 	if err := o.store.IterationStart(o.ctx, o.cfg.SessionName, 0); err != nil {
 		return fmt.Errorf("failed to log iteration #0 start: %w", err)
 	}
+	o.iteration.Store(0)
 
 	// Send iteration start message to TUI
 	if o.tuiProgram != nil {
@@ -995,22 +1169,23 @@ func (o *Orchestrator) runIteration0() error {
 	}
 
 	// Build the planning prompt using the Iteration #0 template
-	prompt, err := template.BuildIteration0Prompt(o.ctx, template.BuildConfig{
-		SessionName:       o.cfg.SessionName,
-		Store:             o.store,
-		IterationNumber:   0,
-		SpecPath:          o.cfg.SpecPath,
-		ExtraInstructions: o.cfg.ExtraInstructions,
-		NATSPort:          o.natsPort,
-	})
+	prompt, _, err := o.buildPrompt(0)
 	if err != nil {
 		return fmt.Errorf("failed to build iteration #0 prompt: %w", err)
 	}
 	logger.Debug("Iteration #0 prompt built, length: %d characters", len(prompt))
 
-	// Run the agent using the main MCP server (same as iteration loop)
+	// Run the agent using the main MCP server (same as iteration loop),
+	// under the same timeout watchdog and transient-failure retries
 	logger.Info("Running agent for Iteration #0")
-	if err := o.runner.RunIteration(o.ctx, prompt, ""); err != nil {
+	if err := o.runIteration(0, prompt, ""); err != nil {
+		// Record watchdog timeouts so the iteration no longer looks running
+		var timeoutErr *ierr.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			if err := o.store.IterationTimeout(o.ctx, o.cfg.SessionName, 0, timeoutErr.Kind, timeoutErr.Error()); err != nil {
+				logger.Warn("Failed to record iteration timeout: %v", err)
+			}
+		}
 		return fmt.Errorf("iteration #0 agent execution failed: %w", err)
 	}
 
@@ -1022,14 +1197,19 @@ func (o *Orchestrator) runIteration0() error {
 	logger.Info("=== Iteration #0 (Planning Phase) completed ===")
 
 	if o.cfg.Headless {
-		fmt.Printf("\n✓ Iteration #0 (planning) complete\n\n")
+		fmt.Fprintf(o.out, "\n✓ Iteration #0 (planning) complete\n\n")
 	}
 
-	// Run auto-commit for iteration #0 if enabled and files were modified
+	// Run auto-commit for iteration #0 if enabled, approved, and files were modified
 	if o.fileWatcher != nil && o.fileWatcher.HasChanges() {
 		o.fileTracker.MergeWatcherPaths(o.fileWatcher.ChangedPaths())
 	}
-	if o.autoCommit && o.fileTracker.HasChanges() {
+	o.trackSpecSync()
+	approved := true
+	if o.cfg.Review && o.fileTracker.HasChanges() {
+		approved = o.reviewIteration(0)
+	}
+	if o.autoCommit && approved && o.fileTracker.HasChanges() {
 		logger.Info("Auto-commit enabled with %d modified files after iteration #0", o.fileTracker.Count())
 		if err := o.runAutoCommit(o.ctx); err != nil {
 			logger.Warn("Auto-commit failed after iteration #0: %v", err)
@@ -1039,6 +1219,42 @@ func (o *Orchestrator) runIteration0() error {
 	return nil
 }
 
+// recordUsage stores the token usage of a finished agent turn against the
+// current iteration, with its cost estimated from the configured prices.
+// Turns without usage (e.g. early errors) record nothing.
+func (o *Orchestrator) recordUsage(event agent.FinishEvent) session.Usage {
+	if event.Usage == nil {
+		return session.Usage{}
+	}
+	usage, model := o.finishUsage(event)
+	if err := o.store.IterationUsage(o.ctx, o.cfg.SessionName, int(o.iteration.Load()), model, usage); err != nil {
+		logger.Warn("Failed to record iteration usage: %v", err)
+	}
+	return usage
+}
+
+// finishUsage converts the usage of a finished agent turn, with its cost
+// estimated from the configured prices, and returns the model it ran on.
+func (o *Orchestrator) finishUsage(event agent.FinishEvent) (session.Usage, string) {
+	model := event.Model
+	if model == "" {
+		model = o.cfg.Model
+	}
+	if event.Usage == nil {
+		return session.Usage{}, model
+	}
+	usage := session.Usage{
+		InputTokens:         event.Usage.InputTokens,
+		OutputTokens:        event.Usage.OutputTokens,
+		CacheReadTokens:     event.Usage.CacheReadTokens,
+		CacheCreationTokens: event.Usage.CacheCreationTokens,
+		ReasoningTokens:     event.Usage.ReasoningTokens,
+		Duration:            event.Duration,
+	}
+	usage.Cost = o.cfg.Prices.Cost(model, usage)
+	return usage, model
+}
+
 // processUserMessages drains sendChan and sends all queued messages as a single ACP request.
 // Each message becomes a separate content block, but appears as separate messages in the TUI.
 // Called after each agent response (iteration or user message).
@@ -1087,8 +1303,9 @@ send:
 }
 
 // runAutoCommit executes auto-commit after iteration completes.
-// Checks if in git repo, builds commit prompt with file list and context,
-// and reuses existing Runner to send commit request to current ACP session.
+// Checks if in git repo, then either commits natively (native modes) or
+// builds a commit prompt with file list and context and reuses the existing
+// Runner to send the commit request to the current ACP session.
 func (o *Orchestrator) runAutoCommit(ctx context.Context) error {
 	// Check if in git repo
 	if !isGitRepo(o.cfg.WorkDir) {
@@ -1096,6 +1313,11 @@ func (o *Orchestrator) runAutoCommit(ctx context.Context) error {
 		return nil
 	}
 
+	if o.cfg.CommitMode == CommitModeNative || o.cfg.CommitMode == CommitModeNativeLLM {
+		logger.Info("Running native auto-commit for %d modified file(s)", o.fileTracker.Count())
+		return o.runNativeCommit(ctx)
+	}
+
 	logger.Info("Running auto-commit for %d modified file(s)", o.fileTracker.Count())
 
 	// Build commit prompt with file list and context
@@ -1231,13 +1453,16 @@ func (o *Orchestrator) Stop() error {
 		o.fileWatcher = nil
 	}
 
-	// Stop agent runner (closes KIT SDK instance)
+	// Stop agent runner (closes KIT SDK instance for the kit backend)
 	if o.runner != nil {
 		logger.Debug("Stopping KIT agent")
 		o.runner.Stop()
 		o.runner = nil
 	}
 
+	// Leave the worktree branch ready for review (needs the store, so before NATS shutdown)
+	o.finishWorktree()
+
 	// Stop MCP server (after runner, before NATS)
 	if o.mcpServer != nil {
 		logger.Debug("Stopping MCP server")
@@ -1294,6 +1519,10 @@ func (o *Orchestrator) ensureNATS() error {
 		logger.Info("Connected to existing NATS server (node mode)")
 		o.nc = nc
 		o.isPrimary = false
+		// The prompt template tells the agent which port to use for tool calls
+		if port, err := nats.ReadPort(dataDir); err == nil {
+			o.natsPort = port
+		}
 		return nil
 	}
 
@@ -1327,20 +1556,30 @@ func (o *Orchestrator) setupJetStream() error {
 	}
 
 	// Setup stream
-	stream, err := nats.SetupStream(o.ctx, js)
+	retention := nats.DefaultRetention()
+	if o.cfg.Retention != nil {
+		retention = *o.cfg.Retention
+	}
+	stream, err := nats.SetupStreamWithRetention(o.ctx, js, retention)
 	if err != nil {
 		return fmt.Errorf("failed to setup stream: %w", err)
 	}
 
 	// Create session store
 	o.store = session.NewStore(js, stream)
+	o.store.SetRetention(retention)
 	return nil
 }
 
+// newTUIApp creates the TUI application for this session.
+func (o *Orchestrator) newTUIApp() *tui.App {
+	return tui.NewApp(o.ctx, o.store, o.cfg.SessionName, o.cfg.WorkDir, o.cfg.DataDir, o.nc, o.sendChan, o)
+}
+
 // startTUI initializes and starts the Bubbletea TUI.
 func (o *Orchestrator) startTUI() error {
 	// Create TUI app
-	o.tuiApp = tui.NewApp(o.ctx, o.store, o.cfg.SessionName, o.cfg.WorkDir, o.cfg.DataDir, o.nc, o.sendChan, o)
+	o.tuiApp = o.newTUIApp()
 
 	// Create Bubbletea program with context for graceful shutdown.
 	// In tests/non-interactive environments, avoid reading os.Stdin to prevent
@@ -1352,7 +1591,8 @@ func (o *Orchestrator) startTUI() error {
 		logger.Debug("stdin is not a terminal, disabling TUI input")
 		programOptions = append(programOptions, tea.WithInput(nil))
 	}
-	o.tuiProgram = tea.NewProgram(o.tuiApp, programOptions...)
+	program := tea.NewProgram(o.tuiApp, programOptions...)
+	o.tuiProgram = program
 
 	// Start TUI in background with panic recovery
 	go func() {
@@ -1365,7 +1605,7 @@ func (o *Orchestrator) startTUI() error {
 			close(o.tuiDone)
 		}()
 
-		if _, err := o.tuiProgram.Run(); err != nil {
+		if _, err := program.Run(); err != nil {
 			// Ignore expected shutdown errors (context cancelled, user interrupt)
 			if o.ctx.Err() == nil && !errors.Is(err, tea.ErrInterrupted) {
 				logger.Error("TUI error: %v", err)
@@ -1422,7 +1662,8 @@ func (o *Orchestrator) hasPendingOutput() bool {
 }
 
 // isGitRepo checks if the given directory is inside a git repository.
-// Returns true if a .git directory exists in the given path or any parent directory.
+// Returns true if a .git directory (or, for worktrees and submodules, a .git
+// file) exists in the given path or any parent directory.
 func isGitRepo(dir string) bool {
 	// Walk up the directory tree looking for .git
 	absDir, err := filepath.Abs(dir)
@@ -1433,7 +1674,7 @@ func isGitRepo(dir string) bool {
 	current := absDir
 	for {
 		gitPath := filepath.Join(current, ".git")
-		if info, err := os.Stat(gitPath); err == nil && info.IsDir() {
+		if _, err := os.Stat(gitPath); err == nil {
 			return true
 		}
 
@@ -1487,6 +1728,8 @@ func (o *Orchestrator) waitIfPaused() error {
 
 	// Paused flag is set - notify TUI that we're now blocking
 	logger.Info("Orchestrator paused, waiting for resume signal")
+	o.waiting.Store(true)
+	defer o.waiting.Store(false)
 	if o.tuiProgram != nil {
 		o.tuiProgram.Send(tui.PauseStateMsg{Paused: true})
 	}
@@ -588,7 +588,7 @@ func TestIsGitRepo(t *testing.T) {
 				// Create .git as a file (worktree reference)
 				return os.WriteFile(filepath.Join(dir, ".git"), []byte("gitdir: ../main/.git"), 0644)
 			},
-			expected: false, // We only check for .git directory
+			expected: true, // Linked worktrees (see Config.Worktree) use a .git file
 		},
 	}
 
@@ -0,0 +1,239 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/template"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// savedPrompts is the number of recent prompts kept per session for
+// 'iteratr prompt preview --diff'.
+const savedPrompts = 10
+
+// NextIteration returns the number of the iteration a build of the session
+// would run next. Fresh sessions start at iteration 0 (planning phase), or at
+// 1 when their tasks were imported and there is nothing to plan; resumed
+// sessions continue after the last recorded iteration.
+func NextIteration(state *session.State) int {
+	switch {
+	case len(state.Iterations) > 0:
+		return state.Iterations[len(state.Iterations)-1].Number + 1
+	case len(state.Tasks) > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// buildPrompt renders the prompt for an iteration and saves it for
+// 'iteratr prompt preview --diff'.
+func (o *Orchestrator) buildPrompt(iteration int) (string, template.PromptReport, error) {
+	prompt, report, err := o.renderPrompt(iteration)
+	if err != nil {
+		return "", template.PromptReport{}, err
+	}
+	if err := SavePrompt(o.cfg.DataDir, o.cfg.SessionName, iteration, prompt); err != nil {
+		logger.Warn("Failed to save prompt for iteration #%d: %v", iteration, err)
+	}
+	return prompt, report, nil
+}
+
+// renderPrompt renders the prompt for an iteration: the planning prompt for
+// iteration 0, the session template otherwise.
+func (o *Orchestrator) renderPrompt(iteration int) (string, template.PromptReport, error) {
+	cfg := template.BuildConfig{
+		SessionName:       o.cfg.SessionName,
+		Store:             o.store,
+		IterationNumber:   iteration,
+		SpecPath:          o.cfg.SpecPath,
+		TemplatePath:      o.cfg.TemplatePath,
+		ExtraInstructions: o.cfg.ExtraInstructions,
+		NATSPort:          o.natsPort,
+		Mode:              o.cfg.TemplateMode,
+		Vars:              o.cfg.TemplateVars,
+		Config:            o.templateConfig(),
+		TokenBudget:       o.cfg.PromptBudget,
+	}
+
+	var (
+		prompt string
+		report template.PromptReport
+		err    error
+	)
+	if iteration == 0 {
+		prompt, err = template.BuildIteration0Prompt(o.ctx, cfg)
+	} else {
+		prompt, report, err = template.BuildPromptWithReport(o.ctx, cfg)
+	}
+	return prompt, report, err
+}
+
+// templateConfig returns the build configuration exposed to Go prompt
+// templates as {{.Config}}.
+func (o *Orchestrator) templateConfig() template.ConfigData {
+	return template.ConfigData{
+		Model:      o.cfg.Model,
+		Backend:    o.cfg.Backend,
+		Iterations: o.cfg.Iterations,
+		AutoCommit: o.cfg.AutoCommit,
+		CommitMode: o.cfg.CommitMode,
+		Review:     o.cfg.Review,
+		SpecSync:   o.cfg.SpecSync,
+		Worktree:   o.cfg.Worktree,
+		SpecPath:   o.cfg.SpecPath,
+		WorkDir:    o.cfg.WorkDir,
+	}
+}
+
+// reportPromptTrim tells the user which prompt sections were trimmed to fit
+// the prompt budget. Sessions over budget are trimmed every iteration, so this
+// only reports when the set of trimmed sections changes.
+func (o *Orchestrator) reportPromptTrim(iteration int, report template.PromptReport) {
+	sections := make([]string, 0, len(report.Trimmed))
+	for _, trimmed := range report.Trimmed {
+		section, _, _ := strings.Cut(trimmed, ":")
+		sections = append(sections, section)
+	}
+	summary := strings.Join(sections, ", ")
+	if summary == o.promptTrimmed {
+		return
+	}
+	o.promptTrimmed = summary
+	if summary == "" {
+		return
+	}
+
+	msg := fmt.Sprintf("Prompt for iteration #%d trimmed to ~%d tokens (budget %d): %s",
+		iteration, report.Tokens, report.Budget, summary)
+	logger.Info("%s", msg)
+	if o.cfg.Headless {
+		fmt.Fprintf(o.out, "%s\n", msg)
+	}
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.ShowToastMsg{Text: msg})
+	}
+}
+
+// promptDir is the directory holding a session's saved prompts.
+func promptDir(dataDir, sessionName string) string {
+	return filepath.Join(dataDir, "prompts", sessionName)
+}
+
+// SavePrompt stores the prompt sent for an iteration of a session, keeping
+// only the most recent savedPrompts prompts.
+func SavePrompt(dataDir, sessionName string, iteration int, prompt string) error {
+	dir := promptDir(dataDir, sessionName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create prompt directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.md", iteration)), []byte(prompt), 0644); err != nil {
+		return fmt.Errorf("failed to write prompt: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list saved prompts: %w", err)
+	}
+	for _, entry := range entries {
+		n, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".md"))
+		if err == nil && n <= iteration-savedPrompts {
+			_ = os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+	return nil
+}
+
+// LoadPrompt returns the saved prompt of an iteration of a session.
+func LoadPrompt(dataDir, sessionName string, iteration int) (string, error) {
+	data, err := os.ReadFile(filepath.Join(promptDir(dataDir, sessionName), fmt.Sprintf("%d.md", iteration)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no saved prompt for iteration #%d of session %s (prompts are saved as builds send them, and only the last %d are kept)", iteration, sessionName, savedPrompts)
+		}
+		return "", fmt.Errorf("failed to read saved prompt: %w", err)
+	}
+	return string(data), nil
+}
+
+// Preview is what the agent would receive for an iteration.
+type Preview struct {
+	Iteration  int
+	HookOutput string // Piped output of session_start and pre_iteration hooks, sent before the prompt
+	Prompt     string
+	Report     template.PromptReport
+}
+
+// PreviewOptions selects what PreviewPrompt renders.
+type PreviewOptions struct {
+	Iteration int  // Iteration to render, -1 = the next one a build would run (see NextIteration)
+	RunHooks  bool // Execute session_start and pre_iteration hooks (all of them, for real) for their piped output
+	NATSPort  int  // NATS server port shown to the agent
+}
+
+// PreviewPrompt renders the prompt an iteration of cfg's session would get,
+// without starting the agent. Hooks run as at the start of a build, except
+// for iteration 0 which runs none.
+func PreviewPrompt(ctx context.Context, store *session.Store, cfg Config, opts PreviewOptions) (*Preview, error) {
+	if cfg.WorkDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+		cfg.WorkDir = wd
+	}
+	if cfg.DataDir == "" {
+		cfg.DataDir = ".iteratr"
+	}
+	o := &Orchestrator{cfg: cfg, store: store, ctx: ctx, natsPort: opts.NATSPort}
+
+	iteration := opts.Iteration
+	if iteration < 0 {
+		state, err := store.LoadState(ctx, cfg.SessionName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session state: %w", err)
+		}
+		iteration = NextIteration(state)
+	}
+	preview := &Preview{Iteration: iteration}
+
+	if opts.RunHooks && iteration > 0 {
+		hooksConfig, err := hooks.LoadConfig(cfg.WorkDir)
+		if err != nil {
+			return nil, err
+		}
+		if hooksConfig != nil {
+			vars := hooks.Variables{Session: cfg.SessionName}
+			pending, err := hooks.ExecuteAllPiped(ctx, hooksConfig.Hooks.SessionStart, cfg.WorkDir, vars)
+			if err != nil {
+				return nil, err
+			}
+			vars.Iteration = strconv.Itoa(iteration)
+			output, err := hooks.ExecuteAllPiped(ctx, hooksConfig.Hooks.PreIteration, cfg.WorkDir, vars)
+			if err != nil {
+				return nil, err
+			}
+			// Same order as the build loop: pending output first
+			preview.HookOutput = output
+			if pending != "" && output != "" {
+				preview.HookOutput = pending + "\n" + output
+			} else if pending != "" {
+				preview.HookOutput = pending
+			}
+		}
+	}
+
+	var err error
+	if preview.Prompt, preview.Report, err = o.renderPrompt(iteration); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
@@ -0,0 +1,167 @@
+package orchestrator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/template"
+)
+
+// TestReportPromptTrim checks that trimming is reported when the trimmed
+// sections change, not on every iteration.
+func TestReportPromptTrim(t *testing.T) {
+	var out bytes.Buffer
+	o := &Orchestrator{cfg: Config{Headless: true}, out: &out}
+
+	o.reportPromptTrim(1, template.PromptReport{Tokens: 900, Budget: 1000})
+	trimmed := template.PromptReport{Tokens: 990, Budget: 1000, Trimmed: []string{"tasks: 12 completed/cancelled tasks only counted", "notes: 3 of 13 older notes omitted"}}
+	o.reportPromptTrim(2, trimmed)
+	trimmed.Trimmed[1] = "notes: 4 of 14 older notes omitted"
+	o.reportPromptTrim(3, trimmed)
+	o.reportPromptTrim(4, template.PromptReport{Tokens: 1000, Budget: 1000, Trimmed: []string{"spec: cut to 10 of 20 bytes"}})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		"Prompt for iteration #2 trimmed to ~990 tokens (budget 1000): tasks, notes",
+		"Prompt for iteration #4 trimmed to ~1000 tokens (budget 1000): spec",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output = %q, want %q", lines, want)
+	}
+}
+
+func TestNextIteration(t *testing.T) {
+	tests := []struct {
+		name  string
+		state *session.State
+		want  int
+	}{
+		{"fresh session plans first", &session.State{}, 0},
+		{"imported tasks skip planning", &session.State{Tasks: map[string]*session.Task{"TAS-1": {ID: "TAS-1"}}}, 1},
+		{"after planning", &session.State{Iterations: []*session.Iteration{{Number: 0}}}, 1},
+		{"resumed", &session.State{Iterations: []*session.Iteration{{Number: 0}, {Number: 1}, {Number: 2}}}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextIteration(tt.state); got != tt.want {
+				t.Errorf("NextIteration() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSavePrompt(t *testing.T) {
+	dataDir := t.TempDir()
+	for i := 0; i <= savedPrompts+2; i++ {
+		if err := SavePrompt(dataDir, "demo", i, "prompt "+strings.Repeat("#", i)); err != nil {
+			t.Fatalf("SavePrompt(%d) failed: %v", i, err)
+		}
+	}
+
+	got, err := LoadPrompt(dataDir, "demo", savedPrompts+2)
+	if err != nil || got != "prompt "+strings.Repeat("#", savedPrompts+2) {
+		t.Errorf("LoadPrompt() = %q, %v", got, err)
+	}
+	if _, err := LoadPrompt(dataDir, "demo", 2); err == nil {
+		t.Error("expected old prompts to be pruned")
+	}
+	entries, _ := os.ReadDir(promptDir(dataDir, "demo"))
+	if len(entries) != savedPrompts {
+		t.Errorf("kept %d prompts, want %d", len(entries), savedPrompts)
+	}
+}
+
+// TestPreviewPrompt checks that the preview renders the next iteration's
+// prompt with hook output, and that build saved the prompts it sent.
+func TestPreviewPrompt(t *testing.T) {
+	tmpDir := t.TempDir()
+	specPath := filepath.Join(tmpDir, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Preview spec\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	scriptPath := filepath.Join(tmpDir, "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: First task}, {content: Second task}]}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+	hooksYAML := `version: 1
+hooks:
+  pre_iteration:
+    - command: "echo PRE_HOOK_{{iteration}}"
+      pipe_output: true
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".iteratr.hooks.yml"), []byte(hooksYAML), 0644); err != nil {
+		t.Fatalf("failed to write hooks config: %v", err)
+	}
+
+	cfg := Config{
+		SessionName:  "test-preview",
+		SpecPath:     specPath,
+		Iterations:   1,
+		DataDir:      filepath.Join(tmpDir, ".iteratr"),
+		WorkDir:      tmpDir,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+	}
+	orch, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	t.Cleanup(func() { _ = orch.Stop() })
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	// Build saved the planning prompt and iteration #1's prompt
+	for i := 0; i <= 1; i++ {
+		if _, err := LoadPrompt(cfg.DataDir, cfg.SessionName, i); err != nil {
+			t.Errorf("LoadPrompt(%d) failed: %v", i, err)
+		}
+	}
+
+	preview, err := PreviewPrompt(orch.ctx, orch.store, cfg, PreviewOptions{Iteration: -1, RunHooks: true})
+	if err != nil {
+		t.Fatalf("PreviewPrompt() failed: %v", err)
+	}
+	if preview.Iteration != 2 {
+		t.Errorf("Iteration = %d, want 2", preview.Iteration)
+	}
+	if strings.TrimSpace(preview.HookOutput) != "PRE_HOOK_2" {
+		t.Errorf("HookOutput = %q, want PRE_HOOK_2", preview.HookOutput)
+	}
+	for _, want := range []string{"Iteration: #2", "# Preview spec", "[TAS-1] First task", "[TAS-2] Second task"} {
+		if !strings.Contains(preview.Prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+
+	// Previewing does not save anything
+	if _, err := LoadPrompt(cfg.DataDir, cfg.SessionName, 2); err == nil {
+		t.Error("preview should not save a prompt")
+	}
+
+	planning, err := PreviewPrompt(orch.ctx, orch.store, cfg, PreviewOptions{Iteration: 0, RunHooks: true})
+	if err != nil {
+		t.Fatalf("PreviewPrompt(0) failed: %v", err)
+	}
+	if planning.HookOutput != "" || !strings.Contains(planning.Prompt, "Iteration #0") {
+		t.Errorf("iteration #0 preview should be the hook-free planning prompt, got hooks %q", planning.HookOutput)
+	}
+}
@@ -0,0 +1,157 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// TestReplayBackendEndToEnd runs the real iteration loop headless with the
+// replay backend: planning, a working iteration that edits a file and updates
+// tasks through the MCP server, and session completion.
+func TestReplayBackendEndToEnd(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, ".iteratr")
+
+	specPath := filepath.Join(tmpDir, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] Write hello.txt\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+
+	scriptPath := filepath.Join(tmpDir, "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: Write hello.txt}]}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: in_progress}
+      - edit: {path: hello.txt, content: "hello\n"}
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: iteration-summary
+        input: {summary: Wrote hello.txt}
+      - tool: session-complete
+      - finish: {input_tokens: 1000000, output_tokens: 100000}
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "test-replay",
+		SpecPath:     specPath,
+		Iterations:   3,
+		DataDir:      dataDir,
+		WorkDir:      tmpDir,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+		Prices:       session.PriceTable{"test": {Input: 3, Output: 15}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	state, err := orch.store.LoadState(orch.ctx, "test-replay")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if !state.Complete {
+		t.Error("expected session to be marked complete")
+	}
+	if len(state.Iterations) != 2 {
+		t.Fatalf("expected 2 iterations (planning + 1), got %d", len(state.Iterations))
+	}
+	if state.Iterations[1].Summary != "Wrote hello.txt" || !state.Iterations[1].Complete {
+		t.Errorf("iteration #1 = %+v, want completed with summary", state.Iterations[1])
+	}
+	if usage := state.Iterations[1].Usage; usage.InputTokens != 1000000 || usage.OutputTokens != 100000 {
+		t.Errorf("iteration #1 usage = %+v, want scripted token counts", usage)
+	}
+	if cost := state.Usage.Cost; cost < 4.49 || cost > 4.51 {
+		t.Errorf("session cost = %v, want 4.50 from the price table", cost)
+	}
+	task, ok := state.Tasks["TAS-1"]
+	if !ok || task.Status != "completed" {
+		t.Errorf("TAS-1 = %+v, want completed", task)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "hello.txt"))
+	if err != nil || string(data) != "hello\n" {
+		t.Errorf("hello.txt = %q, %v", data, err)
+	}
+}
+
+// TestImportedTasksSkipPlanning checks that a fresh session whose tasks were
+// imported starts at iteration #1: the script has no planning turn.
+func TestImportedTasksSkipPlanning(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	specPath := filepath.Join(tmpDir, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] Write hello.txt\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	scriptPath := filepath.Join(tmpDir, "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "test-import",
+		SpecPath:     specPath,
+		Iterations:   1,
+		DataDir:      filepath.Join(tmpDir, ".iteratr"),
+		WorkDir:      tmpDir,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+
+	if _, err := orch.store.ImportTasks(orch.ctx, "test-import", []session.ImportTask{
+		{Content: "Write hello.txt", Status: "remaining", Priority: 2},
+	}); err != nil {
+		t.Fatalf("ImportTasks() error: %v", err)
+	}
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	state, err := orch.store.LoadState(orch.ctx, "test-import")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if len(state.Iterations) != 1 || state.Iterations[0].Number != 1 {
+		t.Fatalf("iterations = %+v, want only iteration #1", state.Iterations)
+	}
+	if !state.Complete {
+		t.Error("expected session to be marked complete")
+	}
+}
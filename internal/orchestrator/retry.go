@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// DefaultIterationRetry is the retry policy for transient agent failures
+// when Config.Retry is not set. Provider rate limits usually clear within
+// a minute, so waits are much longer than ierr.DefaultRetryConfig.
+func DefaultIterationRetry() ierr.RetryConfig {
+	return ierr.RetryConfig{
+		MaxAttempts: 4,
+		InitialWait: 5 * time.Second,
+		MaxWait:     time.Minute,
+		Multiplier:  2.0,
+	}
+}
+
+// runIteration runs one agent iteration under the timeout watchdog, retrying
+// with backoff while it fails for a transient reason (see agent.IsRetryable).
+// Each retry reruns the whole iteration with the same prompt. Non-retryable
+// errors are returned unchanged; exhausted retries return the last error
+// wrapped with the attempt count.
+func (o *Orchestrator) runIteration(iteration int, prompt, hookOutput string) error {
+	retry := o.cfg.Retry
+	if retry.MaxAttempts <= 0 {
+		retry = DefaultIterationRetry()
+	}
+	retry.OnRetry = func(attempt int, wait time.Duration, err error) {
+		logger.Warn("Iteration #%d attempt %d/%d failed with transient error, retrying in %s: %v",
+			iteration, attempt, retry.MaxAttempts, wait, err)
+		if o.cfg.Headless {
+			fmt.Fprintf(o.out, "\n↻ Iteration #%d failed (%v), retrying in %s (attempt %d/%d)\n",
+				iteration, err, wait.Round(time.Millisecond), attempt+1, retry.MaxAttempts)
+		}
+		if o.tuiProgram != nil {
+			o.tuiProgram.Send(tui.ShowToastMsg{
+				Text: fmt.Sprintf("Provider error, retrying in %s (attempt %d/%d)", wait.Round(time.Second), attempt+1, retry.MaxAttempts),
+			})
+		}
+	}
+
+	err := ierr.Retry(o.ctx, retry, func() error {
+		err := o.runWatched(func(ctx context.Context) error {
+			return ierr.Recover(func() error {
+				return o.runner.RunIteration(ctx, prompt, hookOutput)
+			})
+		})
+		if err != nil && (o.ctx.Err() != nil || !agent.IsRetryable(err)) {
+			return ierr.NewPermanentError("iteration", err)
+		}
+		return err
+	})
+
+	// Retry marks non-retryable errors permanent; hand back the original error
+	var permErr *ierr.PermanentError
+	if errors.As(err, &permErr) {
+		return permErr.Err
+	}
+	return err
+}
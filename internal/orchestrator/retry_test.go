@@ -0,0 +1,162 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+)
+
+// newRetryTestOrchestrator starts a headless orchestrator that plays script
+// with the replay backend and a fast retry policy.
+func newRetryTestOrchestrator(t *testing.T, session, script string) *Orchestrator {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	specPath := filepath.Join(tmpDir, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] One\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	scriptPath := filepath.Join(tmpDir, "replay.yml")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  session,
+		SpecPath:     specPath,
+		Iterations:   3,
+		DataDir:      filepath.Join(tmpDir, ".iteratr"),
+		WorkDir:      tmpDir,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+		Retry:        ierr.RetryConfig{MaxAttempts: 3, InitialWait: 10 * time.Millisecond, MaxWait: 10 * time.Millisecond, Multiplier: 2},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	t.Cleanup(func() { _ = orch.Stop() })
+	return orch
+}
+
+// TestTransientErrorRetriesIteration checks that a rate-limited iteration is
+// rerun under the same iteration number and the session carries on.
+func TestTransientErrorRetriesIteration(t *testing.T) {
+	orch := newRetryTestOrchestrator(t, "test-retry", `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - finish: {error: "429 Too Many Requests: rate limit exceeded"}
+  - steps:
+      - finish: {error: "anthropic: Overloaded"}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`)
+
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	state, err := orch.store.LoadState(orch.ctx, "test-retry")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if !state.Complete {
+		t.Error("expected session to be completed after retries")
+	}
+	if len(state.Iterations) != 2 || !state.Iterations[1].Complete {
+		t.Errorf("expected planning + one completed iteration, got %d iterations", len(state.Iterations))
+	}
+}
+
+// TestTransientErrorRetriesPlanning checks that iteration #0 gets the same
+// retries as the main loop instead of failing the build on a rate limit.
+func TestTransientErrorRetriesPlanning(t *testing.T) {
+	orch := newRetryTestOrchestrator(t, "test-retry-plan", `
+iterations:
+  - steps:
+      - finish: {error: "429 Too Many Requests: rate limit exceeded"}
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`)
+
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	state, err := orch.store.LoadState(orch.ctx, "test-retry-plan")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if !state.Complete {
+		t.Error("expected session to be completed after the planning retry")
+	}
+	if len(state.Iterations) != 2 || !state.Iterations[0].Complete || len(state.Tasks) != 1 {
+		t.Errorf("expected completed planning + one iteration with 1 task, got %d iterations and %d tasks",
+			len(state.Iterations), len(state.Tasks))
+	}
+}
+
+// TestPermanentErrorIsNotRetried checks that a non-transient failure without
+// on_error hooks ends the session after a single attempt.
+func TestPermanentErrorIsNotRetried(t *testing.T) {
+	orch := newRetryTestOrchestrator(t, "test-no-retry", `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - finish: {error: "invalid x-api-key"}
+  - steps:
+      - tool: session-complete
+`)
+
+	err := orch.Run()
+	if err == nil || !strings.Contains(err.Error(), "invalid x-api-key") {
+		t.Fatalf("Run() = %v, want invalid api key error", err)
+	}
+	if strings.Contains(err.Error(), "retry") {
+		t.Errorf("Run() = %v, want no retry wrapping for permanent errors", err)
+	}
+}
+
+// TestExhaustedRetriesFailIteration checks that the last transient error is
+// reported once every attempt has failed.
+func TestExhaustedRetriesFailIteration(t *testing.T) {
+	orch := newRetryTestOrchestrator(t, "test-retry-exhausted", `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - finish: {error: "service unavailable"}
+  - steps:
+      - finish: {error: "service unavailable"}
+  - steps:
+      - finish: {error: "service unavailable"}
+  - steps:
+      - tool: session-complete
+`)
+
+	err := orch.Run()
+	if err == nil || !strings.Contains(err.Error(), "retry failed after 3 attempts") {
+		t.Fatalf("Run() = %v, want exhausted retries", err)
+	}
+}
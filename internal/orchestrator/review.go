@@ -0,0 +1,233 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// reviewDecision is a reviewer's verdict on an iteration's changes.
+type reviewDecision struct {
+	action   string // tui.ReviewApprove, tui.ReviewReject, or tui.ReviewAnnotate
+	feedback string // Optional message for the agent's next iteration
+}
+
+// SubmitReview delivers the decision for the pending review (TUI review modal).
+func (o *Orchestrator) SubmitReview(action, feedback string) {
+	select {
+	case o.reviewChan <- reviewDecision{action: action, feedback: feedback}:
+	default:
+		logger.Warn("Review decision already pending, dropped %q", action)
+	}
+}
+
+// resetFileTracking clears the file tracker and watcher for a new iteration.
+// Files held back by a review stay tracked, so they are reviewed and
+// committed together with the next iteration's changes.
+func (o *Orchestrator) resetFileTracking() {
+	o.fileTracker.Clear()
+	if o.fileWatcher != nil {
+		o.fileWatcher.Clear()
+	}
+	if len(o.heldPaths) > 0 {
+		o.fileTracker.MergeWatcherPaths(o.heldPaths)
+	}
+}
+
+// reviewIteration gates auto-commit on a review of the iteration's changes
+// (Config.Review). The TUI asks the user; headless runs ask the review hooks
+// and approve if none are configured. Rejected iterations are rolled back,
+// annotated ones keep their changes uncommitted, and any feedback is sent
+// with the next iteration's prompt. Returns true if the changes may be
+// committed.
+func (o *Orchestrator) reviewIteration(iteration int) bool {
+	paths := o.fileTracker.ModifiedPaths()
+	var diff string
+	if isGitRepo(o.cfg.WorkDir) {
+		d, err := git.Diff(o.cfg.WorkDir, paths)
+		if err != nil {
+			logger.Warn("Failed to diff iteration #%d for review: %v", iteration, err)
+		}
+		diff = d
+	}
+
+	var decision reviewDecision
+	var ok bool
+	if o.tuiProgram != nil {
+		decision, ok = o.waitForReview(iteration, paths, diff)
+	} else {
+		decision, ok = o.hookReview(iteration, diff)
+	}
+	if !ok {
+		// Cancelled while waiting: leave the changes uncommitted
+		return false
+	}
+
+	logger.Info("Review of iteration #%d: %s", iteration, decision.action)
+	if o.cfg.Headless {
+		fmt.Fprintf(o.out, "Review of iteration #%d: %s\n", iteration, decision.action)
+	}
+
+	switch decision.action {
+	case tui.ReviewReject:
+		var outcome string
+		report, err := o.rejectIteration(iteration)
+		switch {
+		case err != nil:
+			logger.Warn("Failed to roll back rejected iteration #%d: %v", iteration, err)
+			o.heldPaths = paths
+			outcome = fmt.Sprintf("Reverting them failed (%v), so your file changes and task updates are still in place, uncommitted.", err)
+		case report.Files == nil:
+			o.heldPaths = paths
+			outcome = "The iteration's task updates were undone, but the working directory is not a git repository, so your file changes are still in place, uncommitted."
+		default:
+			outcome = "They were reverted and the iteration's task updates undone."
+		}
+		o.appendPendingOutput(fmt.Sprintf(
+			"[REVIEW - iteration #%d]\n"+
+				"The reviewer rejected your changes from iteration #%d. %s\n"+
+				"Take a different approach.%s",
+			iteration, iteration, outcome, feedbackSuffix(decision.feedback),
+		))
+		return false
+
+	case tui.ReviewAnnotate:
+		o.heldPaths = paths
+		o.appendPendingOutput(fmt.Sprintf(
+			"[REVIEW - iteration #%d]\n"+
+				"The reviewer kept your changes from iteration #%d uncommitted and asks for revisions.%s",
+			iteration, iteration, feedbackSuffix(decision.feedback),
+		))
+		// A reviewer asking for revisions means the session is not done yet
+		if state, err := o.store.LoadState(o.ctx, o.cfg.SessionName); err == nil && state.Complete {
+			if err := o.store.SessionRestart(o.ctx, o.cfg.SessionName); err != nil {
+				logger.Warn("Failed to restart session after review feedback: %v", err)
+			}
+		}
+		return false
+	}
+
+	o.heldPaths = nil
+	if decision.feedback != "" {
+		o.appendPendingOutput(fmt.Sprintf(
+			"[REVIEW - iteration #%d]\n"+
+				"The reviewer approved your changes from iteration #%d.%s",
+			iteration, iteration, feedbackSuffix(decision.feedback),
+		))
+	}
+	return true
+}
+
+// feedbackSuffix formats reviewer feedback for the agent, or returns "" if
+// there is none.
+func feedbackSuffix(feedback string) string {
+	if feedback == "" {
+		return ""
+	}
+	return "\n\nReviewer feedback:\n" + feedback
+}
+
+// waitForReview shows the iteration's diff in the TUI and blocks until the
+// user decides. Returns false if the session is cancelled first.
+func (o *Orchestrator) waitForReview(iteration int, paths []string, diff string) (reviewDecision, bool) {
+	// Drop decisions sent while no review was pending
+	select {
+	case <-o.reviewChan:
+	default:
+	}
+
+	logger.Info("Waiting for review of iteration #%d (%d files)", iteration, len(paths))
+	o.tuiProgram.Send(tui.ReviewRequestMsg{Iteration: iteration, Files: paths, Diff: diff})
+
+	select {
+	case decision := <-o.reviewChan:
+		return decision, true
+	case <-o.tuiDone:
+		return reviewDecision{}, false
+	case <-o.ctx.Done():
+		return reviewDecision{}, false
+	}
+}
+
+// hookReview runs the review hooks with the diff on stdin. Exit code 0
+// approves, 3 rejects, and anything else (2, a failing script, a crash, or a
+// timeout) keeps the changes uncommitted; reject wins over annotate when
+// several hooks disagree. Hook output becomes the feedback. Returns false on
+// cancellation.
+func (o *Orchestrator) hookReview(iteration int, diff string) (reviewDecision, bool) {
+	decision := reviewDecision{action: tui.ReviewApprove}
+	if o.hooksConfig == nil || len(o.hooksConfig.Hooks.Review) == 0 {
+		return decision, true
+	}
+
+	hookVars := hooks.Variables{
+		Session:   o.cfg.SessionName,
+		Iteration: strconv.Itoa(iteration),
+	}
+	var feedback []string
+	for _, hook := range o.hooksConfig.Hooks.Review {
+		output, exitCode, err := hooks.ExecuteReview(o.ctx, hook, o.cfg.WorkDir, hookVars, diff)
+		if err != nil {
+			return reviewDecision{}, false
+		}
+		output = strings.TrimSpace(output)
+		switch exitCode {
+		case hooks.ReviewExitApprove:
+		case hooks.ReviewExitReject:
+			decision.action = tui.ReviewReject
+		default:
+			if exitCode != hooks.ReviewExitAnnotate {
+				// A failing hook is not a verdict: hold the changes, never revert them
+				logger.Warn("Review hook exited with code %d, keeping iteration #%d uncommitted: %s", exitCode, iteration, hook.Command)
+				output = strings.TrimSpace(fmt.Sprintf("[Review hook failed with exit code %d]\n%s", exitCode, output))
+			}
+			if decision.action != tui.ReviewReject {
+				decision.action = tui.ReviewAnnotate
+			}
+		}
+		if output != "" {
+			feedback = append(feedback, output)
+		}
+	}
+	decision.feedback = strings.Join(feedback, "\n\n")
+	return decision, true
+}
+
+// rejectIteration rolls back a rejected iteration: its files are restored from
+// the checkpoint taken before it started and its task and note changes are
+// undone. Rejecting the planning iteration rolls the session back to before
+// it (see session.RollbackSession). The report's Files is nil when the
+// working directory is not a git repository and only the events were undone.
+func (o *Orchestrator) rejectIteration(iteration int) (*RollbackReport, error) {
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session state: %w", err)
+	}
+	prev := -1
+	for _, iter := range state.Iterations {
+		if !iter.RolledBack && iter.Number < iteration {
+			prev = iter.Number
+		}
+	}
+
+	report, err := Rollback(o.ctx, o.store, RollbackOptions{
+		Session:     o.cfg.SessionName,
+		ToIteration: prev,
+		WorkDir:     o.cfg.WorkDir,
+		DataDir:     o.cfg.DataDir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if o.tuiProgram != nil {
+		if newState, err := o.store.LoadState(o.ctx, o.cfg.SessionName); err == nil {
+			o.tuiProgram.Send(tui.StateUpdateMsg{State: newState})
+		}
+	}
+	return report, nil
+}
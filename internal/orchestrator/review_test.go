@@ -0,0 +1,224 @@
+package orchestrator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHeadlessReviewHooks(t *testing.T) {
+	repo := t.TempDir()
+	runGitT(t, repo, "init", "-q", "-b", "main")
+	runGitT(t, repo, "config", "user.email", "test@example.com")
+	runGitT(t, repo, "config", "user.name", "Test")
+
+	// The review hook saves each diff and annotates iteration 1, rejects
+	// iteration 2, fails like a broken script on iteration 3, and approves
+	// the rest
+	diffDir := t.TempDir()
+	hooksYAML := `version: 1
+hooks:
+  review:
+    - command: |
+        cat > ` + diffDir + `/{{iteration}}.diff
+        case {{iteration}} in
+          1) echo "Add a doc comment"; exit 2 ;;
+          2) echo "Wrong file"; exit 3 ;;
+          3) exit 1 ;;
+        esac
+      timeout: 10
+`
+	if err := os.WriteFile(filepath.Join(repo, ".iteratr.hooks.yml"), []byte(hooksYAML), 0644); err != nil {
+		t.Fatalf("failed to write hooks config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".gitignore"), []byte(".iteratr/\n"), 0644); err != nil {
+		t.Fatalf("failed to write .gitignore: %v", err)
+	}
+	runGitT(t, repo, "add", "-A")
+	runGitT(t, repo, "commit", "-q", "-m", "initial")
+
+	specPath := filepath.Join(t.TempDir(), "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] Greeting\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	script := `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: Add greeting}]}
+  - steps:
+      - edit: {path: greet.go, content: "package main\n"}
+      - tool: iteration-summary
+        input: {summary: "Added greet.go"}
+  - steps:
+      - edit: {path: junk.txt, content: "junk\n"}
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+  - steps:
+      - edit: {path: greet.go, content: "// Package main greets.\npackage main\n"}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`
+	scriptPath := filepath.Join(t.TempDir(), "replay.yml")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "review",
+		SpecPath:     specPath,
+		Iterations:   4,
+		DataDir:      filepath.Join(repo, ".iteratr"),
+		WorkDir:      repo,
+		Headless:     true,
+		AutoCommit:   true,
+		CommitMode:   CommitModeNative,
+		Review:       true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	var out bytes.Buffer
+	orch.out = &out
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"Review of iteration #1: annotate",
+		"Review of iteration #2: reject",
+		"Review of iteration #3: annotate",
+		"Review of iteration #4: approve",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("headless output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	// The hook saw the held-back greet.go together with the rejected junk.txt
+	diff, err := os.ReadFile(filepath.Join(diffDir, "2.diff"))
+	if err != nil {
+		t.Fatalf("review hook did not run for iteration 2: %v", err)
+	}
+	if !strings.Contains(string(diff), "+++ b/greet.go") || !strings.Contains(string(diff), "+junk") {
+		t.Errorf("iteration 2 diff = %q, want greet.go and junk.txt", diff)
+	}
+
+	// Only the approved iteration committed, including the annotated file
+	if count := runGitT(t, repo, "rev-list", "--count", "HEAD"); count != "2" {
+		t.Errorf("commit count = %s, want 2 (initial + approved iteration)", count)
+	}
+	if files := runGitT(t, repo, "show", "--name-only", "--format=", "HEAD"); files != "greet.go" {
+		t.Errorf("committed files = %q, want greet.go", files)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "junk.txt")); !os.IsNotExist(err) {
+		t.Errorf("junk.txt from the rejected iteration still exists (err=%v)", err)
+	}
+
+	state, err := orch.store.LoadState(orch.ctx, "review")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	rolledBack := false
+	for _, iter := range state.Iterations {
+		if iter.Number == 2 && iter.RolledBack {
+			rolledBack = true
+		}
+	}
+	if !rolledBack {
+		t.Error("rejected iteration #2 was not rolled back")
+	}
+}
+
+func TestHeadlessReviewReject_NoGit(t *testing.T) {
+	workDir := t.TempDir()
+	hooksYAML := `version: 1
+hooks:
+  review:
+    - command: |
+        case {{iteration}} in
+          0) echo "Bad plan"; exit 3 ;;
+        esac
+      timeout: 10
+`
+	if err := os.WriteFile(filepath.Join(workDir, ".iteratr.hooks.yml"), []byte(hooksYAML), 0644); err != nil {
+		t.Fatalf("failed to write hooks config: %v", err)
+	}
+	specPath := filepath.Join(t.TempDir(), "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	script := `
+iterations:
+  - steps:
+      - edit: {path: plan.txt, content: "plan\n"}
+      - tool: task-add
+        input: {tasks: [{content: Planned task}]}
+  - steps:
+      - tool: session-complete
+`
+	scriptPath := filepath.Join(t.TempDir(), "replay.yml")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "review-nogit",
+		SpecPath:     specPath,
+		Iterations:   1,
+		DataDir:      filepath.Join(workDir, ".iteratr"),
+		WorkDir:      workDir,
+		Headless:     true,
+		Review:       true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	orch.out = &bytes.Buffer{}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	// Rejecting the planning iteration undoes its task updates
+	state, err := orch.store.LoadState(orch.ctx, "review-nogit")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if len(state.Tasks) != 0 {
+		t.Errorf("tasks after rejecting iteration #0 = %d, want 0", len(state.Tasks))
+	}
+	if len(state.Iterations) == 0 || state.Iterations[0].Number != 0 || !state.Iterations[0].RolledBack {
+		t.Error("rejected iteration #0 was not rolled back")
+	}
+
+	// Without git the files stay, and the agent is told so
+	if _, err := os.Stat(filepath.Join(workDir, "plan.txt")); err != nil {
+		t.Errorf("plan.txt should be kept outside a git repository: %v", err)
+	}
+	transcript, err := orch.store.LoadTranscript(orch.ctx, "review-nogit", 1)
+	if err != nil {
+		t.Fatalf("failed to load transcript: %v", err)
+	}
+	prompt := transcript.Entries[0].Text
+	if !strings.Contains(prompt, "not a git repository") || strings.Contains(prompt, "They were reverted") {
+		t.Errorf("iteration #1 prompt does not report the partial revert:\n%s", prompt)
+	}
+}
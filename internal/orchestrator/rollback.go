@@ -0,0 +1,185 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// RollbackOptions describes a session rollback.
+type RollbackOptions struct {
+	Session     string // Session to roll back
+	ToIteration int    // Last iteration to keep (-1 = none, see session.RollbackSession)
+	WorkDir     string // Working tree to restore (skipped if not in a git repository)
+	DataDir     string // Data directory, never touched by checkpoints or restores
+}
+
+// RollbackReport describes what a rollback changed.
+type RollbackReport struct {
+	Iterations []int              // Iterations that were rolled back
+	Files      *git.RestoreResult // Restored files (nil if WorkDir is not in a git repository)
+	SavedRef   string             // Ref holding the working tree as it was before the rollback
+}
+
+// preRollbackRef returns the ref that keeps a session's working tree from
+// just before its last rollback, so a mistaken rollback can be recovered.
+func preRollbackRef(sessionName string) string {
+	return git.CheckpointRefPrefix + sessionName + "/pre-rollback"
+}
+
+// checkpoint records the working tree before an iteration under
+// refs/iteratr/<session>/<iteration> so the iteration can be rolled back.
+// When an iteration number that is already recorded (and not rolled back)
+// runs again, its first checkpoint is kept: the tree now holds the failed
+// attempt's changes, not the state before the iteration.
+// Failures are logged and never stop the session.
+func (o *Orchestrator) checkpoint(iteration int) {
+	if !isGitRepo(o.cfg.WorkDir) {
+		return
+	}
+	ref := git.CheckpointRef(o.cfg.SessionName, iteration)
+	if o.iterationRecorded(iteration) && git.CheckpointExists(o.cfg.WorkDir, ref) {
+		logger.Debug("Keeping checkpoint %s from the first attempt of iteration #%d", ref, iteration)
+		return
+	}
+	if _, err := git.Checkpoint(o.cfg.WorkDir, ref, o.cfg.DataDir); err != nil {
+		logger.Warn("Failed to record checkpoint %s: %v", ref, err)
+		return
+	}
+	logger.Debug("Recorded checkpoint %s", ref)
+}
+
+// iterationRecorded reports whether the session already has a kept (not
+// rolled back) iteration with this number.
+func (o *Orchestrator) iterationRecorded(iteration int) bool {
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return false
+	}
+	for _, iter := range state.Iterations {
+		if iter.Number == iteration && !iter.RolledBack {
+			return true
+		}
+	}
+	return false
+}
+
+// Rollback undoes the iterations of a session after ToIteration. The working
+// tree is restored from the checkpoint taken before the next kept iteration
+// started, then compensating events bring tasks, notes, and the completion
+// flag back to their state at the end of ToIteration. The working tree as it
+// was before the rollback is saved under refs/iteratr/<session>/pre-rollback.
+// The session must not be running an iteration while this happens.
+func Rollback(ctx context.Context, store *session.Store, opts RollbackOptions) (*RollbackReport, error) {
+	state, err := store.LoadState(ctx, opts.Session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session state: %w", err)
+	}
+
+	// The checkpoint of the first kept iteration after ToIteration holds the
+	// working tree as ToIteration left it
+	found := opts.ToIteration < 0
+	next := -1
+	for _, iter := range state.Iterations {
+		if iter.RolledBack {
+			continue
+		}
+		if iter.Number == opts.ToIteration {
+			found = true
+		}
+		if iter.Number > opts.ToIteration && next < 0 {
+			next = iter.Number
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("iteration %d not found in session '%s'", opts.ToIteration, opts.Session)
+	}
+	if next < 0 {
+		return nil, fmt.Errorf("no iterations after iteration %d to roll back", opts.ToIteration)
+	}
+
+	report := &RollbackReport{}
+	if isGitRepo(opts.WorkDir) {
+		report.SavedRef = preRollbackRef(opts.Session)
+		if _, err := git.Checkpoint(opts.WorkDir, report.SavedRef, opts.DataDir); err != nil {
+			return nil, fmt.Errorf("failed to save working tree before rollback: %w", err)
+		}
+		files, err := git.RestoreCheckpoint(opts.WorkDir, git.CheckpointRef(opts.Session, next), opts.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore files: %w", err)
+		}
+		report.Files = files
+	} else {
+		logger.Warn("%s is not a git repository, rolling back session events only", opts.WorkDir)
+	}
+
+	result, err := store.RollbackSession(ctx, session.RollbackParams{
+		Session:     opts.Session,
+		ToIteration: opts.ToIteration,
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Iterations = result.Iterations
+	return report, nil
+}
+
+// RollbackLastIteration rolls back the most recent kept iteration (TUI
+// ctrl+x u). It is only allowed while the loop is waiting (paused between
+// iterations or after session completion), never while the agent runs.
+// Returns a one-line summary for display.
+func (o *Orchestrator) RollbackLastIteration() (string, error) {
+	if !o.waiting.Load() {
+		return "", fmt.Errorf("pause first and wait for the iteration to finish")
+	}
+
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return "", fmt.Errorf("failed to load session state: %w", err)
+	}
+	var kept []*session.Iteration
+	for _, iter := range state.Iterations {
+		if !iter.RolledBack {
+			kept = append(kept, iter)
+		}
+	}
+	if len(kept) < 2 {
+		return "", fmt.Errorf("no iteration to roll back")
+	}
+	last := kept[len(kept)-1].Number
+
+	report, err := Rollback(o.ctx, o.store, RollbackOptions{
+		Session:     o.cfg.SessionName,
+		ToIteration: kept[len(kept)-2].Number,
+		WorkDir:     o.cfg.WorkDir,
+		DataDir:     o.cfg.DataDir,
+	})
+	if err != nil {
+		return "", err
+	}
+	logger.Info("Rolled back iteration #%d of session '%s'", last, o.cfg.SessionName)
+
+	if newState, err := o.store.LoadState(o.ctx, o.cfg.SessionName); err == nil {
+		if o.tuiProgram != nil {
+			o.tuiProgram.Send(tui.StateUpdateMsg{State: newState})
+		}
+		// A completed session waits for a message before resuming iterations
+		if state.Complete && !newState.Complete {
+			select {
+			case o.sendChan <- fmt.Sprintf("Iteration #%d was rolled back and its changes discarded. Continue with remaining tasks.", last):
+			default:
+				logger.Warn("sendChan full, rollback resume signal dropped")
+			}
+		}
+	}
+
+	summary := fmt.Sprintf("Rolled back iteration #%d", last)
+	if report.Files != nil {
+		summary += fmt.Sprintf(" (%d files restored, %d removed)", len(report.Files.Restored), len(report.Files.Removed))
+	}
+	return summary, nil
+}
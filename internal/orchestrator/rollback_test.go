@@ -0,0 +1,118 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/git"
+)
+
+// TestRollbackRestoresIteration runs a replay session whose second iteration
+// goes wrong, then rolls it back and checks files and session state.
+func TestRollbackRestoresIteration(t *testing.T) {
+	repo := t.TempDir()
+	runGitT(t, repo, "init", "-q", "-b", "main")
+	runGitT(t, repo, "config", "user.email", "test@example.com")
+	runGitT(t, repo, "config", "user.name", "Test")
+	runGitT(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	specPath := filepath.Join(t.TempDir(), "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] One\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	scriptPath := filepath.Join(t.TempDir(), "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - edit: {path: keep.txt, content: "good\n"}
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - edit: {path: keep.txt, content: "bad\n"}
+      - edit: {path: junk.txt, content: "junk\n"}
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	dataDir := filepath.Join(repo, ".iteratr")
+	orch, err := New(Config{
+		SessionName:  "rb",
+		SpecPath:     specPath,
+		Iterations:   3,
+		DataDir:      dataDir,
+		WorkDir:      repo,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	// A checkpoint was recorded before each iteration
+	runGitT(t, repo, "rev-parse", "--verify", git.CheckpointRef("rb", 0))
+	runGitT(t, repo, "rev-parse", "--verify", git.CheckpointRef("rb", 1))
+
+	report, err := Rollback(orch.ctx, orch.store, RollbackOptions{
+		Session:     "rb",
+		ToIteration: 0,
+		WorkDir:     repo,
+		DataDir:     dataDir,
+	})
+	if err != nil {
+		t.Fatalf("Rollback() error: %v", err)
+	}
+	if len(report.Iterations) != 1 || report.Iterations[0] != 1 {
+		t.Errorf("rolled back iterations = %v, want [1]", report.Iterations)
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(repo, "keep.txt")); string(got) != "good\n" {
+		t.Errorf("keep.txt = %q, want restored content", got)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "junk.txt")); !os.IsNotExist(err) {
+		t.Errorf("junk.txt should be removed: %v", err)
+	}
+	if _, err := os.Stat(dataDir); err != nil {
+		t.Errorf("data directory must survive the rollback: %v", err)
+	}
+	runGitT(t, repo, "rev-parse", "--verify", report.SavedRef)
+
+	state, err := orch.store.LoadState(orch.ctx, "rb")
+	if err != nil {
+		t.Fatalf("LoadState() error: %v", err)
+	}
+	if state.Complete {
+		t.Error("session should no longer be complete")
+	}
+	if task := state.Tasks["TAS-1"]; task == nil || task.Status != "remaining" {
+		t.Errorf("TAS-1 should be remaining again, got %+v", task)
+	}
+
+	// Nothing left to roll back after iteration 0
+	if _, err := Rollback(orch.ctx, orch.store, RollbackOptions{Session: "rb", ToIteration: 0, WorkDir: repo, DataDir: dataDir}); err == nil {
+		t.Error("expected error when no iterations follow")
+	}
+
+	// Rerunning a recorded iteration keeps the checkpoint of its first attempt
+	before := runGitT(t, repo, "rev-parse", git.CheckpointRef("rb", 0))
+	if err := os.WriteFile(filepath.Join(repo, "half.txt"), []byte("failed attempt\n"), 0644); err != nil {
+		t.Fatalf("failed to write half.txt: %v", err)
+	}
+	orch.checkpoint(0)
+	if after := runGitT(t, repo, "rev-parse", git.CheckpointRef("rb", 0)); after != before {
+		t.Errorf("checkpoint of iteration 0 was overwritten by a rerun: %s -> %s", before, after)
+	}
+}
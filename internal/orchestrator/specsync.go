@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// specSyncPath returns the spec file kept in sync with task status. In
+// worktree mode that is the worktree's copy, so the change lands on the
+// session branch.
+func (o *Orchestrator) specSyncPath() string {
+	path, err := filepath.Abs(o.cfg.SpecPath)
+	if err != nil {
+		return o.cfg.SpecPath
+	}
+	if o.repoDir == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(o.repoDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join(o.cfg.WorkDir, rel)
+	}
+	return path
+}
+
+// syncSpec checks off the spec's checklist items whose tasks are completed
+// (and unchecks reopened ones), see session.SyncChecklist. It only runs from
+// the iteration loop while no agent turn is in progress, so it never
+// overwrites edits the agent is making to the spec.
+func (o *Orchestrator) syncSpec() {
+	path := o.specSyncPath()
+	info, err := os.Stat(path)
+	if err != nil {
+		logger.Warn("Failed to sync spec %s: %v", path, err)
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logger.Warn("Failed to sync spec %s: %v", path, err)
+		return
+	}
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		logger.Warn("Failed to load session state for spec sync: %v", err)
+		return
+	}
+
+	synced, changed := session.SyncChecklist(content, state)
+	if !changed {
+		return
+	}
+	if err := os.WriteFile(path, synced, info.Mode().Perm()); err != nil {
+		logger.Warn("Failed to write spec %s: %v", path, err)
+		return
+	}
+	o.specSynced = true
+	logger.Debug("Synced task status into %s", path)
+}
+
+// trackSpecSync syncs the spec after the iteration's agent turns and, if it
+// was rewritten since the last call, adds it to the iteration's changes so
+// it is reviewed and committed with them.
+func (o *Orchestrator) trackSpecSync() {
+	if !o.cfg.SpecSync || o.cfg.SpecPath == "" {
+		return
+	}
+	o.syncSpec()
+
+	if !o.specSynced {
+		return
+	}
+	o.specSynced = false
+	workDir, err := filepath.Abs(o.cfg.WorkDir)
+	if err != nil {
+		return
+	}
+	if rel, err := filepath.Rel(workDir, o.specSyncPath()); err == nil && !strings.HasPrefix(rel, "..") {
+		o.fileTracker.MergeWatcherPaths([]string{rel})
+	}
+}
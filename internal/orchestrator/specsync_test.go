@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpecSync(t *testing.T) {
+	repo := t.TempDir()
+	runGitT(t, repo, "init", "-q", "-b", "main")
+	runGitT(t, repo, "config", "user.email", "test@example.com")
+	runGitT(t, repo, "config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(repo, ".gitignore"), []byte(".iteratr/\n"), 0644); err != nil {
+		t.Fatalf("failed to write .gitignore: %v", err)
+	}
+	specPath := filepath.Join(repo, "spec.md")
+	spec := "# Spec\n\n## Tasks\n- [ ] Write hello.txt\n- [ ] Write docs\n"
+	if err := os.WriteFile(specPath, []byte(spec), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	runGitT(t, repo, "add", "-A")
+	runGitT(t, repo, "commit", "-q", "-m", "initial")
+
+	script := `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: Write hello.txt}, {content: Write docs}]}
+  - steps:
+      - edit: {path: hello.txt, content: "hello\n"}
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-2, status: cancelled}
+      - tool: session-complete
+`
+	scriptPath := filepath.Join(t.TempDir(), "replay.yml")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "sync",
+		SpecPath:     specPath,
+		Iterations:   2,
+		DataDir:      filepath.Join(repo, ".iteratr"),
+		WorkDir:      repo,
+		Headless:     true,
+		AutoCommit:   true,
+		CommitMode:   CommitModeNative,
+		SpecSync:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	want := "# Spec\n\n## Tasks\n- [x] Write hello.txt <!-- iteratr:TAS-1 -->\n- [ ] Write docs <!-- iteratr:TAS-2 -->\n"
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		t.Fatalf("failed to read spec: %v", err)
+	}
+	if string(data) != want {
+		t.Errorf("spec =\n%s\nwant\n%s", data, want)
+	}
+
+	// Planning committed the links, iteration #1 the checked-off item along
+	// with its work; iteration #2 changed nothing
+	if files := runGitT(t, repo, "show", "--name-only", "--format=", "HEAD~1"); files != "spec.md" {
+		t.Errorf("iteration #0 commit files = %q, want spec.md", files)
+	}
+	if files := runGitT(t, repo, "show", "--name-only", "--format=", "HEAD"); files != "hello.txt\nspec.md" {
+		t.Errorf("iteration #1 commit files = %q, want hello.txt and spec.md", files)
+	}
+	if committed := runGitT(t, repo, "show", "HEAD:spec.md"); committed+"\n" != want {
+		t.Errorf("committed spec =\n%s\nwant\n%s", committed, want)
+	}
+}
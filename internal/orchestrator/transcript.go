@@ -0,0 +1,171 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// transcriptSaveTimeout bounds how long saving a transcript may take once
+// the orchestrator context is cancelled.
+const transcriptSaveTimeout = 5 * time.Second
+
+// recordTranscript wraps the agent callbacks in cfg so that text, thinking,
+// tool calls, and finish events are added to the current iteration's
+// transcript. Subagent callbacks are installed even when cfg has none.
+func (o *Orchestrator) recordTranscript(cfg *agent.BackendConfig) {
+	if o.cfg.TranscriptMax < 0 {
+		return
+	}
+	onText := cfg.OnText
+	cfg.OnText = func(text string) {
+		o.addTranscript(session.TranscriptEntry{Kind: session.TranscriptKindText, Text: text})
+		if onText != nil {
+			onText(text)
+		}
+	}
+	onThinking := cfg.OnThinking
+	cfg.OnThinking = func(content string) {
+		o.addTranscript(session.TranscriptEntry{Kind: session.TranscriptKindThinking, Text: content})
+		if onThinking != nil {
+			onThinking(content)
+		}
+	}
+	onToolCall := cfg.OnToolCall
+	cfg.OnToolCall = func(event agent.ToolCallEvent) {
+		o.addTranscript(session.TranscriptEntry{Kind: session.TranscriptKindTool, Tool: transcriptTool(event)})
+		if onToolCall != nil {
+			onToolCall(event)
+		}
+	}
+	onFinish := cfg.OnFinish
+	cfg.OnFinish = func(event agent.FinishEvent) {
+		usage, model := o.finishUsage(event)
+		o.addTranscript(session.TranscriptEntry{Kind: session.TranscriptKindFinish, Finish: &session.TranscriptFinish{
+			StopReason: event.StopReason,
+			Error:      event.Error,
+			Model:      model,
+			Provider:   event.Provider,
+			Duration:   event.Duration,
+			Usage:      usage,
+		}})
+		if onFinish != nil {
+			onFinish(event)
+		}
+	}
+	onSubagentText := cfg.OnSubagentText
+	cfg.OnSubagentText = func(toolCallID, text string) {
+		o.addTranscript(session.TranscriptEntry{Kind: session.TranscriptKindText, Subagent: toolCallID, Text: text})
+		if onSubagentText != nil {
+			onSubagentText(toolCallID, text)
+		}
+	}
+	onSubagentThinking := cfg.OnSubagentThinking
+	cfg.OnSubagentThinking = func(toolCallID, content string) {
+		o.addTranscript(session.TranscriptEntry{Kind: session.TranscriptKindThinking, Subagent: toolCallID, Text: content})
+		if onSubagentThinking != nil {
+			onSubagentThinking(toolCallID, content)
+		}
+	}
+	onSubagentToolCall := cfg.OnSubagentToolCall
+	cfg.OnSubagentToolCall = func(toolCallID string, event agent.ToolCallEvent) {
+		o.addTranscript(session.TranscriptEntry{Kind: session.TranscriptKindTool, Subagent: toolCallID, Tool: transcriptTool(event)})
+		if onSubagentToolCall != nil {
+			onSubagentToolCall(toolCallID, event)
+		}
+	}
+}
+
+// transcriptTool converts a tool call event for the transcript.
+func transcriptTool(event agent.ToolCallEvent) *session.TranscriptTool {
+	tool := &session.TranscriptTool{
+		ID:     event.ToolCallID,
+		Title:  event.Title,
+		Kind:   event.Kind,
+		Status: event.Status,
+		Input:  event.RawInput,
+		Output: event.Output,
+	}
+	if event.FileDiff != nil {
+		tool.Diff = &session.TranscriptDiff{
+			File:      event.FileDiff.File,
+			Before:    event.FileDiff.Before,
+			After:     event.FileDiff.After,
+			Additions: event.FileDiff.Additions,
+			Deletions: event.FileDiff.Deletions,
+		}
+	}
+	return tool
+}
+
+// addTranscript adds an entry to the transcript of the current iteration,
+// saving the previous iteration's transcript first if the agent moved on.
+func (o *Orchestrator) addTranscript(entry session.TranscriptEntry) {
+	iteration := int(o.iteration.Load())
+	o.transcriptMu.Lock()
+	defer o.transcriptMu.Unlock()
+	if o.transcript == nil || o.transcript.Iteration != iteration {
+		o.saveTranscriptLocked()
+		o.transcript = session.NewTranscript(o.cfg.SessionName, iteration, o.cfg.TranscriptMax)
+	}
+	o.transcript.Add(entry)
+}
+
+// saveTranscript stores the current iteration's transcript. It runs after
+// every agent turn, so a transcript survives an interrupted build up to the
+// last completed turn.
+func (o *Orchestrator) saveTranscript() {
+	o.transcriptMu.Lock()
+	defer o.transcriptMu.Unlock()
+	o.saveTranscriptLocked()
+}
+
+// saveTranscriptLocked is saveTranscript with transcriptMu held. Saving is
+// not cancelled with the orchestrator, so interrupted turns are kept too.
+func (o *Orchestrator) saveTranscriptLocked() {
+	if o.transcript == nil || o.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(o.ctx), transcriptSaveTimeout)
+	defer cancel()
+	if err := o.store.SaveTranscript(ctx, o.transcript); err != nil {
+		logger.Warn("Failed to save transcript for iteration #%d: %v", o.transcript.Iteration, err)
+	}
+}
+
+// transcriptRunner wraps the agent backend so that prompts and messages sent
+// to the agent are recorded in the transcript, which is saved after each turn.
+func (o *Orchestrator) transcriptRunner(runner agent.Backend) agent.Backend {
+	if o.cfg.TranscriptMax < 0 {
+		return runner
+	}
+	return &transcriptBackend{Backend: runner, o: o}
+}
+
+// transcriptBackend is an agent.Backend that records what it is sent.
+type transcriptBackend struct {
+	agent.Backend
+	o *Orchestrator
+}
+
+// RunIteration records the prompt, preceded by any hook output, and runs it.
+func (b *transcriptBackend) RunIteration(ctx context.Context, prompt string, hookOutput string) error {
+	text := prompt
+	if hookOutput != "" {
+		text = hookOutput + "\n\n" + prompt
+	}
+	b.o.addTranscript(session.TranscriptEntry{Kind: session.TranscriptKindPrompt, Text: text})
+	defer b.o.saveTranscript()
+	return b.Backend.RunIteration(ctx, prompt, hookOutput)
+}
+
+// SendMessages records the messages and sends them.
+func (b *transcriptBackend) SendMessages(ctx context.Context, texts []string) error {
+	b.o.addTranscript(session.TranscriptEntry{Kind: session.TranscriptKindPrompt, Text: strings.Join(texts, "\n\n")})
+	defer b.o.saveTranscript()
+	return b.Backend.SendMessages(ctx, texts)
+}
@@ -0,0 +1,119 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// TestTranscriptRecording runs a build with the replay backend and checks
+// that each iteration's prompt, agent output, tool calls, and finish event
+// end up in its stored transcript.
+func TestTranscriptRecording(t *testing.T) {
+	tmpDir := t.TempDir()
+	specPath := filepath.Join(tmpDir, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Transcript spec\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	scriptPath := filepath.Join(tmpDir, "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: Write hello.txt}]}
+  - steps:
+      - thinking: "Need a file"
+      - text: "Writing "
+      - text: "hello.txt"
+      - edit: {path: hello.txt, content: "hello\n"}
+      - finish: {input_tokens: 1000, output_tokens: 100}
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:   "test-transcript",
+		SpecPath:      specPath,
+		Iterations:    1,
+		DataDir:       filepath.Join(tmpDir, ".iteratr"),
+		WorkDir:       tmpDir,
+		Headless:      true,
+		Model:         "replay/test",
+		Backend:       "replay",
+		ReplayScript:  scriptPath,
+		TranscriptMax: session.DefaultTranscriptMaxBytes,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	t.Cleanup(func() { _ = orch.Stop() })
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	planning, err := orch.store.LoadTranscript(orch.ctx, "test-transcript", 0)
+	if err != nil {
+		t.Fatalf("LoadTranscript(0) failed: %v", err)
+	}
+	if len(planning.Entries) == 0 || planning.Entries[0].Kind != session.TranscriptKindPrompt {
+		t.Fatalf("planning transcript should start with the prompt, got %+v", planning.Entries)
+	}
+	var taskAdd *session.TranscriptTool
+	for _, entry := range planning.Entries {
+		if entry.Tool != nil && entry.Tool.Title == "task-add" {
+			taskAdd = entry.Tool
+		}
+	}
+	if taskAdd == nil || taskAdd.Status != "completed" || taskAdd.Input["tasks"] == nil {
+		t.Errorf("planning transcript should hold the completed task-add call, got %+v", taskAdd)
+	}
+
+	work, err := orch.store.LoadTranscript(orch.ctx, "test-transcript", 1)
+	if err != nil {
+		t.Fatalf("LoadTranscript(1) failed: %v", err)
+	}
+	byKind := map[string]session.TranscriptEntry{}
+	for _, entry := range work.Entries {
+		byKind[entry.Kind] = entry
+	}
+	if got := byKind[session.TranscriptKindThinking].Text; got != "Need a file" {
+		t.Errorf("thinking = %q, want %q", got, "Need a file")
+	}
+	if got := byKind[session.TranscriptKindText].Text; got != "Writing hello.txt" {
+		t.Errorf("text = %q, want the merged chunks", got)
+	}
+	if tool := byKind[session.TranscriptKindTool].Tool; tool == nil || tool.Diff == nil || tool.Diff.After != "hello\n" {
+		t.Errorf("edit tool call should carry its file diff, got %+v", tool)
+	}
+	finish := byKind[session.TranscriptKindFinish].Finish
+	if finish == nil || finish.Usage.InputTokens != 1000 || finish.Model == "" {
+		t.Errorf("finish = %+v, want usage and model", finish)
+	}
+
+	iterations, err := orch.store.TranscriptIterations(orch.ctx, "test-transcript")
+	if err != nil || len(iterations) != 2 {
+		t.Errorf("TranscriptIterations() = %v, %v, want [0 1]", iterations, err)
+	}
+}
+
+// TestTranscriptDisabled checks that a negative cap leaves the callbacks and
+// runner untouched.
+func TestTranscriptDisabled(t *testing.T) {
+	o := &Orchestrator{cfg: Config{TranscriptMax: -1}}
+	cfg := agent.BackendConfig{}
+	o.recordTranscript(&cfg)
+	if cfg.OnText != nil || cfg.OnToolCall != nil || cfg.OnFinish != nil {
+		t.Error("recordTranscript should not install callbacks when transcripts are off")
+	}
+	runner := &agent.ReplayAgent{}
+	if o.transcriptRunner(runner) != agent.Backend(runner) {
+		t.Error("transcriptRunner should return the runner unchanged when transcripts are off")
+	}
+}
@@ -0,0 +1,142 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// Bounds on how often the watchdog checks its limits.
+const (
+	minWatchdogInterval = 10 * time.Millisecond
+	maxWatchdogInterval = time.Second
+)
+
+// trackActivity wraps the agent callbacks in cfg so that every text, thinking,
+// tool, or file event resets the idle watchdog.
+func (o *Orchestrator) trackActivity(cfg *agent.BackendConfig) {
+	if onText := cfg.OnText; onText != nil {
+		cfg.OnText = func(text string) {
+			o.touchActivity()
+			onText(text)
+		}
+	}
+	if onThinking := cfg.OnThinking; onThinking != nil {
+		cfg.OnThinking = func(content string) {
+			o.touchActivity()
+			onThinking(content)
+		}
+	}
+	if onToolCall := cfg.OnToolCall; onToolCall != nil {
+		cfg.OnToolCall = func(event agent.ToolCallEvent) {
+			o.touchActivity()
+			onToolCall(event)
+		}
+	}
+	if onFileChange := cfg.OnFileChange; onFileChange != nil {
+		cfg.OnFileChange = func(change agent.FileChange) {
+			o.touchActivity()
+			onFileChange(change)
+		}
+	}
+	if onSubagentText := cfg.OnSubagentText; onSubagentText != nil {
+		cfg.OnSubagentText = func(toolCallID, text string) {
+			o.touchActivity()
+			onSubagentText(toolCallID, text)
+		}
+	}
+	if onSubagentToolCall := cfg.OnSubagentToolCall; onSubagentToolCall != nil {
+		cfg.OnSubagentToolCall = func(toolCallID string, event agent.ToolCallEvent) {
+			o.touchActivity()
+			onSubagentToolCall(toolCallID, event)
+		}
+	}
+	if onSubagentThinking := cfg.OnSubagentThinking; onSubagentThinking != nil {
+		cfg.OnSubagentThinking = func(toolCallID, content string) {
+			o.touchActivity()
+			onSubagentThinking(toolCallID, content)
+		}
+	}
+}
+
+// touchActivity records agent activity for the idle watchdog.
+func (o *Orchestrator) touchActivity() {
+	o.lastActivity.Store(time.Now().UnixNano())
+}
+
+// runWatched runs fn with a context that is cancelled when the iteration
+// exceeds cfg.IterationTimeout or the agent is idle for cfg.IdleTimeout.
+// If the watchdog fired, the returned error is the *ierr.TimeoutError that
+// caused the cancellation rather than the backend's cancellation error.
+// Cancelling the orchestrator context is not reported as a timeout.
+func (o *Orchestrator) runWatched(fn func(ctx context.Context) error) error {
+	iterationTimeout, idleTimeout := o.cfg.IterationTimeout, o.cfg.IdleTimeout
+	if iterationTimeout <= 0 && idleTimeout <= 0 {
+		return fn(o.ctx)
+	}
+
+	ctx, cancel := context.WithCancelCause(o.ctx)
+	defer cancel(nil)
+
+	// Check at a fraction of the shortest limit so timeouts fire promptly
+	interval := maxWatchdogInterval
+	for _, limit := range []time.Duration{iterationTimeout, idleTimeout} {
+		if limit > 0 {
+			interval = min(interval, limit/10)
+		}
+	}
+	interval = max(interval, minWatchdogInterval)
+
+	start := time.Now()
+	o.touchActivity()
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if iterationTimeout > 0 && now.Sub(start) >= iterationTimeout {
+					cancel(&ierr.TimeoutError{Kind: ierr.TimeoutIteration, Limit: iterationTimeout})
+					return
+				}
+				if idleTimeout > 0 && now.Sub(time.Unix(0, o.lastActivity.Load())) >= idleTimeout {
+					cancel(&ierr.TimeoutError{Kind: ierr.TimeoutIdle, Limit: idleTimeout})
+					return
+				}
+			}
+		}
+	}()
+
+	err := fn(ctx)
+	close(done)
+
+	var timeoutErr *ierr.TimeoutError
+	if err != nil && o.ctx.Err() == nil && errors.As(context.Cause(ctx), &timeoutErr) {
+		logger.Warn("Watchdog cancelled agent run: %v (backend returned: %v)", timeoutErr, err)
+		return timeoutErr
+	}
+	return err
+}
+
+// errorType classifies an iteration error for the {{error_type}} hook variable.
+func errorType(err error) string {
+	var timeoutErr *ierr.TimeoutError
+	var panicErr *ierr.PanicError
+	switch {
+	case errors.As(err, &timeoutErr):
+		return timeoutErr.Kind
+	case errors.As(err, &panicErr):
+		return "panic"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,221 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+)
+
+func TestRunWatched(t *testing.T) {
+	// blockUntilCancelled simulates a backend that runs until its context ends,
+	// reporting activity every tick when active is true.
+	blockUntilCancelled := func(o *Orchestrator, active bool) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			ticker := time.NewTicker(5 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-ticker.C:
+					if active {
+						o.touchActivity()
+					}
+				}
+			}
+		}
+	}
+
+	t.Run("no limits runs on orchestrator context", func(t *testing.T) {
+		o := &Orchestrator{ctx: context.Background()}
+		if err := o.runWatched(func(ctx context.Context) error { return nil }); err != nil {
+			t.Errorf("runWatched() = %v, want nil", err)
+		}
+	})
+
+	t.Run("iteration timeout", func(t *testing.T) {
+		o := &Orchestrator{ctx: context.Background(), cfg: Config{IterationTimeout: 100 * time.Millisecond, IdleTimeout: time.Hour}}
+		err := o.runWatched(blockUntilCancelled(o, true))
+		var timeoutErr *ierr.TimeoutError
+		if !errors.As(err, &timeoutErr) || timeoutErr.Kind != ierr.TimeoutIteration {
+			t.Errorf("runWatched() = %v, want iteration timeout", err)
+		}
+	})
+
+	t.Run("idle timeout", func(t *testing.T) {
+		o := &Orchestrator{ctx: context.Background(), cfg: Config{IterationTimeout: time.Hour, IdleTimeout: 100 * time.Millisecond}}
+		err := o.runWatched(blockUntilCancelled(o, false))
+		var timeoutErr *ierr.TimeoutError
+		if !errors.As(err, &timeoutErr) || timeoutErr.Kind != ierr.TimeoutIdle {
+			t.Errorf("runWatched() = %v, want idle timeout", err)
+		}
+		if errorType(err) != ierr.TimeoutIdle {
+			t.Errorf("errorType() = %q, want %q", errorType(err), ierr.TimeoutIdle)
+		}
+	})
+
+	t.Run("orchestrator cancellation is not a timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		o := &Orchestrator{ctx: ctx, cfg: Config{IdleTimeout: time.Hour}}
+		time.AfterFunc(20*time.Millisecond, cancel)
+		err := o.runWatched(blockUntilCancelled(o, false))
+		if !errors.Is(err, context.Canceled) || errors.Is(err, ierr.ErrTimeout) {
+			t.Errorf("runWatched() = %v, want context.Canceled", err)
+		}
+	})
+}
+
+// TestIdleTimeoutContinuesLoop runs the replay backend with an iteration that
+// stalls. The watchdog must cancel it, record the timeout, run on_error hooks
+// with the idle_timeout error type, and let the next iteration finish the session.
+func TestIdleTimeoutContinuesLoop(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, ".iteratr")
+
+	specPath := filepath.Join(tmpDir, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] One\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+
+	hooksYAML := `
+version: 1
+hooks:
+  on_error:
+    - command: "echo '{{iteration}} {{error_type}}' >> errors.txt"
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".iteratr.hooks.yml"), []byte(hooksYAML), 0644); err != nil {
+		t.Fatalf("failed to write hooks file: %v", err)
+	}
+
+	scriptPath := filepath.Join(tmpDir, "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - text: thinking about it
+      - delay: 1h
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "test-watchdog",
+		SpecPath:     specPath,
+		Iterations:   5,
+		DataDir:      dataDir,
+		WorkDir:      tmpDir,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+		IdleTimeout:  200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+
+	if err := orch.Run(); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	state, err := orch.store.LoadState(orch.ctx, "test-watchdog")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if !state.Complete {
+		t.Error("expected session to be completed by the iteration after the timeout")
+	}
+	if len(state.Iterations) != 3 {
+		t.Fatalf("expected 3 iterations, got %d", len(state.Iterations))
+	}
+	stalled := state.Iterations[1]
+	if stalled.Number != 1 || !stalled.TimedOut || stalled.TimeoutKind != ierr.TimeoutIdle || stalled.Complete {
+		t.Errorf("iteration #1 = %+v, want idle timeout", stalled)
+	}
+	if next := state.Iterations[2]; next.Number != 2 || !next.Complete {
+		t.Errorf("iteration #2 = %+v, want complete", next)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "errors.txt"))
+	if err != nil {
+		t.Fatalf("on_error hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "1 idle_timeout" {
+		t.Errorf("on_error hook output = %q, want %q", got, "1 idle_timeout")
+	}
+}
+
+// TestIdleTimeoutPlanningIteration checks that iteration #0 runs under the
+// watchdog too: a stalled planning run is cancelled and recorded as timed out.
+func TestIdleTimeoutPlanningIteration(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	specPath := filepath.Join(tmpDir, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] One\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	scriptPath := filepath.Join(tmpDir, "replay.yml")
+	script := `
+iterations:
+  - steps:
+      - text: planning
+      - delay: 1h
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  "test-watchdog-plan",
+		SpecPath:     specPath,
+		Iterations:   3,
+		DataDir:      filepath.Join(tmpDir, ".iteratr"),
+		WorkDir:      tmpDir,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+		IdleTimeout:  200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	defer func() { _ = orch.Stop() }()
+
+	err = orch.Run()
+	var timeoutErr *ierr.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Kind != ierr.TimeoutIdle {
+		t.Fatalf("Run() = %v, want idle timeout", err)
+	}
+
+	state, err := orch.store.LoadState(orch.ctx, "test-watchdog-plan")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if len(state.Iterations) != 1 {
+		t.Fatalf("expected 1 iteration, got %d", len(state.Iterations))
+	}
+	if plan := state.Iterations[0]; !plan.TimedOut || plan.TimeoutKind != ierr.TimeoutIdle || plan.Complete {
+		t.Errorf("iteration #0 = %+v, want idle timeout", plan)
+	}
+}
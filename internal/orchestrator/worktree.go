@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// worktreePath returns where a session's worktree is checked out:
+// <data_dir>/worktrees/<session>.
+func (o *Orchestrator) worktreePath() string {
+	return filepath.Join(o.cfg.DataDir, "worktrees", o.cfg.SessionName)
+}
+
+// setupWorktree creates (or reuses) the session's git worktree on branch
+// iteratr/<session> and points the agent, file tracking, hooks, and
+// auto-commit at it. The original checkout is left untouched.
+func (o *Orchestrator) setupWorktree() error {
+	wt, err := git.AddWorktree(o.cfg.WorkDir, o.worktreePath(), git.WorktreeBranch(o.cfg.SessionName))
+	if err != nil {
+		return err
+	}
+
+	o.repoDir = o.cfg.WorkDir
+	o.worktree = wt
+	o.cfg.WorkDir = wt.Path
+	o.fileTracker = agent.NewFileTracker(wt.Path)
+
+	// The data directory lives in the original checkout, not the worktree
+	if o.cfg.CommitDataDir {
+		logger.Warn("commit_data_dir is ignored in worktree mode (data directory is outside the worktree)")
+		o.cfg.CommitDataDir = false
+	}
+
+	verb := "Created"
+	if wt.Reused {
+		verb = "Reusing"
+	}
+	logger.Info("%s worktree %s on branch %s", verb, wt.Path, wt.Branch)
+	if o.cfg.Headless {
+		fmt.Fprintf(o.out, "%s worktree %s (branch %s)\n", verb, wt.Path, wt.Branch)
+	}
+	return nil
+}
+
+// finishWorktree runs at shutdown. Once the session is complete, a clean
+// worktree is removed so its branch can be checked out and reviewed from the
+// original checkout. Incomplete sessions and worktrees with uncommitted
+// changes are kept so the session can be resumed or the changes inspected.
+func (o *Orchestrator) finishWorktree() {
+	if o.worktree == nil {
+		return
+	}
+	wt := o.worktree
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	complete := false
+	if state, err := o.store.LoadState(ctx, o.cfg.SessionName); err == nil {
+		complete = state.Complete
+	} else {
+		logger.Warn("Failed to load session state for worktree cleanup: %v", err)
+	}
+
+	if !complete {
+		logger.Info("Keeping worktree %s for resume", wt.Path)
+		fmt.Fprintf(o.out, "Worktree kept at %s (branch %s) - resume with --worktree --name %s\n", wt.Path, wt.Branch, o.cfg.SessionName)
+		return
+	}
+
+	if err := git.RemoveWorktree(o.repoDir, wt.Path); err != nil {
+		logger.Warn("Failed to remove worktree %s: %v", wt.Path, err)
+		fmt.Fprintf(o.out, "Session complete, but worktree %s was kept: %v\n", wt.Path, err)
+		return
+	}
+	logger.Info("Removed worktree %s, branch %s ready for review", wt.Path, wt.Branch)
+	fmt.Fprintf(o.out, "Session complete: branch %s is ready for review (git log ..%s)\n", wt.Branch, wt.Branch)
+}
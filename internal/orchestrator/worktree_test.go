@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runGitT runs git in dir and fails the test on error.
+func runGitT(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// runWorktreeSession runs a headless replay session in worktree mode inside a
+// fresh git repository and returns the repository and worktree paths.
+func runWorktreeSession(t *testing.T, session, script string) (repo, worktree string) {
+	t.Helper()
+	repo = t.TempDir()
+	runGitT(t, repo, "init", "-q", "-b", "main")
+	runGitT(t, repo, "config", "user.email", "test@example.com")
+	runGitT(t, repo, "config", "user.name", "Test")
+	runGitT(t, repo, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	specPath := filepath.Join(repo, "spec.md")
+	if err := os.WriteFile(specPath, []byte("# Spec\n\n## Tasks\n- [ ] One\n"), 0644); err != nil {
+		t.Fatalf("failed to write spec file: %v", err)
+	}
+	scriptPath := filepath.Join(t.TempDir(), "replay.yml")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("failed to write replay script: %v", err)
+	}
+
+	orch, err := New(Config{
+		SessionName:  session,
+		SpecPath:     specPath,
+		Iterations:   3,
+		DataDir:      filepath.Join(repo, ".iteratr"),
+		WorkDir:      repo,
+		Headless:     true,
+		Model:        "replay/test",
+		Backend:      "replay",
+		ReplayScript: scriptPath,
+		Worktree:     true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if err := orch.Start(); err != nil {
+		t.Fatalf("failed to start orchestrator: %v", err)
+	}
+	worktree = orch.cfg.WorkDir
+	if worktree == repo {
+		t.Fatal("expected WorkDir to point at the worktree")
+	}
+	if err := orch.Run(); err != nil {
+		_ = orch.Stop()
+		t.Fatalf("Run() returned error: %v", err)
+	}
+	if err := orch.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	return repo, worktree
+}
+
+// TestWorktreeIsolatesAgentEdits checks that agent edits land in the session
+// worktree and that a worktree with uncommitted changes survives shutdown.
+func TestWorktreeIsolatesAgentEdits(t *testing.T) {
+	repo, worktree := runWorktreeSession(t, "wt-edit", `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - edit: {path: hello.txt, content: "hello\n"}
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`)
+
+	if _, err := os.Stat(filepath.Join(worktree, "hello.txt")); err != nil {
+		t.Errorf("expected hello.txt in worktree: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "hello.txt")); !os.IsNotExist(err) {
+		t.Errorf("hello.txt leaked into the original checkout: %v", err)
+	}
+	if got := runGitT(t, worktree, "rev-parse", "--abbrev-ref", "HEAD"); got != "iteratr/wt-edit" {
+		t.Errorf("worktree branch = %q, want iteratr/wt-edit", got)
+	}
+	if got := runGitT(t, repo, "rev-parse", "--abbrev-ref", "HEAD"); got != "main" {
+		t.Errorf("original checkout moved to branch %q", got)
+	}
+}
+
+// TestWorktreeRemovedWhenComplete checks that a clean worktree is removed when
+// the session completes, leaving its branch for review.
+func TestWorktreeRemovedWhenComplete(t *testing.T) {
+	repo, worktree := runWorktreeSession(t, "wt-clean", `
+iterations:
+  - steps:
+      - tool: task-add
+        input: {tasks: [{content: One}]}
+  - steps:
+      - tool: task-update
+        input: {id: TAS-1, status: completed}
+      - tool: session-complete
+`)
+
+	if _, err := os.Stat(worktree); !os.IsNotExist(err) {
+		t.Errorf("expected worktree to be removed after completion: %v", err)
+	}
+	runGitT(t, repo, "rev-parse", "--verify", "refs/heads/iteratr/wt-clean")
+}
@@ -0,0 +1,200 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// ArchiveVersion is the current version of the session archive format.
+// Bump when the header or event encoding changes incompatibly.
+const ArchiveVersion = 1
+
+// ArchiveHeader is the first line of a session archive.
+// The remaining lines are JSON-encoded Events, one per line, in stream order.
+type ArchiveHeader struct {
+	Version    int       `json:"iteratr_archive"` // Archive format version
+	Session    string    `json:"session"`         // Name of the exported session
+	ExportedAt time.Time `json:"exported_at"`     // When the archive was written
+	Events     int       `json:"events"`          // Number of event lines that follow
+}
+
+// ImportParams represents the parameters for importing a session archive.
+type ImportParams struct {
+	Name   string  // Target session name
+	Force  bool    // Replace the target session if it already has events
+	Events []Event // Events to replay, in order
+}
+
+// ExportSession writes every event of a session to w as a versioned JSONL archive.
+// Returns the number of events written.
+func (s *Store) ExportSession(ctx context.Context, session string, w io.Writer) (int, error) {
+	events, err := s.Events(ctx, session)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read session events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, fmt.Errorf("session not found: %s", session)
+	}
+
+	if err := WriteArchive(w, session, events); err != nil {
+		return 0, err
+	}
+
+	logger.Debug("Exported %d events for session '%s'", len(events), session)
+	return len(events), nil
+}
+
+// WriteArchive encodes a header followed by one event per line.
+func WriteArchive(w io.Writer, session string, events []Event) error {
+	enc := json.NewEncoder(w)
+	header := ArchiveHeader{
+		Version:    ArchiveVersion,
+		Session:    session,
+		ExportedAt: time.Now(),
+		Events:     len(events),
+	}
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("failed to write archive header: %w", err)
+	}
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to write event: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReadArchive decodes a session archive written by WriteArchive.
+// Returns an error for unknown versions or if the event count does not match the header.
+func ReadArchive(r io.Reader) (*ArchiveHeader, []Event, error) {
+	scanner := bufio.NewScanner(r)
+	// Events can carry large notes or summaries; allow lines up to 16MB
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive header: %w", err)
+		}
+		return nil, nil, fmt.Errorf("archive is empty")
+	}
+
+	var header ArchiveHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, nil, fmt.Errorf("invalid archive header: %w", err)
+	}
+	if header.Version == 0 {
+		return nil, nil, fmt.Errorf("not an iteratr session archive")
+	}
+	if header.Version > ArchiveVersion {
+		return nil, nil, fmt.Errorf("unsupported archive version %d (max supported: %d)", header.Version, ArchiveVersion)
+	}
+
+	events := make([]Event, 0, header.Events)
+	line := 1
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, nil, fmt.Errorf("invalid event on line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	if len(events) != header.Events {
+		return nil, nil, fmt.Errorf("archive is truncated: header declares %d events, found %d", header.Events, len(events))
+	}
+
+	return &header, events, nil
+}
+
+// SessionExists reports whether any events are stored for a session.
+func (s *Store) SessionExists(ctx context.Context, session string) (bool, error) {
+	count := 0
+	if _, err := s.forEachEvent(ctx, session, func(Event) { count++ }); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ImportSession replays archived events into a session, rewriting their session name.
+// Refuses to write into a session that already has events unless Force is set,
+// in which case the existing session is purged first. The archive is replayed
+// into an in-memory State before anything is purged, so a bad archive leaves
+// the existing session untouched.
+// Event IDs and timestamps are preserved so the reconstructed state matches the source.
+func (s *Store) ImportSession(ctx context.Context, params ImportParams) error {
+	if params.Name == "" {
+		return fmt.Errorf("session name is required")
+	}
+	if len(params.Events) == 0 {
+		return fmt.Errorf("archive contains no events")
+	}
+	if err := validateArchive(params.Name, params.Events); err != nil {
+		return err
+	}
+
+	exists, err := s.SessionExists(ctx, params.Name)
+	if err != nil {
+		return fmt.Errorf("failed to check existing session: %w", err)
+	}
+	if exists {
+		if !params.Force {
+			return fmt.Errorf("session '%s' already exists (use force to replace it)", params.Name)
+		}
+		logger.Info("Replacing existing session '%s' on import", params.Name)
+		if err := s.ResetSession(ctx, params.Name); err != nil {
+			return fmt.Errorf("failed to reset existing session: %w", err)
+		}
+	}
+
+	for i, event := range params.Events {
+		event.Session = params.Name
+		if _, err := s.PublishEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to import event %d of %d: %w", i+1, len(params.Events), err)
+		}
+	}
+
+	logger.Info("Imported %d events into session '%s'", len(params.Events), params.Name)
+	return nil
+}
+
+// validateArchive checks that every archived event can be published and
+// replays them into a fresh State, as LoadState would after the import.
+// Returns an error naming the first event that fails.
+func validateArchive(session string, events []Event) (err error) {
+	state := &State{Session: session, Tasks: make(map[string]*Task)}
+	current := 0
+	defer func() {
+		// A malformed event must not take the import down with it
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid archive: event %d of %d cannot be replayed: %v", current+1, len(events), r)
+		}
+	}()
+	for i, event := range events {
+		current = i
+		switch event.Type {
+		case nats.EventTypeTask, nats.EventTypeNote, nats.EventTypeIteration, nats.EventTypeControl:
+		default:
+			return fmt.Errorf("invalid archive: event %d of %d has unknown type %q", i+1, len(events), event.Type)
+		}
+		if event.Action == "" {
+			return fmt.Errorf("invalid archive: event %d of %d has no action", i+1, len(events))
+		}
+		event.Session = session
+		state.Apply(event)
+	}
+	return nil
+}
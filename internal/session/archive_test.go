@@ -0,0 +1,160 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestSessionArchive(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(js, stream)
+	source := "archive-src"
+
+	// Populate a session with a representative mix of events
+	if err := store.IterationStart(ctx, source, 1); err != nil {
+		t.Fatalf("IterationStart failed: %v", err)
+	}
+	if _, err := store.TaskBatchAdd(ctx, source, []TaskAddParams{
+		{Content: "Task A", Priority: 1, Iteration: 1},
+		{Content: "Task B", Iteration: 1},
+	}); err != nil {
+		t.Fatalf("TaskBatchAdd failed: %v", err)
+	}
+	if err := store.TaskDepends(ctx, source, TaskDependsParams{ID: "TAS-2", DependsOn: "TAS-1", Iteration: 1}); err != nil {
+		t.Fatalf("TaskDepends failed: %v", err)
+	}
+	if err := store.TaskStatus(ctx, source, TaskStatusParams{ID: "TAS-1", Status: "completed", Iteration: 1}); err != nil {
+		t.Fatalf("TaskStatus failed: %v", err)
+	}
+	if _, err := store.NoteAdd(ctx, source, NoteAddParams{Content: "learned", Type: "learning", Iteration: 1}); err != nil {
+		t.Fatalf("NoteAdd failed: %v", err)
+	}
+	if err := store.IterationSummary(ctx, source, 1, "did A", []string{"TAS-1"}); err != nil {
+		t.Fatalf("IterationSummary failed: %v", err)
+	}
+	if err := store.IterationComplete(ctx, source, 1); err != nil {
+		t.Fatalf("IterationComplete failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	count, err := store.ExportSession(ctx, source, &buf)
+	if err != nil {
+		t.Fatalf("ExportSession failed: %v", err)
+	}
+	archive := buf.String()
+
+	t.Run("export writes header and one line per event", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSpace(archive), "\n")
+		if len(lines) != count+1 {
+			t.Errorf("expected %d lines, got %d", count+1, len(lines))
+		}
+		if !strings.Contains(lines[0], `"iteratr_archive":1`) {
+			t.Errorf("expected versioned header, got %s", lines[0])
+		}
+	})
+
+	t.Run("import under new name reproduces state", func(t *testing.T) {
+		header, events, err := ReadArchive(strings.NewReader(archive))
+		if err != nil {
+			t.Fatalf("ReadArchive failed: %v", err)
+		}
+		if header.Session != source {
+			t.Errorf("expected header session %q, got %q", source, header.Session)
+		}
+
+		if err := store.ImportSession(ctx, ImportParams{Name: "archive-dst", Events: events}); err != nil {
+			t.Fatalf("ImportSession failed: %v", err)
+		}
+
+		want, _ := store.LoadState(ctx, source)
+		got, _ := store.LoadState(ctx, "archive-dst")
+		got.Session = want.Session
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("imported state differs from source\nwant: %+v\ngot:  %+v", want, got)
+		}
+	})
+
+	t.Run("import refuses to clobber existing session", func(t *testing.T) {
+		_, events, _ := ReadArchive(strings.NewReader(archive))
+		err := store.ImportSession(ctx, ImportParams{Name: "archive-dst", Events: events})
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("expected already exists error, got %v", err)
+		}
+	})
+
+	t.Run("forced import replaces existing session", func(t *testing.T) {
+		if _, err := store.TaskAdd(ctx, "archive-dst", TaskAddParams{Content: "Extra"}); err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		_, events, _ := ReadArchive(strings.NewReader(archive))
+		if err := store.ImportSession(ctx, ImportParams{Name: "archive-dst", Force: true, Events: events}); err != nil {
+			t.Fatalf("forced ImportSession failed: %v", err)
+		}
+		state, _ := store.LoadState(ctx, "archive-dst")
+		if len(state.Tasks) != 2 {
+			t.Errorf("expected 2 tasks after forced import, got %d", len(state.Tasks))
+		}
+	})
+
+	t.Run("forced import of a bad archive keeps the existing session", func(t *testing.T) {
+		before, _ := store.LoadState(ctx, "archive-dst")
+		_, events, _ := ReadArchive(strings.NewReader(archive))
+		events = append(events, Event{Type: "bogus.>", Action: "add", Data: "bad"})
+		err := store.ImportSession(ctx, ImportParams{Name: "archive-dst", Force: true, Events: events})
+		if err == nil || !strings.Contains(err.Error(), "unknown type") {
+			t.Fatalf("expected unknown type error, got %v", err)
+		}
+		after, _ := store.LoadState(ctx, "archive-dst")
+		if !reflect.DeepEqual(before, after) {
+			t.Errorf("rejected import changed the existing session\nbefore: %+v\nafter:  %+v", before, after)
+		}
+	})
+
+	t.Run("read rejects truncated and unknown archives", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSpace(archive), "\n")
+		truncated := strings.Join(lines[:len(lines)-1], "\n")
+		if _, _, err := ReadArchive(strings.NewReader(truncated)); err == nil {
+			t.Error("expected error for truncated archive")
+		}
+		if _, _, err := ReadArchive(strings.NewReader(`{"iteratr_archive":99,"events":0}`)); err == nil {
+			t.Error("expected error for unsupported version")
+		}
+		if _, _, err := ReadArchive(strings.NewReader(`{"foo":"bar"}`)); err == nil {
+			t.Error("expected error for non-archive input")
+		}
+	})
+
+	t.Run("export of unknown session fails", func(t *testing.T) {
+		if _, err := store.ExportSession(ctx, "does-not-exist", &bytes.Buffer{}); err == nil {
+			t.Error("expected error exporting unknown session")
+		}
+	})
+}
@@ -98,3 +98,32 @@ func (s *Store) SessionRestart(ctx context.Context, session string) error {
 
 	return nil
 }
+
+// BudgetExceeded records that a session budget limit was crossed.
+// Creates an event of type "control" with action "budget_exceeded".
+// The event is informational: it does not change reduced session state, but
+// leaves an audit trail of why the loop stopped or paused.
+func (s *Store) BudgetExceeded(ctx context.Context, session, limit, reason, action string) error {
+	meta, err := json.Marshal(map[string]string{
+		"limit":  limit,
+		"action": action,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal budget metadata: %w", err)
+	}
+
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeControl,
+		Action:  "budget_exceeded",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Budget exceeded: %s (%s)", reason, action),
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish budget exceeded event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// ForkParams represents the parameters for forking a session.
+type ForkParams struct {
+	Source      string // Session to copy events from
+	Target      string // New session name
+	AtIteration int    // Last iteration to keep
+	Force       bool   // Replace the target session if it already has events
+}
+
+// ForkSession copies the events of a session up to the end of an iteration
+// into a new session, leaving the source untouched. Because state is rebuilt
+// purely from events, the fork's state is exactly the source's state as of
+// that point. A "session_fork" control event records where the fork came from.
+// Returns the number of events copied.
+func (s *Store) ForkSession(ctx context.Context, params ForkParams) (int, error) {
+	if params.Source == params.Target {
+		return 0, fmt.Errorf("fork target must differ from source session")
+	}
+
+	events, err := s.Events(ctx, params.Source)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read session events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, fmt.Errorf("session not found: %s", params.Source)
+	}
+
+	kept, err := EventsThroughIteration(events, params.AtIteration)
+	if err != nil {
+		return 0, fmt.Errorf("cannot fork session '%s': %w", params.Source, err)
+	}
+
+	if err := s.ImportSession(ctx, ImportParams{
+		Name:   params.Target,
+		Force:  params.Force,
+		Events: kept,
+	}); err != nil {
+		return 0, err
+	}
+
+	meta, err := json.Marshal(map[string]any{
+		"source":       params.Source,
+		"at_iteration": params.AtIteration,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal fork metadata: %w", err)
+	}
+	event := Event{
+		Session: params.Target,
+		Type:    nats.EventTypeControl,
+		Action:  "session_fork",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Forked from %s at iteration %d", params.Source, params.AtIteration),
+	}
+	if _, err := s.PublishEvent(ctx, event); err != nil {
+		return 0, fmt.Errorf("failed to publish session fork event: %w", err)
+	}
+
+	logger.Info("Forked session '%s' at iteration %d into '%s' (%d events)", params.Source, params.AtIteration, params.Target, len(kept))
+	return len(kept), nil
+}
+
+// EventsThroughIteration returns the prefix of events that ends with
+// iteration n: everything before the first later iteration starts.
+// Returns an error if iteration n never started.
+func EventsThroughIteration(events []Event, n int) ([]Event, error) {
+	found := false
+	for i, event := range events {
+		if event.Type != nats.EventTypeIteration || event.Action != "start" {
+			continue
+		}
+		var meta struct {
+			Number int `json:"number"`
+		}
+		if err := json.Unmarshal(event.Meta, &meta); err != nil {
+			continue
+		}
+		if meta.Number > n {
+			if !found {
+				break
+			}
+			return events[:i], nil
+		}
+		if meta.Number == n {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("iteration %d not found", n)
+	}
+	return events, nil
+}
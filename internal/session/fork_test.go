@@ -0,0 +1,132 @@
+package session
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestForkSession(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(js, stream)
+	source := "fork-src"
+
+	// Iteration 1 adds two tasks, iteration 2 completes one, iteration 3 goes astray
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+	must(store.IterationStart(ctx, source, 1))
+	_, err = store.TaskBatchAdd(ctx, source, []TaskAddParams{
+		{Content: "Task A", Iteration: 1},
+		{Content: "Task B", Iteration: 1},
+	})
+	must(err)
+	must(store.IterationComplete(ctx, source, 1))
+	must(store.IterationStart(ctx, source, 2))
+	must(store.TaskStatus(ctx, source, TaskStatusParams{ID: "TAS-1", Status: "completed", Iteration: 2}))
+	must(store.IterationComplete(ctx, source, 2))
+	must(store.IterationUsage(ctx, source, 2, "test/model", Usage{InputTokens: 10, OutputTokens: 5}))
+	must(store.IterationStart(ctx, source, 3))
+	must(store.TaskStatus(ctx, source, TaskStatusParams{ID: "TAS-2", Status: "cancelled", Iteration: 3}))
+	_, err = store.NoteAdd(ctx, source, NoteAddParams{Content: "wrong turn", Type: "stuck", Iteration: 3})
+	must(err)
+	must(store.IterationComplete(ctx, source, 3))
+
+	t.Run("fork reproduces state at end of iteration", func(t *testing.T) {
+		count, err := store.ForkSession(ctx, ForkParams{Source: source, Target: "fork-dst", AtIteration: 2})
+		if err != nil {
+			t.Fatalf("ForkSession failed: %v", err)
+		}
+
+		// Expected state: source events up to (not including) iteration 3's start
+		events, _ := store.Events(ctx, source)
+		want := &State{Session: "fork-dst", Tasks: make(map[string]*Task)}
+		for _, event := range events[:count] {
+			want.Apply(event)
+		}
+		got, err := store.LoadState(ctx, "fork-dst")
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("forked state differs from source prefix\nwant: %+v\ngot:  %+v", want, got)
+		}
+
+		if len(got.Iterations) != 2 || got.Tasks["TAS-2"].Status != "remaining" || len(got.Notes) != 0 {
+			t.Errorf("fork should stop before iteration 3: %d iterations, TAS-2 %s, %d notes",
+				len(got.Iterations), got.Tasks["TAS-2"].Status, len(got.Notes))
+		}
+		if got.Usage.InputTokens != 10 {
+			t.Errorf("fork should keep usage recorded after iteration 2 completed, got %+v", got.Usage)
+		}
+
+		// Provenance is recorded, and the source is untouched
+		forkEvents, _ := store.Events(ctx, "fork-dst")
+		last := forkEvents[len(forkEvents)-1]
+		if last.Action != "session_fork" || !strings.Contains(last.Data, source) {
+			t.Errorf("expected trailing session_fork event, got %+v", last)
+		}
+		srcState, _ := store.LoadState(ctx, source)
+		if len(srcState.Iterations) != 3 {
+			t.Errorf("source should keep 3 iterations, got %d", len(srcState.Iterations))
+		}
+	})
+
+	t.Run("fork at last iteration copies everything", func(t *testing.T) {
+		count, err := store.ForkSession(ctx, ForkParams{Source: source, Target: "fork-all", AtIteration: 3})
+		if err != nil {
+			t.Fatalf("ForkSession failed: %v", err)
+		}
+		events, _ := store.Events(ctx, source)
+		if count != len(events) {
+			t.Errorf("expected all %d events copied, got %d", len(events), count)
+		}
+	})
+
+	t.Run("fork errors", func(t *testing.T) {
+		if _, err := store.ForkSession(ctx, ForkParams{Source: source, Target: "fork-x", AtIteration: 7}); err == nil || !strings.Contains(err.Error(), "iteration 7 not found") {
+			t.Errorf("expected missing iteration error, got %v", err)
+		}
+		if _, err := store.ForkSession(ctx, ForkParams{Source: "missing", Target: "fork-x", AtIteration: 1}); err == nil {
+			t.Error("expected error forking unknown session")
+		}
+		if _, err := store.ForkSession(ctx, ForkParams{Source: source, Target: source, AtIteration: 1}); err == nil {
+			t.Error("expected error forking onto the source")
+		}
+		if _, err := store.ForkSession(ctx, ForkParams{Source: source, Target: "fork-dst", AtIteration: 1}); err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("expected already exists error, got %v", err)
+		}
+		if _, err := store.ForkSession(ctx, ForkParams{Source: source, Target: "fork-dst", AtIteration: 1, Force: true}); err != nil {
+			t.Errorf("forced fork failed: %v", err)
+		}
+	})
+}
@@ -66,6 +66,37 @@ func (s *Store) IterationComplete(ctx context.Context, session string, number in
 	return nil
 }
 
+// IterationTimeout records that a watchdog cancelled an iteration.
+// Creates an event of type "iteration" with action "timeout".
+// Kind is "iteration_timeout" or "idle_timeout"; reason is the human-readable error.
+func (s *Store) IterationTimeout(ctx context.Context, session string, number int, kind, reason string) error {
+	// Build metadata
+	meta, err := json.Marshal(map[string]any{
+		"number": number,
+		"kind":   kind,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration timeout metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "timeout",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Iteration %d timed out: %s", number, reason),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration timeout event: %w", err)
+	}
+
+	return nil
+}
+
 // IterationSummary logs a summary for an iteration with tasks worked.
 // Creates an event of type "iteration" with action "summary".
 func (s *Store) IterationSummary(ctx context.Context, session string, number int, summary string, tasksWorked []string) error {
@@ -96,3 +127,36 @@ func (s *Store) IterationSummary(ctx context.Context, session string, number int
 
 	return nil
 }
+
+// IterationUsage records token usage and estimated cost of one agent turn
+// (an iteration or a follow-up message) against an iteration.
+// Creates an event of type "iteration" with action "usage".
+func (s *Store) IterationUsage(ctx context.Context, session string, number int, model string, usage Usage) error {
+	// Build metadata
+	meta, err := json.Marshal(struct {
+		Number int    `json:"number"`
+		Model  string `json:"model,omitempty"`
+		Usage
+	}{number, model, usage})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration usage metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "usage",
+		Meta:    meta,
+		Data: fmt.Sprintf("Iteration %d: %s in / %s out tokens, ~$%.4f",
+			number, FormatTokens(usage.InputTokens), FormatTokens(usage.OutputTokens), usage.Cost),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration usage event: %w", err)
+	}
+
+	return nil
+}
@@ -2,7 +2,9 @@ package session
 
 import (
 	"context"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/iteratr/internal/nats"
 )
@@ -236,4 +238,56 @@ func TestIterationOperations(t *testing.T) {
 			}
 		}
 	})
+	t.Run("IterationUsage accumulates per iteration and session", func(t *testing.T) {
+		usageSession := "test-usage"
+
+		if err := store.IterationStart(ctx, usageSession, 1); err != nil {
+			t.Fatalf("IterationStart failed: %v", err)
+		}
+		turns := []Usage{
+			{InputTokens: 1000, OutputTokens: 200, CacheReadTokens: 500, Duration: time.Second, Cost: 0.01},
+			{InputTokens: 300, OutputTokens: 50, Duration: time.Second, Cost: 0.002},
+		}
+		for _, u := range turns {
+			if err := store.IterationUsage(ctx, usageSession, 1, "anthropic/claude-sonnet-4-5", u); err != nil {
+				t.Fatalf("IterationUsage failed: %v", err)
+			}
+		}
+
+		state, err := store.LoadState(ctx, usageSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+
+		want := Usage{InputTokens: 1300, OutputTokens: 250, CacheReadTokens: 500, Duration: 2 * time.Second, Cost: 0.012}
+		if got := state.Iterations[0].Usage; got.InputTokens != want.InputTokens || got.OutputTokens != want.OutputTokens ||
+			got.CacheReadTokens != want.CacheReadTokens || got.Duration != want.Duration {
+			t.Errorf("iteration usage = %+v, want %+v", got, want)
+		}
+		if got := state.Usage; got.TotalTokens() != 1550 || math.Abs(got.Cost-want.Cost) > 1e-9 {
+			t.Errorf("session usage = %+v, want %d tokens costing %v", got, 1550, want.Cost)
+		}
+	})
+	t.Run("IterationTimeout marks iteration ended but not complete", func(t *testing.T) {
+		timeoutSession := "test-timeout"
+
+		if err := store.IterationStart(ctx, timeoutSession, 1); err != nil {
+			t.Fatalf("IterationStart failed: %v", err)
+		}
+		if err := store.IterationTimeout(ctx, timeoutSession, 1, "idle_timeout", "agent idle for 5m0s"); err != nil {
+			t.Fatalf("IterationTimeout failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, timeoutSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		iter := state.Iterations[0]
+		if !iter.TimedOut || iter.TimeoutKind != "idle_timeout" {
+			t.Errorf("iteration = %+v, want timed out with kind idle_timeout", iter)
+		}
+		if iter.Complete || iter.EndedAt.IsZero() {
+			t.Errorf("iteration = %+v, want ended but not complete", iter)
+		}
+	})
 }
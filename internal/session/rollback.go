@@ -0,0 +1,247 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// RollbackParams represents the parameters for rolling back a session.
+type RollbackParams struct {
+	Session     string // Session to roll back
+	ToIteration int    // Last iteration to keep (-1 = none, back to before the first iteration)
+}
+
+// RollbackResult describes a rollback.
+type RollbackResult struct {
+	Iterations []int // Iterations that were rolled back
+	Events     int   // Compensating events appended
+}
+
+// RollbackSession undoes the iterations after ToIteration. The event log is
+// append-only, so instead of truncating it, compensating events are appended
+// that bring tasks, notes, and the completion flag back to their state at the
+// end of ToIteration, followed by an iteration "rollback" event that marks the
+// later iterations as rolled back. Usage recorded by those iterations is kept.
+// A ToIteration of -1 rolls back every iteration, keeping only the events
+// recorded before the first one (e.g. imported tasks).
+// Working tree files are restored separately from git checkpoints.
+func (s *Store) RollbackSession(ctx context.Context, params RollbackParams) (*RollbackResult, error) {
+	events, err := s.Events(ctx, params.Session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("session not found: %s", params.Session)
+	}
+
+	kept := eventsBeforeIterations(events)
+	if params.ToIteration >= 0 {
+		kept, err = EventsThroughIteration(events, params.ToIteration)
+		if err != nil {
+			return nil, fmt.Errorf("cannot roll back session '%s': %w", params.Session, err)
+		}
+	}
+	target := replayEvents(params.Session, kept)
+	current := replayEvents(params.Session, events)
+
+	result := &RollbackResult{}
+	for _, iter := range current.Iterations {
+		if iter.Number == params.ToIteration && iter.RolledBack {
+			return nil, fmt.Errorf("iteration %d was already rolled back", params.ToIteration)
+		}
+		if iter.Number > params.ToIteration && !iter.RolledBack {
+			result.Iterations = append(result.Iterations, iter.Number)
+		}
+	}
+	if len(result.Iterations) == 0 {
+		return nil, fmt.Errorf("no iterations after iteration %d to roll back", params.ToIteration)
+	}
+
+	compensating := compensatingEvents(params.Session, current, target)
+	meta, _ := json.Marshal(map[string]any{
+		"number": params.ToIteration,
+	})
+	compensating = append(compensating, Event{
+		Session: params.Session,
+		Type:    nats.EventTypeIteration,
+		Action:  "rollback",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Rolled back to iteration %d", params.ToIteration),
+	})
+
+	for _, event := range compensating {
+		if _, err := s.PublishEvent(ctx, event); err != nil {
+			return nil, fmt.Errorf("failed to publish rollback event: %w", err)
+		}
+	}
+	result.Events = len(compensating)
+
+	logger.Info("Rolled back session '%s' to iteration %d (%d events)", params.Session, params.ToIteration, len(compensating))
+	return result, nil
+}
+
+// eventsBeforeIterations returns the events recorded before the session's
+// first iteration started.
+func eventsBeforeIterations(events []Event) []Event {
+	for i, event := range events {
+		if event.Type == nats.EventTypeIteration && event.Action == "start" {
+			return events[:i]
+		}
+	}
+	return events
+}
+
+// replayEvents reduces events into a fresh state.
+func replayEvents(session string, events []Event) *State {
+	st := &State{
+		Session: session,
+		Tasks:   make(map[string]*Task),
+	}
+	for _, event := range events {
+		st.Apply(event)
+	}
+	return st
+}
+
+// compensatingEvents returns the task, note, and control events that turn
+// current into target. Items missing from current are re-added under their
+// original IDs and creation times.
+func compensatingEvents(session string, current, target *State) []Event {
+	var events []Event
+	add := func(eventType, action, data string, meta map[string]any) {
+		raw, _ := json.Marshal(meta)
+		events = append(events, Event{Session: session, Type: eventType, Action: action, Meta: raw, Data: data})
+	}
+
+	// Tasks
+	for _, id := range slices.Sorted(maps.Keys(current.Tasks)) {
+		if _, ok := target.Tasks[id]; !ok {
+			add(nats.EventTypeTask, "delete", id, map[string]any{"task_id": id})
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(target.Tasks)) {
+		want := target.Tasks[id]
+		got, ok := current.Tasks[id]
+
+		// Dependencies can only be added, so a task that gained some is recreated
+		if ok && slices.ContainsFunc(got.DependsOn, func(dep string) bool { return !slices.Contains(want.DependsOn, dep) }) {
+			add(nats.EventTypeTask, "delete", id, map[string]any{"task_id": id})
+			ok = false
+		}
+		if !ok {
+			metaMap := map[string]any{
+				"status":    want.Status,
+				"priority":  want.Priority,
+				"iteration": want.Iteration,
+			}
+			TaskAddParams{
+				ParentID:           want.ParentID,
+				Origin:             want.Origin,
+				Description:        want.Description,
+				AcceptanceCriteria: want.AcceptanceCriteria,
+				Labels:             want.Labels,
+				Estimate:           want.Estimate,
+			}.addDetailsMeta(metaMap)
+			meta, _ := json.Marshal(metaMap)
+			events = append(events, Event{
+				ID:        id,
+				Timestamp: want.CreatedAt,
+				Session:   session,
+				Type:      nats.EventTypeTask,
+				Action:    "add",
+				Meta:      meta,
+				Data:      want.Content,
+			})
+			recreated := *want
+			recreated.DependsOn = nil
+			got = &recreated
+		}
+
+		if got.Content != want.Content {
+			add(nats.EventTypeTask, "content", want.Content, map[string]any{"task_id": id, "iteration": want.Iteration})
+		}
+		if got.Status != want.Status {
+			add(nats.EventTypeTask, "status", want.Status, map[string]any{"task_id": id, "status": want.Status, "iteration": want.Iteration})
+		}
+		if got.Priority != want.Priority {
+			add(nats.EventTypeTask, "priority", fmt.Sprintf("%d", want.Priority), map[string]any{"task_id": id, "priority": want.Priority, "iteration": want.Iteration})
+		}
+		if got.ParentID != want.ParentID {
+			add(nats.EventTypeTask, "parent", want.ParentID, map[string]any{"task_id": id, "parent_id": want.ParentID, "iteration": want.Iteration})
+		}
+		if got.Description != want.Description {
+			add(nats.EventTypeTask, "description", want.Description, map[string]any{"task_id": id, "iteration": want.Iteration})
+		}
+		if !slices.Equal(got.AcceptanceCriteria, want.AcceptanceCriteria) {
+			add(nats.EventTypeTask, "acceptance", strings.Join(want.AcceptanceCriteria, "\n"), map[string]any{"task_id": id, "acceptance_criteria": want.AcceptanceCriteria, "iteration": want.Iteration})
+		}
+		if !slices.Equal(got.Labels, want.Labels) {
+			add(nats.EventTypeTask, "labels", strings.Join(want.Labels, ","), map[string]any{"task_id": id, "labels": want.Labels, "iteration": want.Iteration})
+		}
+		if got.Estimate != want.Estimate {
+			add(nats.EventTypeTask, "estimate", want.Estimate, map[string]any{"task_id": id, "iteration": want.Iteration})
+		}
+		for _, dep := range want.DependsOn {
+			if !slices.Contains(got.DependsOn, dep) {
+				add(nats.EventTypeTask, "depends", dep, map[string]any{"task_id": id, "depends_on": dep, "iteration": want.Iteration})
+			}
+		}
+	}
+
+	// Notes
+	currentNotes := make(map[string]*Note, len(current.Notes))
+	for _, note := range current.Notes {
+		currentNotes[note.ID] = note
+	}
+	targetNotes := make(map[string]bool, len(target.Notes))
+	for _, note := range target.Notes {
+		targetNotes[note.ID] = true
+	}
+	for _, note := range current.Notes {
+		if !targetNotes[note.ID] {
+			add(nats.EventTypeNote, "delete", note.ID, map[string]any{"note_id": note.ID})
+		}
+	}
+	for _, want := range target.Notes {
+		got, ok := currentNotes[want.ID]
+		if !ok {
+			meta, _ := json.Marshal(map[string]any{
+				"type":      want.Type,
+				"iteration": want.Iteration,
+			})
+			events = append(events, Event{
+				ID:        want.ID,
+				Timestamp: want.CreatedAt,
+				Session:   session,
+				Type:      nats.EventTypeNote,
+				Action:    "add",
+				Meta:      meta,
+				Data:      want.Content,
+			})
+			continue
+		}
+		if got.Content != want.Content {
+			add(nats.EventTypeNote, "content", want.Content, map[string]any{"note_id": want.ID, "iteration": want.Iteration})
+		}
+		if got.Type != want.Type {
+			add(nats.EventTypeNote, "type", want.Type, map[string]any{"note_id": want.ID, "type": want.Type, "iteration": want.Iteration})
+		}
+	}
+
+	// Completion flag
+	switch {
+	case current.Complete && !target.Complete:
+		add(nats.EventTypeControl, "session_restart", "Session restarted", map[string]any{})
+	case !current.Complete && target.Complete:
+		add(nats.EventTypeControl, "session_complete", "Session marked as complete", map[string]any{})
+	}
+
+	return events
+}
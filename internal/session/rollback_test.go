@@ -0,0 +1,173 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestRollbackSession(t *testing.T) {
+	// Setup: Create embedded NATS and store
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(js, stream)
+	name := "rollback"
+
+	// Iteration 1 sets up tasks and a note, iteration 2 goes astray in every way
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+	must(store.IterationStart(ctx, name, 1))
+	_, err = store.TaskBatchAdd(ctx, name, []TaskAddParams{
+		{Content: "Task A", Iteration: 1},
+		{Content: "Task B", Iteration: 1, AcceptanceCriteria: []string{"B works"}, Labels: []string{"api"}},
+		{Content: "Task C", Iteration: 1},
+	})
+	must(err)
+	must(store.TaskDepends(ctx, name, TaskDependsParams{ID: "TAS-2", DependsOn: "TAS-1", Iteration: 1}))
+	_, err = store.NoteAdd(ctx, name, NoteAddParams{Content: "good idea", Type: "tip", Iteration: 1})
+	must(err)
+	must(store.IterationComplete(ctx, name, 1))
+
+	must(store.IterationStart(ctx, name, 2))
+	must(store.TaskStatus(ctx, name, TaskStatusParams{ID: "TAS-1", Status: "completed", Iteration: 2}))
+	must(store.TaskPriority(ctx, name, TaskPriorityParams{ID: "TAS-1", Priority: 0, Iteration: 2}))
+	must(store.TaskContent(ctx, name, TaskContentParams{ID: "TAS-2", Content: "Task B, reworded", Iteration: 2}))
+	must(store.TaskParent(ctx, name, TaskParentParams{ID: "TAS-1", ParentID: "TAS-3", Iteration: 2}))
+	estimate := "3d"
+	must(store.TaskDetails(ctx, name, TaskDetailsParams{ID: "TAS-1", Estimate: &estimate, Labels: []string{"ui"}, Iteration: 2}))
+	must(store.TaskDetails(ctx, name, TaskDetailsParams{ID: "TAS-2", AcceptanceCriteria: []string{}, Iteration: 2}))
+	must(store.TaskDepends(ctx, name, TaskDependsParams{ID: "TAS-2", DependsOn: "TAS-3", Iteration: 2}))
+	must(store.TaskDelete(ctx, name, TaskDeleteParams{ID: "TAS-3", Iteration: 2}))
+	_, err = store.TaskAdd(ctx, name, TaskAddParams{Content: "Task D", Iteration: 2})
+	must(err)
+	must(store.NoteType(ctx, name, NoteTypeParams{ID: "NOT-1", Type: "stuck", Iteration: 2}))
+	_, err = store.NoteAdd(ctx, name, NoteAddParams{Content: "wrong turn", Type: "stuck", Iteration: 2})
+	must(err)
+	must(store.TaskStatus(ctx, name, TaskStatusParams{ID: "TAS-2", Status: "cancelled", Iteration: 2}))
+	must(store.TaskStatus(ctx, name, TaskStatusParams{ID: "TAS-4", Status: "completed", Iteration: 2}))
+	must(store.IterationComplete(ctx, name, 2))
+	must(store.IterationUsage(ctx, name, 2, "test/model", Usage{InputTokens: 10, OutputTokens: 5}))
+	must(store.SessionComplete(ctx, name))
+
+	events, _ := store.Events(ctx, name)
+	kept, _ := EventsThroughIteration(events, 1)
+	want := replayEvents(name, kept)
+
+	result, err := store.RollbackSession(ctx, RollbackParams{Session: name, ToIteration: 1})
+	if err != nil {
+		t.Fatalf("RollbackSession failed: %v", err)
+	}
+	if len(result.Iterations) != 1 || result.Iterations[0] != 2 {
+		t.Errorf("expected iteration 2 rolled back, got %v", result.Iterations)
+	}
+
+	got, err := store.LoadState(ctx, name)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if got.Complete {
+		t.Error("session should no longer be complete")
+	}
+	if len(got.Tasks) != len(want.Tasks) {
+		t.Errorf("expected %d tasks, got %d", len(want.Tasks), len(got.Tasks))
+	}
+	for id, w := range want.Tasks {
+		g, ok := got.Tasks[id]
+		if !ok {
+			t.Errorf("task %s missing after rollback", id)
+			continue
+		}
+		if g.Content != w.Content || g.Status != w.Status || g.Priority != w.Priority ||
+			strings.Join(g.DependsOn, ",") != strings.Join(w.DependsOn, ",") || !g.CreatedAt.Equal(w.CreatedAt) ||
+			g.ParentID != w.ParentID || g.Estimate != w.Estimate || strings.Join(g.Labels, ",") != strings.Join(w.Labels, ",") ||
+			strings.Join(g.AcceptanceCriteria, ",") != strings.Join(w.AcceptanceCriteria, ",") {
+			t.Errorf("task %s = %+v, want %+v", id, g, w)
+		}
+	}
+	if len(got.Notes) != 1 || got.Notes[0].ID != "NOT-1" || got.Notes[0].Type != "tip" {
+		t.Errorf("expected only NOT-1 as a tip, got %+v", got.Notes)
+	}
+	if len(got.Iterations) != 2 || got.Iterations[0].RolledBack || !got.Iterations[1].RolledBack {
+		t.Errorf("expected iteration 2 marked rolled back, got %+v", got.Iterations)
+	}
+	if got.Usage.InputTokens != 10 {
+		t.Errorf("rollback should keep usage, got %+v", got.Usage)
+	}
+
+	// New tasks must not reuse IDs of rolled back ones
+	added, err := store.TaskAdd(ctx, name, TaskAddParams{Content: "Task E", Iteration: 3})
+	if err != nil {
+		t.Fatalf("TaskAdd after rollback failed: %v", err)
+	}
+	if added.ID == "TAS-4" {
+		t.Error("new task reused a rolled back task ID")
+	}
+
+	t.Run("rollback errors", func(t *testing.T) {
+		if _, err := store.RollbackSession(ctx, RollbackParams{Session: name, ToIteration: 1}); err == nil || !strings.Contains(err.Error(), "no iterations after") {
+			t.Errorf("expected nothing to roll back, got %v", err)
+		}
+		if _, err := store.RollbackSession(ctx, RollbackParams{Session: name, ToIteration: 2}); err == nil || !strings.Contains(err.Error(), "already rolled back") {
+			t.Errorf("expected already rolled back error, got %v", err)
+		}
+		if _, err := store.RollbackSession(ctx, RollbackParams{Session: name, ToIteration: 7}); err == nil || !strings.Contains(err.Error(), "iteration 7 not found") {
+			t.Errorf("expected missing iteration error, got %v", err)
+		}
+		if _, err := store.RollbackSession(ctx, RollbackParams{Session: "missing", ToIteration: 1}); err == nil {
+			t.Error("expected error rolling back unknown session")
+		}
+	})
+
+	t.Run("rollback before the first iteration", func(t *testing.T) {
+		first := "rollback-first"
+		if _, err := store.TaskAdd(ctx, first, TaskAddParams{Content: "Imported"}); err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		must(store.IterationStart(ctx, first, 0))
+		_, err := store.TaskAdd(ctx, first, TaskAddParams{Content: "Planned", Iteration: 0})
+		must(err)
+		must(store.TaskStatus(ctx, first, TaskStatusParams{ID: "TAS-1", Status: "in_progress", Iteration: 0}))
+
+		result, err := store.RollbackSession(ctx, RollbackParams{Session: first, ToIteration: -1})
+		if err != nil {
+			t.Fatalf("RollbackSession(-1) failed: %v", err)
+		}
+		if len(result.Iterations) != 1 || result.Iterations[0] != 0 {
+			t.Errorf("expected iteration 0 rolled back, got %v", result.Iterations)
+		}
+		got, _ := store.LoadState(ctx, first)
+		if len(got.Tasks) != 1 || got.Tasks["TAS-1"] == nil || got.Tasks["TAS-1"].Status != "remaining" {
+			t.Errorf("expected only the imported task, still remaining, got %+v", got.Tasks)
+		}
+		if !got.Iterations[0].RolledBack {
+			t.Error("expected iteration 0 marked rolled back")
+		}
+	})
+}
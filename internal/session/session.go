@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/mark3labs/iteratr/internal/logger"
@@ -29,19 +30,44 @@ type Event struct {
 type Store struct {
 	js     jetstream.JetStream // JetStream context for operations
 	stream jetstream.Stream    // The iteratr_events stream
+
+	snapshotsMu      sync.Mutex         // Guards snapshots
+	snapshots        jetstream.KeyValue // Snapshot bucket, opened lazily
+	snapshotInterval int                // Tail length that triggers a new snapshot (<= 0 disables)
+
+	transcriptsMu sync.Mutex            // Guards transcripts
+	transcripts   jetstream.ObjectStore // Transcript object store, opened lazily
+	retention     nats.Retention        // Retention applied to the transcript object store
 }
 
 // NewStore creates a new Store instance with the given JetStream context and stream.
 func NewStore(js jetstream.JetStream, stream jetstream.Stream) *Store {
 	return &Store{
-		js:     js,
-		stream: stream,
+		js:               js,
+		stream:           stream,
+		snapshotInterval: DefaultSnapshotInterval,
+		retention:        nats.DefaultRetention(),
 	}
 }
 
+// SetRetention sets the retention limits the store applies to the data it
+// keeps outside the event stream, so transcripts expire with the configured
+// max age rather than the default. Call it before the store is used.
+func (s *Store) SetRetention(retention nats.Retention) {
+	s.transcriptsMu.Lock()
+	defer s.transcriptsMu.Unlock()
+	s.retention = retention
+}
+
 // ResetSession removes all events for a session, resetting it to a fresh state.
+// The session's state snapshot and transcripts are discarded along with its events.
 func (s *Store) ResetSession(ctx context.Context, session string) error {
-	return nats.PurgeSession(ctx, s.stream, session)
+	if err := nats.PurgeSession(ctx, s.stream, session); err != nil {
+		return err
+	}
+	s.deleteSnapshot(ctx, session)
+	s.deleteTranscripts(ctx, session)
+	return nil
 }
 
 // PublishEvent appends an event to the JetStream event log.
@@ -87,18 +113,27 @@ type State struct {
 	Iterations  []*Iteration     `json:"iterations"`   // Iteration history
 	Complete    bool             `json:"complete"`     // Session marked complete
 	Model       string           `json:"model"`        // Last model used for this session
+	Usage       Usage            `json:"usage"`        // Token usage and estimated cost across all iterations
 }
 
 // Task represents a task in the task system.
 type Task struct {
 	ID        string    `json:"id"`
 	Content   string    `json:"content"`
-	Status    string    `json:"status"`     // remaining, in_progress, completed, blocked, cancelled
-	Priority  int       `json:"priority"`   // 0-4, default 2 (0=critical, 1=high, 2=medium, 3=low, 4=backlog)
-	DependsOn []string  `json:"depends_on"` // Task IDs this task is blocked by
+	Status    string    `json:"status"`              // remaining, in_progress, completed, blocked, cancelled
+	Priority  int       `json:"priority"`            // 0-4, default 2 (0=critical, 1=high, 2=medium, 3=low, 4=backlog)
+	DependsOn []string  `json:"depends_on"`          // Task IDs this task is blocked by
+	ParentID  string    `json:"parent_id,omitempty"` // Task this is a subtask of
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
-	Iteration int       `json:"iteration"` // Iteration that last modified this task
+	Iteration int       `json:"iteration"`        // Iteration that last modified this task
+	Origin    string    `json:"origin,omitempty"` // TaskOriginSpec for tasks created from the spec
+
+	// Optional details that spell out what "done" means for the task
+	Description        string   `json:"description,omitempty"`         // Long-form description
+	AcceptanceCriteria []string `json:"acceptance_criteria,omitempty"` // Conditions to verify before completing
+	Labels             []string `json:"labels,omitempty"`              // Free-form labels, e.g. "backend"
+	Estimate           string   `json:"estimate,omitempty"`            // Free-form size estimate, e.g. "2h" or "M"
 }
 
 // Note represents a note recorded during a session.
@@ -120,6 +155,10 @@ type Iteration struct {
 	Summary     string    `json:"summary,omitempty"`      // What was accomplished
 	TasksWorked []string  `json:"tasks_worked,omitempty"` // Task IDs touched
 	TaskStarted bool      `json:"task_started,omitempty"` // Whether a task was set to in_progress during this iteration
+	Usage       Usage     `json:"usage,omitzero"`         // Token usage and estimated cost of this iteration's agent turns
+	TimedOut    bool      `json:"timed_out,omitempty"`    // Whether a watchdog cancelled this iteration
+	TimeoutKind string    `json:"timeout_kind,omitempty"` // "iteration_timeout" or "idle_timeout"
+	RolledBack  bool      `json:"rolled_back,omitempty"`  // Whether a rollback discarded this iteration's work
 }
 
 // SessionInfo provides summary information about a session for UI display.
@@ -130,6 +169,7 @@ type SessionInfo struct {
 	TasksCompleted int       `json:"tasks_completed"`
 	LastActivity   time.Time `json:"last_activity"`
 	Model          string    `json:"model"` // Last model used for this session
+	Usage          Usage     `json:"usage"` // Token usage and estimated cost across all iterations
 }
 
 // Apply applies an event to the state, implementing the reduce pattern.
@@ -164,9 +204,15 @@ func (st *State) applyTaskEvent(event Event) {
 	case "add":
 		// Parse metadata for status, priority, and iteration
 		var meta struct {
-			Status    string `json:"status"`
-			Priority  int    `json:"priority"`
-			Iteration int    `json:"iteration"`
+			Status             string   `json:"status"`
+			Priority           int      `json:"priority"`
+			Iteration          int      `json:"iteration"`
+			Description        string   `json:"description"`
+			AcceptanceCriteria []string `json:"acceptance_criteria"`
+			Labels             []string `json:"labels"`
+			Estimate           string   `json:"estimate"`
+			ParentID           string   `json:"parent_id"`
+			Origin             string   `json:"origin"`
 		}
 		_ = json.Unmarshal(event.Meta, &meta)
 
@@ -197,9 +243,16 @@ func (st *State) applyTaskEvent(event Event) {
 			Status:    meta.Status,
 			Priority:  priority,
 			DependsOn: []string{}, // Initialize empty dependencies
+			ParentID:  meta.ParentID,
 			CreatedAt: event.Timestamp,
 			UpdatedAt: event.Timestamp,
 			Iteration: meta.Iteration,
+			Origin:    meta.Origin,
+
+			Description:        meta.Description,
+			AcceptanceCriteria: meta.AcceptanceCriteria,
+			Labels:             meta.Labels,
+			Estimate:           meta.Estimate,
 		}
 		st.Tasks[event.ID] = task
 		st.TaskCounter++
@@ -288,6 +341,62 @@ func (st *State) applyTaskEvent(event Event) {
 			task.Iteration = meta.Iteration
 		}
 
+	case "description", "estimate":
+		// Parse metadata for task ID and iteration; the new value is in Data
+		var meta struct {
+			TaskID    string `json:"task_id"`
+			Iteration int    `json:"iteration"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Replace the field if the task exists (empty clears it)
+		if task, exists := st.Tasks[meta.TaskID]; exists {
+			if event.Action == "description" {
+				task.Description = event.Data
+			} else {
+				task.Estimate = event.Data
+			}
+			task.UpdatedAt = event.Timestamp
+			task.Iteration = meta.Iteration
+		}
+
+	case "acceptance", "labels":
+		// Parse metadata for task ID and the full replacement list
+		var meta struct {
+			TaskID             string   `json:"task_id"`
+			AcceptanceCriteria []string `json:"acceptance_criteria"`
+			Labels             []string `json:"labels"`
+			Iteration          int      `json:"iteration"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Replace the list if the task exists (empty clears it)
+		if task, exists := st.Tasks[meta.TaskID]; exists {
+			if event.Action == "acceptance" {
+				task.AcceptanceCriteria = meta.AcceptanceCriteria
+			} else {
+				task.Labels = meta.Labels
+			}
+			task.UpdatedAt = event.Timestamp
+			task.Iteration = meta.Iteration
+		}
+
+	case "parent":
+		// Parse metadata for task ID and new parent
+		var meta struct {
+			TaskID    string `json:"task_id"`
+			ParentID  string `json:"parent_id"`
+			Iteration int    `json:"iteration"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Move the task under its new parent (empty makes it top-level)
+		if task, exists := st.Tasks[meta.TaskID]; exists {
+			task.ParentID = meta.ParentID
+			task.UpdatedAt = event.Timestamp
+			task.Iteration = meta.Iteration
+		}
+
 	case "delete":
 		// Parse metadata for task ID
 		var meta struct {
@@ -411,6 +520,24 @@ func (st *State) applyIterationEvent(event Event) {
 			}
 		}
 
+	case "timeout":
+		// Parse metadata for iteration number and timeout kind
+		var meta struct {
+			Number int    `json:"number"`
+			Kind   string `json:"kind"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Mark iteration as ended by the watchdog (not complete)
+		for _, iter := range st.Iterations {
+			if iter.Number == meta.Number {
+				iter.TimedOut = true
+				iter.TimeoutKind = meta.Kind
+				iter.EndedAt = event.Timestamp
+				break
+			}
+		}
+
 	case "summary":
 		// Parse metadata for iteration number, summary, and tasks worked
 		var meta struct {
@@ -428,6 +555,37 @@ func (st *State) applyIterationEvent(event Event) {
 				break
 			}
 		}
+
+	case "usage":
+		// Parse metadata for iteration number and usage of one agent turn
+		var meta struct {
+			Number int `json:"number"`
+			Usage
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Accumulate on the iteration and the session totals
+		for _, iter := range st.Iterations {
+			if iter.Number == meta.Number {
+				iter.Usage.Add(meta.Usage)
+				break
+			}
+		}
+		st.Usage.Add(meta.Usage)
+
+	case "rollback":
+		// Parse metadata for the last iteration kept
+		var meta struct {
+			Number int `json:"number"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Mark later iterations as discarded; their usage still counts
+		for _, iter := range st.Iterations {
+			if iter.Number > meta.Number {
+				iter.RolledBack = true
+			}
+		}
 	}
 }
 
@@ -511,6 +669,7 @@ func (s *Store) ListSessions(ctx context.Context) ([]SessionInfo, error) {
 			TasksCompleted: completed,
 			LastActivity:   lastActivity,
 			Model:          state.Model,
+			Usage:          state.Usage,
 		}
 		infos = append(infos, info)
 	}
@@ -530,32 +689,86 @@ func (s *Store) ListSessions(ctx context.Context) ([]SessionInfo, error) {
 }
 
 // LoadState reconstructs the current state of a session by reading and reducing
-// all events from the JetStream event log. This implements the event sourcing pattern.
+// events from the JetStream event log. This implements the event sourcing pattern.
+// If a snapshot exists, only events published after it are replayed; a new
+// snapshot is saved once the replayed tail reaches the snapshot interval.
 func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
 	logger.Debug("Loading state for session: %s", session)
 
+	// Start from the latest snapshot, or an empty state
+	snap := s.loadSnapshot(ctx, session)
+	state := snap.State
+	if state == nil {
+		state = &State{
+			Session: session,
+			Tasks:   make(map[string]*Task),
+		}
+	}
+
+	totalEvents, lastSeq, err := s.forEachEventFrom(ctx, session, snap.Seq+1, func(event Event) {
+		// Apply event to state (reduce)
+		state.Apply(event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("State loaded: %d tail events after seq %d, %d tasks, %d notes, %d iterations",
+		totalEvents, snap.Seq, len(state.Tasks), len(state.Notes), len(state.Iterations))
+
+	if s.snapshotInterval > 0 && totalEvents >= s.snapshotInterval {
+		s.saveSnapshot(ctx, snap, lastSeq, state)
+	}
+
+	return state, nil
+}
+
+// Events returns every event recorded for a session in stream order.
+// Event IDs that were not set at publish time are filled from the stream sequence,
+// matching what LoadState applies.
+func (s *Store) Events(ctx context.Context, session string) ([]Event, error) {
+	var events []Event
+	if _, err := s.forEachEvent(ctx, session, func(event Event) {
+		events = append(events, event)
+	}); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// forEachEvent reads all events for a session from the beginning of the stream
+// and calls fn for each one in order. Malformed events are skipped.
+// Returns the number of messages read (including malformed ones).
+func (s *Store) forEachEvent(ctx context.Context, session string, fn func(Event)) (int, error) {
+	count, _, err := s.forEachEventFrom(ctx, session, 1, fn)
+	return count, err
+}
+
+// forEachEventFrom is forEachEvent starting at stream sequence startSeq.
+// Also returns the stream sequence of the last message read (0 if none).
+func (s *Store) forEachEventFrom(ctx context.Context, session string, startSeq uint64, fn func(Event)) (int, uint64, error) {
 	// Create a consumer filtered to this session's events
-	consumer, err := s.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+	cfg := jetstream.ConsumerConfig{
 		FilterSubject: nats.SubjectForSession(session),
 		DeliverPolicy: jetstream.DeliverAllPolicy, // Start from beginning
 		AckPolicy:     jetstream.AckExplicitPolicy,
-	})
+	}
+	if startSeq > 1 {
+		cfg.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		cfg.OptStartSeq = startSeq
+	}
+	consumer, err := s.stream.CreateOrUpdateConsumer(ctx, cfg)
 	if err != nil {
 		logger.Error("Failed to create consumer for session %s: %v", session, err)
-		return nil, fmt.Errorf("failed to create consumer: %w", err)
-	}
-
-	// Initialize empty state
-	state := &State{
-		Session: session,
-		Tasks:   make(map[string]*Task),
+		return 0, 0, fmt.Errorf("failed to create consumer: %w", err)
 	}
 
-	// Fetch events in batches and reduce into state
+	// Fetch events in batches
 	// Using a large batch size to minimize round trips
 	const batchSize = 1000
 	malformedCount := 0
 	totalEvents := 0
+	var lastSeq uint64
 	for {
 		// Fetch with short timeout to avoid blocking forever
 		msgs, err := consumer.FetchNoWait(batchSize)
@@ -569,6 +782,9 @@ func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
 		for msg := range msgs.Messages() {
 			msgCount++
 			totalEvents++
+			if meta, err := msg.Metadata(); err == nil {
+				lastSeq = meta.Sequence.Stream
+			}
 			// Unmarshal event
 			var event Event
 			if err := json.Unmarshal(msg.Data(), &event); err != nil {
@@ -586,8 +802,7 @@ func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
 				event.ID = fmt.Sprintf("%d", meta.Sequence.Stream)
 			}
 
-			// Apply event to state (reduce)
-			state.Apply(event)
+			fn(event)
 
 			// Acknowledge message
 			_ = msg.Ack()
@@ -606,8 +821,5 @@ func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
 		logger.Warn("Skipped %d malformed events while loading state", malformedCount)
 	}
 
-	logger.Debug("State loaded: %d total events, %d tasks, %d notes, %d iterations",
-		totalEvents, len(state.Tasks), len(state.Notes), len(state.Iterations))
-
-	return state, nil
+	return totalEvents, lastSeq, nil
 }
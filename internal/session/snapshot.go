@@ -0,0 +1,128 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// DefaultSnapshotInterval is the number of replayed events after which
+// LoadState saves a fresh snapshot.
+const DefaultSnapshotInterval = 200
+
+// snapshotVersion is the encoding version of stored snapshots.
+// Bump when State changes in a way old snapshots cannot represent;
+// snapshots with another version are ignored and rebuilt from events.
+const snapshotVersion = 1
+
+// snapshot is a session State as of a stream sequence, stored in the
+// snapshot bucket under the session name.
+type snapshot struct {
+	Version int    `json:"version"`
+	Seq     uint64 `json:"seq"`   // Stream sequence of the last event applied
+	State   *State `json:"state"` // State after applying every event up to Seq
+
+	revision uint64 // KV revision the snapshot was read at (0 if none)
+}
+
+// snapshotBucket returns the snapshot KV bucket, creating it on first use.
+// Snapshots are an optimization: callers fall back to full replay on error.
+func (s *Store) snapshotBucket(ctx context.Context) (jetstream.KeyValue, error) {
+	s.snapshotsMu.Lock()
+	defer s.snapshotsMu.Unlock()
+	if s.snapshots != nil {
+		return s.snapshots, nil
+	}
+	kv, err := nats.SetupSnapshotBucket(ctx, s.js)
+	if err != nil {
+		return nil, err
+	}
+	s.snapshots = kv
+	return kv, nil
+}
+
+// loadSnapshot returns the latest usable snapshot for a session.
+// Returns an empty snapshot (Seq 0, nil State) if there is none or it cannot be used.
+func (s *Store) loadSnapshot(ctx context.Context, session string) snapshot {
+	if s.snapshotInterval <= 0 {
+		return snapshot{}
+	}
+	kv, err := s.snapshotBucket(ctx)
+	if err != nil {
+		logger.Warn("Snapshots unavailable, replaying all events: %v", err)
+		return snapshot{}
+	}
+
+	entry, err := kv.Get(ctx, session)
+	if err != nil {
+		if !errors.Is(err, jetstream.ErrKeyNotFound) {
+			logger.Warn("Failed to read snapshot for session '%s': %v", session, err)
+		}
+		return snapshot{}
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(entry.Value(), &snap); err != nil {
+		logger.Warn("Ignoring malformed snapshot for session '%s': %v", session, err)
+		return snapshot{revision: entry.Revision()}
+	}
+	if snap.Version != snapshotVersion || snap.State == nil {
+		logger.Debug("Ignoring snapshot for session '%s' with version %d", session, snap.Version)
+		return snapshot{revision: entry.Revision()}
+	}
+	// A snapshot past the end of the stream predates a stream rebuild
+	info, err := s.stream.Info(ctx)
+	if err != nil || snap.Seq > info.State.LastSeq {
+		logger.Debug("Ignoring stale snapshot for session '%s' at seq %d", session, snap.Seq)
+		return snapshot{revision: entry.Revision()}
+	}
+	if snap.State.Tasks == nil {
+		snap.State.Tasks = make(map[string]*Task)
+	}
+	snap.revision = entry.Revision()
+
+	logger.Debug("Loaded snapshot for session '%s' at seq %d", session, snap.Seq)
+	return snap
+}
+
+// saveSnapshot stores state as the snapshot for its session at seq.
+// The write is conditional on the revision prev was read at, so a concurrent
+// loader that saved a newer snapshot is never overwritten with an older one.
+func (s *Store) saveSnapshot(ctx context.Context, prev snapshot, seq uint64, state *State) {
+	kv, err := s.snapshotBucket(ctx)
+	if err != nil {
+		return
+	}
+
+	data, err := json.Marshal(snapshot{Version: snapshotVersion, Seq: seq, State: state})
+	if err != nil {
+		logger.Warn("Failed to marshal snapshot for session '%s': %v", state.Session, err)
+		return
+	}
+
+	if prev.revision == 0 {
+		_, err = kv.Create(ctx, state.Session, data)
+	} else {
+		_, err = kv.Update(ctx, state.Session, data, prev.revision)
+	}
+	if err != nil {
+		logger.Debug("Skipped snapshot for session '%s' at seq %d: %v", state.Session, seq, err)
+		return
+	}
+	logger.Debug("Saved snapshot for session '%s' at seq %d", state.Session, seq)
+}
+
+// deleteSnapshot removes the snapshot for a session, if any.
+func (s *Store) deleteSnapshot(ctx context.Context, session string) {
+	kv, err := s.snapshotBucket(ctx)
+	if err != nil {
+		return
+	}
+	if err := kv.Purge(ctx, session); err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		logger.Warn("Failed to delete snapshot for session '%s': %v", session, err)
+	}
+}
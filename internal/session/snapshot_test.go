@@ -0,0 +1,215 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// newSnapshotTestStore starts an embedded NATS server and returns a store on it.
+func newSnapshotTestStore(tb testing.TB) (*Store, jetstream.JetStream, jetstream.Stream) {
+	tb.Helper()
+	ns, _, err := nats.StartEmbeddedNATS(tb.TempDir())
+	if err != nil {
+		tb.Fatalf("failed to start NATS: %v", err)
+	}
+	tb.Cleanup(ns.Shutdown)
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		tb.Fatalf("failed to connect to NATS: %v", err)
+	}
+	tb.Cleanup(nc.Close)
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		tb.Fatalf("failed to create JetStream: %v", err)
+	}
+
+	stream, err := nats.SetupStream(context.Background(), js)
+	if err != nil {
+		tb.Fatalf("failed to setup stream: %v", err)
+	}
+
+	return NewStore(js, stream), js, stream
+}
+
+// seedTaskEvents publishes n task events (adds followed by status changes)
+// directly, without loading state between them.
+func seedTaskEvents(tb testing.TB, store *Store, session string, n int) {
+	tb.Helper()
+	ctx := context.Background()
+	statuses := []string{"in_progress", "completed", "blocked"}
+	for i := 0; i < n; i++ {
+		event := Event{Session: session, Type: nats.EventTypeTask}
+		taskNum := i/2 + 1
+		if i%2 == 0 {
+			event.ID = fmt.Sprintf("TAS-%d", taskNum)
+			event.Action = "add"
+			event.Data = fmt.Sprintf("Task %d", taskNum)
+			event.Meta = json.RawMessage(`{"status":"remaining","iteration":1}`)
+		} else {
+			event.Action = "status"
+			event.Data = statuses[taskNum%len(statuses)]
+			event.Meta = json.RawMessage(fmt.Sprintf(`{"task_id":"TAS-%d","iteration":1}`, taskNum))
+		}
+		if _, err := store.PublishEvent(ctx, event); err != nil {
+			tb.Fatalf("failed to publish event %d: %v", i, err)
+		}
+	}
+}
+
+func TestLoadStateSnapshots(t *testing.T) {
+	ctx := context.Background()
+	store, js, stream := newSnapshotTestStore(t)
+	store.snapshotInterval = 10
+
+	// A second store on the same stream that never uses snapshots
+	fullReplay := NewStore(js, stream)
+	fullReplay.snapshotInterval = 0
+
+	session := "snap-test"
+	other := "snap-other"
+
+	assertEquivalent := func(t *testing.T, name string) {
+		t.Helper()
+		got, err := store.LoadState(ctx, name)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		want, err := fullReplay.LoadState(ctx, name)
+		if err != nil {
+			t.Fatalf("full replay LoadState failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			gotJSON, _ := json.MarshalIndent(got, "", "  ")
+			wantJSON, _ := json.MarshalIndent(want, "", "  ")
+			t.Fatalf("snapshot+tail state differs from full replay\ngot:  %s\nwant: %s", gotJSON, wantJSON)
+		}
+	}
+
+	t.Run("snapshot plus tail equals full replay", func(t *testing.T) {
+		// Interleave every event type with loads so snapshots are taken mid-stream
+		for iter := 1; iter <= 8; iter++ {
+			if err := store.IterationStart(ctx, session, iter); err != nil {
+				t.Fatalf("IterationStart failed: %v", err)
+			}
+			for j := 0; j < 3; j++ {
+				task, err := store.TaskAdd(ctx, session, TaskAddParams{
+					Content:   fmt.Sprintf("iter %d task %d", iter, j),
+					Priority:  j,
+					Iteration: iter,
+				})
+				if err != nil {
+					t.Fatalf("TaskAdd failed: %v", err)
+				}
+				if err := store.TaskStatus(ctx, session, TaskStatusParams{ID: task.ID, Status: "completed", Iteration: iter}); err != nil {
+					t.Fatalf("TaskStatus failed: %v", err)
+				}
+			}
+			if _, err := store.NoteAdd(ctx, session, NoteAddParams{Content: fmt.Sprintf("note %d", iter), Type: "learning", Iteration: iter}); err != nil {
+				t.Fatalf("NoteAdd failed: %v", err)
+			}
+			if err := store.IterationSummary(ctx, session, iter, fmt.Sprintf("summary %d", iter), []string{"TAS-1"}); err != nil {
+				t.Fatalf("IterationSummary failed: %v", err)
+			}
+			if err := store.IterationComplete(ctx, session, iter); err != nil {
+				t.Fatalf("IterationComplete failed: %v", err)
+			}
+			if err := store.SetSessionModel(ctx, session, fmt.Sprintf("model-%d", iter)); err != nil {
+				t.Fatalf("SetSessionModel failed: %v", err)
+			}
+			assertEquivalent(t, session)
+		}
+
+		// Events for another session must not leak into this session's snapshot
+		seedTaskEvents(t, store, other, 25)
+		assertEquivalent(t, other)
+		assertEquivalent(t, session)
+
+		snap := store.loadSnapshot(ctx, session)
+		if snap.State == nil || snap.Seq == 0 {
+			t.Fatal("expected a snapshot to have been saved")
+		}
+	})
+
+	t.Run("reset discards snapshot", func(t *testing.T) {
+		if err := store.ResetSession(ctx, session); err != nil {
+			t.Fatalf("ResetSession failed: %v", err)
+		}
+		if snap := store.loadSnapshot(ctx, session); snap.State != nil {
+			t.Fatalf("expected no snapshot after reset, got seq %d", snap.Seq)
+		}
+
+		if _, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "after reset"}); err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if len(state.Tasks) != 1 || state.Tasks["TAS-1"] == nil {
+			t.Errorf("expected only TAS-1 after reset, got %d tasks", len(state.Tasks))
+		}
+		assertEquivalent(t, session)
+	})
+
+	t.Run("unusable snapshots are ignored", func(t *testing.T) {
+		kv, err := store.snapshotBucket(ctx)
+		if err != nil {
+			t.Fatalf("snapshotBucket failed: %v", err)
+		}
+
+		for _, value := range []string{
+			`not json`,
+			`{"version":999,"seq":1,"state":{"session":"snap-other"}}`,
+			`{"version":1,"seq":999999,"state":{"session":"snap-other"}}`,
+		} {
+			if _, err := kv.Put(ctx, other, []byte(value)); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+			assertEquivalent(t, other)
+		}
+	})
+}
+
+func BenchmarkLoadState(b *testing.B) {
+	const events = 5000
+	ctx := context.Background()
+
+	for _, bc := range []struct {
+		name     string
+		interval int
+	}{
+		{"full_replay", 0},
+		{"snapshot", DefaultSnapshotInterval},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			store, _, _ := newSnapshotTestStore(b)
+			store.snapshotInterval = bc.interval
+			session := "bench"
+			seedTaskEvents(b, store, session, events)
+
+			// Warm up: takes the initial snapshot when enabled
+			if _, err := store.LoadState(ctx, session); err != nil {
+				b.Fatalf("LoadState failed: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				state, err := store.LoadState(ctx, session)
+				if err != nil {
+					b.Fatalf("LoadState failed: %v", err)
+				}
+				if len(state.Tasks) != events/2 {
+					b.Fatalf("expected %d tasks, got %d", events/2, len(state.Tasks))
+				}
+			}
+		})
+	}
+}
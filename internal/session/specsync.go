@@ -0,0 +1,122 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// specTaskMarker matches the hidden comment linking a checklist item to its
+// task, e.g. "<!-- iteratr:TAS-3 -->".
+var specTaskMarker = regexp.MustCompile(`\s*<!--\s*iteratr:(TAS-\d+)\s*-->`)
+
+// SyncChecklist updates the checkboxes of a spec's checklist items to match
+// the status of their tasks: completed tasks are checked; remaining,
+// in-progress, and blocked ones unchecked; cancelled ones are left alone.
+// Items are linked to tasks by a hidden "<!-- iteratr:TAS-N -->" comment.
+// Items without one are matched by content to a task created from the spec
+// (TaskOriginSpec) and get the comment appended, so the link survives later
+// edits to either text. Other tasks that happen to share an item's wording
+// are never linked. Returns the new content and whether anything changed.
+func SyncChecklist(content []byte, state *State) ([]byte, bool) {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+
+	// First pass: items already linked, so content matching skips their tasks
+	linked := make(map[string]bool)
+	forEachChecklistLine(lines, func(_ int, line string, _ []int) {
+		if m := specTaskMarker.FindStringSubmatch(line); m != nil {
+			linked[m[1]] = true
+		}
+	})
+	byContent := make(map[string]string)
+	for id, task := range state.Tasks {
+		if linked[id] || task.Origin != TaskOriginSpec {
+			continue
+		}
+		key := normalizeTaskContent(task.Content)
+		if _, dup := byContent[key]; dup {
+			byContent[key] = "" // Ambiguous: link neither
+			continue
+		}
+		byContent[key] = id
+	}
+
+	changed := false
+	forEachChecklistLine(lines, func(i int, line string, loc []int) {
+		var id string
+		if m := specTaskMarker.FindStringSubmatch(line); m != nil {
+			id = m[1]
+		} else {
+			text, _ := extractPriority(line[loc[6]:loc[7]])
+			id = byContent[normalizeTaskContent(text)]
+			if id == "" {
+				return
+			}
+			delete(byContent, normalizeTaskContent(text))
+			line = fmt.Sprintf("%s <!-- iteratr:%s -->", line, id)
+		}
+
+		task := state.Tasks[id]
+		if task != nil {
+			mark := line[loc[4]:loc[5]]
+			switch task.Status {
+			case "completed":
+				if mark == " " {
+					mark = "x"
+				}
+			case "cancelled":
+			default:
+				mark = " "
+			}
+			line = line[:loc[4]] + mark + line[loc[5]:]
+		}
+
+		if newLine := line + lineEnding(lines[i]); newLine != string(lines[i]) {
+			lines[i] = []byte(newLine)
+			changed = true
+		}
+	})
+	if !changed {
+		return content, false
+	}
+	return bytes.Join(lines, nil), true
+}
+
+// forEachChecklistLine calls fn for each checklist item outside fenced code
+// blocks with the line (without its line ending) and the checklistItem
+// submatch indices into it.
+func forEachChecklistLine(lines [][]byte, fn func(i int, line string, loc []int)) {
+	inFence := false
+	for i, raw := range lines {
+		line := strings.TrimRight(string(raw), "\r\n")
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if loc := checklistItem.FindStringSubmatchIndex(line); loc != nil {
+			fn(i, line, loc)
+		}
+	}
+}
+
+// lineEnding returns the line ending of a line ("\r\n", "\n", or "").
+func lineEnding(line []byte) string {
+	switch {
+	case bytes.HasSuffix(line, []byte("\r\n")):
+		return "\r\n"
+	case bytes.HasSuffix(line, []byte("\n")):
+		return "\n"
+	}
+	return ""
+}
+
+// normalizeTaskContent folds case and whitespace for matching checklist text
+// against task content.
+func normalizeTaskContent(content string) string {
+	content = specTaskMarker.ReplaceAllString(content, "")
+	return strings.ToLower(strings.Join(strings.Fields(content), " "))
+}
@@ -0,0 +1,53 @@
+package session
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSyncChecklist(t *testing.T) {
+	state := &State{Tasks: map[string]*Task{
+		"TAS-1": {ID: "TAS-1", Content: "Login endpoint", Status: "completed", Origin: TaskOriginSpec},
+		"TAS-2": {ID: "TAS-2", Content: "User model, renamed in the TUI", Status: "remaining"},
+		"TAS-3": {ID: "TAS-3", Content: "password  HASHING", Status: "in_progress", Origin: TaskOriginSpec},
+		"TAS-4": {ID: "TAS-4", Content: "Drop legacy auth", Status: "cancelled", Origin: TaskOriginSpec},
+		"TAS-5": {ID: "TAS-5", Content: "Example", Status: "completed", Origin: TaskOriginSpec},
+		"TAS-6": {ID: "TAS-6", Content: "Not a task", Status: "completed"}, // Added later, not from the spec
+	}}
+	spec := "# Auth\r\n" +
+		"- [ ] Login endpoint [P1]\r\n" +
+		"  - [x] User model <!-- iteratr:TAS-2 -->\r\n" +
+		"  - [ ] Password hashing\r\n" +
+		"- [x] Drop legacy auth\r\n" +
+		"- [ ] Not a task\r\n" +
+		"```\r\n- [ ] Example\r\n```\r\n" +
+		"- [ ] Docs <!-- iteratr:TAS-9 -->"
+
+	got, changed := SyncChecklist([]byte(spec), state)
+	if !changed {
+		t.Fatal("SyncChecklist() reported no change")
+	}
+	want := "# Auth\r\n" +
+		"- [x] Login endpoint [P1] <!-- iteratr:TAS-1 -->\r\n" +
+		"  - [ ] User model <!-- iteratr:TAS-2 -->\r\n" +
+		"  - [ ] Password hashing <!-- iteratr:TAS-3 -->\r\n" +
+		"- [x] Drop legacy auth <!-- iteratr:TAS-4 -->\r\n" +
+		"- [ ] Not a task\r\n" +
+		"```\r\n- [ ] Example\r\n```\r\n" +
+		"- [ ] Docs <!-- iteratr:TAS-9 -->"
+	if string(got) != want {
+		t.Errorf("SyncChecklist() =\n%q\nwant\n%q", got, want)
+	}
+
+	// Synced content is stable and still imports cleanly
+	if again, changed := SyncChecklist(got, state); changed || string(again) != want {
+		t.Errorf("second SyncChecklist() changed the spec: %q", again)
+	}
+	tasks, err := ParseChecklist(bytes.NewReader(got))
+	if err != nil {
+		t.Fatalf("ParseChecklist() error: %v", err)
+	}
+	if len(tasks) != 6 || tasks[0].Content != "Login endpoint" || tasks[1].Content != "User model" {
+		t.Errorf("ParseChecklist() of synced spec = %+v, want links stripped", tasks)
+	}
+}
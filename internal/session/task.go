@@ -14,9 +14,55 @@ import (
 // TaskAddParams represents the parameters for adding a task.
 type TaskAddParams struct {
 	Content   string `json:"content"`
-	Status    string `json:"status,omitempty"`   // Optional: remaining, in_progress, completed, blocked, cancelled
-	Priority  int    `json:"priority,omitempty"` // Optional: 0=critical, 1=high, 2=medium, 3=low, 4=backlog
+	Status    string `json:"status,omitempty"`    // Optional: remaining, in_progress, completed, blocked, cancelled
+	Priority  int    `json:"priority,omitempty"`  // Optional: 0=critical, 1=high, 2=medium, 3=low, 4=backlog
+	ParentID  string `json:"parent_id,omitempty"` // Optional: task ID or prefix this is a subtask of
 	Iteration int    `json:"iteration"`
+	Origin    string `json:"origin,omitempty"` // Optional: TaskOriginSpec; defaults to it during planning
+
+	// Optional details, see Task
+	Description        string   `json:"description,omitempty"`
+	AcceptanceCriteria []string `json:"acceptance_criteria,omitempty"`
+	Labels             []string `json:"labels,omitempty"`
+	Estimate           string   `json:"estimate,omitempty"`
+}
+
+// TaskOriginSpec is the origin of tasks created from the spec: imported from
+// its checklist or added during the planning iteration (#0). Only these are
+// linked to the spec's checklist items by content (see SyncChecklist).
+const TaskOriginSpec = "spec"
+
+// planning reports whether the planning iteration (#0) is running, so tasks
+// added now are the agent's breakdown of the spec.
+func planning(state *State) bool {
+	if len(state.Iterations) == 0 {
+		return false
+	}
+	last := state.Iterations[len(state.Iterations)-1]
+	return last.Number == 0 && !last.Complete && !last.TimedOut && !last.RolledBack
+}
+
+// addDetailsMeta copies the optional parent, origin, and task details into an
+// add event's metadata, leaving unset ones out.
+func (p TaskAddParams) addDetailsMeta(metaMap map[string]any) {
+	if p.ParentID != "" {
+		metaMap["parent_id"] = p.ParentID
+	}
+	if p.Origin != "" {
+		metaMap["origin"] = p.Origin
+	}
+	if p.Description != "" {
+		metaMap["description"] = p.Description
+	}
+	if len(p.AcceptanceCriteria) > 0 {
+		metaMap["acceptance_criteria"] = p.AcceptanceCriteria
+	}
+	if len(p.Labels) > 0 {
+		metaMap["labels"] = p.Labels
+	}
+	if p.Estimate != "" {
+		metaMap["estimate"] = p.Estimate
+	}
 }
 
 // TaskStatusParams represents the parameters for updating task status.
@@ -66,6 +112,16 @@ func (s *Store) TaskAdd(ctx context.Context, session string, params TaskAddParam
 		return nil, fmt.Errorf("task already exists with ID %s: %q", existingID, params.Content)
 	}
 
+	// Resolve the parent task (supports prefix matching)
+	if params.ParentID != "" {
+		if params.ParentID, err = resolveTaskID(state, params.ParentID); err != nil {
+			return nil, fmt.Errorf("failed to resolve parent task: %w", err)
+		}
+	}
+	if params.Origin == "" && planning(state) {
+		params.Origin = TaskOriginSpec
+	}
+
 	// Generate sequential ID and timestamp
 	id := fmt.Sprintf("TAS-%d", state.TaskCounter+1)
 	now := time.Now()
@@ -79,6 +135,7 @@ func (s *Store) TaskAdd(ctx context.Context, session string, params TaskAddParam
 	if params.Priority != 0 {
 		metaMap["priority"] = params.Priority
 	}
+	params.addDetailsMeta(metaMap)
 	meta, _ := json.Marshal(metaMap)
 
 	// Create and publish event
@@ -102,9 +159,16 @@ func (s *Store) TaskAdd(ctx context.Context, session string, params TaskAddParam
 		ID:        id,
 		Content:   params.Content,
 		Status:    status,
+		ParentID:  params.ParentID,
 		CreatedAt: now,
 		UpdatedAt: now,
 		Iteration: params.Iteration,
+		Origin:    params.Origin,
+
+		Description:        params.Description,
+		AcceptanceCriteria: params.AcceptanceCriteria,
+		Labels:             params.Labels,
+		Estimate:           params.Estimate,
 	}
 
 	return task, nil
@@ -144,6 +208,7 @@ func (s *Store) TaskBatchAdd(ctx context.Context, session string, tasks []TaskAd
 	counter := state.TaskCounter
 	now := time.Now()
 	result := make([]*Task, 0, len(tasks))
+	batchIDs := make(map[string]bool, len(tasks))
 
 	for _, params := range tasks {
 		if params.Content == "" {
@@ -158,8 +223,19 @@ func (s *Store) TaskBatchAdd(ctx context.Context, session string, tasks []TaskAd
 			return nil, fmt.Errorf("invalid status: %s (must be remaining, in_progress, completed, blocked, or cancelled)", status)
 		}
 
+		// Parents may be existing tasks or tasks earlier in the batch
+		if params.ParentID != "" && !batchIDs[params.ParentID] {
+			if params.ParentID, err = resolveTaskID(state, params.ParentID); err != nil {
+				return nil, fmt.Errorf("failed to resolve parent task of %q: %w", params.Content, err)
+			}
+		}
+		if params.Origin == "" && planning(state) {
+			params.Origin = TaskOriginSpec
+		}
+
 		counter++
 		id := fmt.Sprintf("TAS-%d", counter)
+		batchIDs[id] = true
 
 		metaMap := map[string]any{
 			"status":    status,
@@ -169,6 +245,7 @@ func (s *Store) TaskBatchAdd(ctx context.Context, session string, tasks []TaskAd
 		if params.Priority != 0 {
 			metaMap["priority"] = params.Priority
 		}
+		params.addDetailsMeta(metaMap)
 		meta, _ := json.Marshal(metaMap)
 
 		event := Event{
@@ -190,9 +267,16 @@ func (s *Store) TaskBatchAdd(ctx context.Context, session string, tasks []TaskAd
 			ID:        id,
 			Content:   params.Content,
 			Status:    status,
+			ParentID:  params.ParentID,
 			CreatedAt: now,
 			UpdatedAt: now,
 			Iteration: params.Iteration,
+			Origin:    params.Origin,
+
+			Description:        params.Description,
+			AcceptanceCriteria: params.AcceptanceCriteria,
+			Labels:             params.Labels,
+			Estimate:           params.Estimate,
 		})
 	}
 
@@ -201,6 +285,8 @@ func (s *Store) TaskBatchAdd(ctx context.Context, session string, tasks []TaskAd
 
 // TaskStatus updates the status of an existing task.
 // The ID parameter supports prefix matching (minimum 3 characters).
+// Completing or cancelling the last open subtask of a parent closes the
+// parent as well.
 func (s *Store) TaskStatus(ctx context.Context, session string, params TaskStatusParams) error {
 	// Validate required fields
 	if params.ID == "" {
@@ -243,8 +329,15 @@ func (s *Store) TaskStatus(ctx context.Context, session string, params TaskStatu
 		Meta:    meta,
 	}
 
-	_, err = s.PublishEvent(ctx, event)
-	return err
+	if _, err := s.PublishEvent(ctx, event); err != nil {
+		return err
+	}
+
+	// Close parents whose subtasks are now all done
+	if isTerminalTaskStatus(params.Status) && state.Tasks[taskID].ParentID != "" {
+		return s.completeParents(ctx, session, taskID, params.Iteration)
+	}
+	return nil
 }
 
 // TaskPriorityParams represents the parameters for updating task priority.
@@ -344,6 +437,89 @@ func (s *Store) TaskContent(ctx context.Context, session string, params TaskCont
 	return err
 }
 
+// TaskDetailsParams represents the parameters for updating a task's
+// optional details. Nil fields are left unchanged; a pointer to an empty
+// value (or an empty, non-nil slice) clears the field.
+type TaskDetailsParams struct {
+	ID                 string   `json:"id"` // Task ID or prefix (3+ chars)
+	Description        *string  `json:"description,omitempty"`
+	AcceptanceCriteria []string `json:"acceptance_criteria,omitempty"`
+	Labels             []string `json:"labels,omitempty"`
+	Estimate           *string  `json:"estimate,omitempty"`
+	Iteration          int      `json:"iteration"`
+}
+
+// TaskDetails updates the description, acceptance criteria, labels and/or
+// estimate of an existing task, publishing one event per changed field.
+// Lists replace the previous value rather than appending to it.
+func (s *Store) TaskDetails(ctx context.Context, session string, params TaskDetailsParams) error {
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+	if params.Description == nil && params.AcceptanceCriteria == nil && params.Labels == nil && params.Estimate == nil {
+		return fmt.Errorf("at least one of description, acceptance criteria, labels or estimate is required")
+	}
+
+	// Load current state to resolve task ID prefix
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	taskID, err := resolveTaskID(state, params.ID)
+	if err != nil {
+		return err
+	}
+
+	publish := func(action, data string, metaMap map[string]any) error {
+		metaMap["task_id"] = taskID
+		metaMap["iteration"] = params.Iteration
+		meta, _ := json.Marshal(metaMap)
+		_, err := s.PublishEvent(ctx, Event{
+			Session: session,
+			Type:    nats.EventTypeTask,
+			Action:  action,
+			Data:    data,
+			Meta:    meta,
+		})
+		return err
+	}
+
+	if params.Description != nil {
+		if err := publish("description", strings.TrimSpace(*params.Description), map[string]any{}); err != nil {
+			return err
+		}
+	}
+	if params.AcceptanceCriteria != nil {
+		criteria := cleanTaskList(params.AcceptanceCriteria)
+		if err := publish("acceptance", strings.Join(criteria, "\n"), map[string]any{"acceptance_criteria": criteria}); err != nil {
+			return err
+		}
+	}
+	if params.Labels != nil {
+		labels := cleanTaskList(params.Labels)
+		if err := publish("labels", strings.Join(labels, ","), map[string]any{"labels": labels}); err != nil {
+			return err
+		}
+	}
+	if params.Estimate != nil {
+		if err := publish("estimate", strings.TrimSpace(*params.Estimate), map[string]any{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanTaskList trims the entries of a detail list and drops empty ones.
+func cleanTaskList(items []string) []string {
+	cleaned := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			cleaned = append(cleaned, item)
+		}
+	}
+	return cleaned
+}
+
 // TaskDeleteParams represents the parameters for deleting a task.
 type TaskDeleteParams struct {
 	ID        string `json:"id"`        // Task ID (exact match)
@@ -524,6 +700,7 @@ func findTaskByContent(state *State, content string) string {
 
 // TaskNext returns the highest priority unblocked task.
 // A task is "ready" if it has status "remaining" and all its dependencies are completed.
+// Leaf tasks are preferred over parents whose subtasks are still open.
 // Returns nil if no ready tasks exist.
 func (s *Store) TaskNext(ctx context.Context, session string) (*Task, error) {
 	// Load current state
@@ -532,7 +709,9 @@ func (s *Store) TaskNext(ctx context.Context, session string) (*Task, error) {
 		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
 
+	children := taskChildren(state)
 	var bestTask *Task
+	bestIsLeaf := false
 	for _, task := range state.Tasks {
 		// Skip non-remaining tasks
 		if task.Status != "remaining" {
@@ -558,11 +737,15 @@ func (s *Store) TaskNext(ctx context.Context, session string) (*Task, error) {
 			continue
 		}
 
-		// This task is ready - compare priority (lower is higher priority)
+		// This task is ready - prefer leaf tasks over parents that still have
+		// open subtasks, then compare priority (lower is higher priority)
 		// Use ID as tiebreaker for deterministic selection among equal priorities
-		if bestTask == nil || task.Priority < bestTask.Priority ||
-			(task.Priority == bestTask.Priority && task.ID < bestTask.ID) {
+		isLeaf := !hasOpenSubtasks(children, task.ID)
+		if bestTask == nil || (isLeaf && !bestIsLeaf) ||
+			(isLeaf == bestIsLeaf && (task.Priority < bestTask.Priority ||
+				(task.Priority == bestTask.Priority && task.ID < bestTask.ID))) {
 			bestTask = task
+			bestIsLeaf = isLeaf
 		}
 	}
 
@@ -817,4 +817,94 @@ func TestTaskOperations(t *testing.T) {
 			t.Errorf("expected 3 tasks, got %d", len(tasks))
 		}
 	})
+
+	t.Run("TaskDetails sets and clears task details", func(t *testing.T) {
+		detailsSession := "test-session-details"
+
+		_, err := store.TaskAdd(ctx, detailsSession, TaskAddParams{
+			Content:            "Add login endpoint",
+			Description:        "POST /login returning a session cookie",
+			AcceptanceCriteria: []string{"returns 401 on bad password", "sets HttpOnly cookie"},
+			Labels:             []string{"backend"},
+			Estimate:           "2h",
+			Iteration:          1,
+		})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+
+		description := ""
+		err = store.TaskDetails(ctx, detailsSession, TaskDetailsParams{
+			ID:                 "TAS-1",
+			Description:        &description,
+			AcceptanceCriteria: []string{" rate limited ", ""},
+			Labels:             []string{"backend", "auth"},
+			Iteration:          2,
+		})
+		if err != nil {
+			t.Fatalf("TaskDetails failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, detailsSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		task := state.Tasks["TAS-1"]
+		if task.Description != "" {
+			t.Errorf("expected description cleared, got %q", task.Description)
+		}
+		if strings.Join(task.AcceptanceCriteria, "|") != "rate limited" {
+			t.Errorf("expected criteria replaced, got %v", task.AcceptanceCriteria)
+		}
+		if strings.Join(task.Labels, ",") != "backend,auth" {
+			t.Errorf("expected labels replaced, got %v", task.Labels)
+		}
+		if task.Estimate != "2h" {
+			t.Errorf("expected estimate unchanged, got %q", task.Estimate)
+		}
+		if task.Iteration != 2 {
+			t.Errorf("expected iteration 2, got %d", task.Iteration)
+		}
+
+		if err := store.TaskDetails(ctx, detailsSession, TaskDetailsParams{ID: "TAS-1"}); err == nil {
+			t.Error("expected error when no details are given")
+		}
+		if err := store.TaskDetails(ctx, detailsSession, TaskDetailsParams{ID: "TAS-9", Labels: []string{"x"}}); err == nil {
+			t.Error("expected error for unknown task")
+		}
+	})
+
+	t.Run("tasks added during planning come from the spec", func(t *testing.T) {
+		originSession := "test-session-origin"
+
+		if _, err := store.TaskAdd(ctx, originSession, TaskAddParams{Content: "Before planning"}); err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		if err := store.IterationStart(ctx, originSession, 0); err != nil {
+			t.Fatalf("IterationStart failed: %v", err)
+		}
+		if _, err := store.TaskBatchAdd(ctx, originSession, []TaskAddParams{{Content: "Planned"}}); err != nil {
+			t.Fatalf("TaskBatchAdd failed: %v", err)
+		}
+		if err := store.IterationComplete(ctx, originSession, 0); err != nil {
+			t.Fatalf("IterationComplete failed: %v", err)
+		}
+		if _, err := store.TaskAdd(ctx, originSession, TaskAddParams{Content: "After planning"}); err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		if _, err := store.TaskAdd(ctx, originSession, TaskAddParams{Content: "Imported", Origin: TaskOriginSpec}); err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, originSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		want := map[string]string{"TAS-1": "", "TAS-2": TaskOriginSpec, "TAS-3": "", "TAS-4": TaskOriginSpec}
+		for id, origin := range want {
+			if got := state.Tasks[id].Origin; got != origin {
+				t.Errorf("%s (%q) origin = %q, want %q", id, state.Tasks[id].Content, got, origin)
+			}
+		}
+	})
 }
@@ -0,0 +1,427 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// ImportTask is a task parsed from a markdown checklist or an issue export,
+// to be added to a session with Store.ImportTasks.
+type ImportTask struct {
+	Content   string
+	Status    string // remaining, completed, or cancelled
+	Priority  int    // 0-4 (0=critical, 1=high, 2=medium, 3=low, 4=backlog)
+	DependsOn []int  // Indices of the tasks this task depends on
+	Origin    string // TaskOriginSpec for checklist items, empty for issues
+}
+
+// TaskImportResult reports what Store.ImportTasks did.
+type TaskImportResult struct {
+	Added        []*Task // Tasks created, in import order
+	Skipped      int     // Tasks whose content already existed in the session
+	Duplicates   int     // Repeats of a task earlier in the import, merged into it
+	Dependencies int     // Dependencies recorded
+}
+
+var (
+	// checklistItem matches "- [ ] task", "* [x] task", and "1. [ ] task"
+	checklistItem = regexp.MustCompile(`^(\s*)(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.*)$`)
+	// listItem matches any list item, used to end the nesting of checklist items
+	listItem = regexp.MustCompile(`^(\s*)(?:[-*+]|\d+[.)])\s`)
+	// priorityMarker matches "[P1]", "(p0)", "[high]", or "(backlog)"
+	priorityMarker = regexp.MustCompile(`(?i)[\[(](p[0-4]|critical|high|medium|low|backlog)[\])]`)
+	// issueDependency matches "depends on #12" and "blocked by #3, #4"
+	issueDependency = regexp.MustCompile(`(?i)(?:depends on|blocked by)((?:[\s,]*(?:and\s+)?#\d+)+)`)
+	// issueTaskList matches a task list item tracking another issue: "- [ ] #12"
+	issueTaskList = regexp.MustCompile(`(?m)^\s*[-*+]\s+\[[ xX]\]\s+#(\d+)\b`)
+	issueRef      = regexp.MustCompile(`#(\d+)`)
+)
+
+// priorityNames maps priority words to their numeric level.
+var priorityNames = map[string]int{
+	"critical": 0,
+	"high":     1,
+	"medium":   2,
+	"low":      3,
+	"backlog":  4,
+}
+
+// ParseChecklist parses the markdown checklist items of a spec into tasks.
+// "- [x]" items are completed, "- [ ]" items remaining. A checklist item
+// nested under another one becomes a dependency of it: the parent is only
+// done once its sub-items are. Priority markers such as "[P1]" or "(high)"
+// set the priority (default medium) and are removed from the content, as are
+// the task links written by SyncChecklist. Fenced code blocks are skipped.
+// The tasks have TaskOriginSpec, so SyncChecklist links them to their items.
+func ParseChecklist(r io.Reader) ([]ImportTask, error) {
+	type open struct {
+		indent int
+		index  int
+	}
+	var tasks []ImportTask
+	var stack []open
+	inFence := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		indent := -1
+		if m := listItem.FindStringSubmatch(line); m != nil {
+			indent = indentWidth(m[1])
+		} else if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			// Headings and paragraphs end any list
+			stack = stack[:0]
+			continue
+		}
+		if indent < 0 {
+			// Continuation line of a list item
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		m := checklistItem.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		content, priority := extractPriority(specTaskMarker.ReplaceAllString(m[3], ""))
+		if content == "" {
+			continue
+		}
+		status := "remaining"
+		if m[2] != " " {
+			status = "completed"
+		}
+
+		index := len(tasks)
+		tasks = append(tasks, ImportTask{Content: content, Status: status, Priority: priority, Origin: TaskOriginSpec})
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1].index
+			tasks[parent].DependsOn = append(tasks[parent].DependsOn, index)
+		}
+		stack = append(stack, open{indent: indent, index: index})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checklist: %w", err)
+	}
+	return tasks, nil
+}
+
+// indentWidth returns the width of leading whitespace, counting tabs as 4.
+func indentWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// extractPriority removes the first priority marker from content and returns
+// the cleaned content with its priority (medium if there is no marker).
+func extractPriority(content string) (string, int) {
+	priority := 2
+	loc := priorityMarker.FindStringSubmatchIndex(content)
+	if loc != nil {
+		priority = parsePriority(content[loc[2]:loc[3]])
+		content = content[:loc[0]] + " " + content[loc[1]:]
+	}
+	return strings.Join(strings.Fields(content), " "), priority
+}
+
+// parsePriority converts "P0"-"P4", "0"-"4", or a priority name to its level.
+// Returns -1 if the value is not a priority.
+func parsePriority(value string) int {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if p, ok := priorityNames[value]; ok {
+		return p
+	}
+	value = strings.TrimPrefix(value, "p")
+	if p, err := strconv.Atoi(value); err == nil && p >= 0 && p <= 4 {
+		return p
+	}
+	return -1
+}
+
+// issueExport is one issue of a GitHub issue export, in either the REST API
+// shape or the shape of `gh issue list --json`.
+type issueExport struct {
+	Number           int             `json:"number"`
+	Title            string          `json:"title"`
+	Body             string          `json:"body"`
+	State            string          `json:"state"`
+	StateReason      string          `json:"state_reason"`
+	StateReasonCamel string          `json:"stateReason"`
+	Labels           []issueLabel    `json:"labels"`
+	PullRequest      json.RawMessage `json:"pull_request"`
+}
+
+type issueLabel struct {
+	Name string `json:"name"`
+}
+
+// ParseIssues parses a JSON array of GitHub issues, as written by the REST
+// API or `gh issue list --json number,title,body,state,labels`, into tasks
+// ordered by issue number. Closed issues are completed (cancelled if closed
+// as not planned). Labels like "P1" or "priority: high" set the priority.
+// "Depends on #12" or "Blocked by #12" in the body, and task list items
+// tracking other issues ("- [ ] #12"), become dependencies on those issues
+// if they are in the export. Pull requests are skipped.
+func ParseIssues(data []byte) ([]ImportTask, error) {
+	var issues []issueExport
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, fmt.Errorf("invalid issue export (want a JSON array of issues): %w", err)
+	}
+	issues = slices.DeleteFunc(issues, func(issue issueExport) bool {
+		return strings.TrimSpace(issue.Title) == "" || (len(issue.PullRequest) > 0 && string(issue.PullRequest) != "null")
+	})
+	slices.SortStableFunc(issues, func(a, b issueExport) int { return a.Number - b.Number })
+
+	byNumber := make(map[int]int, len(issues))
+	for i, issue := range issues {
+		if issue.Number > 0 {
+			byNumber[issue.Number] = i
+		}
+	}
+
+	tasks := make([]ImportTask, len(issues))
+	for i, issue := range issues {
+		content := strings.Join(strings.Fields(issue.Title), " ")
+		if issue.Number > 0 {
+			content = fmt.Sprintf("%s (#%d)", content, issue.Number)
+		}
+
+		status := "remaining"
+		if strings.EqualFold(issue.State, "closed") {
+			status = "completed"
+			reason := issue.StateReason
+			if reason == "" {
+				reason = issue.StateReasonCamel
+			}
+			if strings.EqualFold(reason, "not_planned") {
+				status = "cancelled"
+			}
+		}
+
+		priority := 2
+		for _, label := range issue.Labels {
+			if p := labelPriority(label.Name); p >= 0 {
+				priority = p
+				break
+			}
+		}
+
+		var deps []int
+		for _, number := range issueReferences(issue.Body) {
+			if j, ok := byNumber[number]; ok && j != i && !slices.Contains(deps, j) {
+				deps = append(deps, j)
+			}
+		}
+
+		tasks[i] = ImportTask{Content: content, Status: status, Priority: priority, DependsOn: deps}
+	}
+	return tasks, nil
+}
+
+// labelPriority returns the priority a label stands for ("P1", "priority: high",
+// "priority/critical", "low"), or -1 if it is not a priority label.
+func labelPriority(name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if rest, ok := strings.CutPrefix(name, "priority"); ok {
+		name = strings.TrimLeft(rest, " :/-_=")
+	}
+	return parsePriority(name)
+}
+
+// issueReferences returns the issue numbers an issue body depends on.
+func issueReferences(body string) []int {
+	var numbers []int
+	for _, m := range issueDependency.FindAllStringSubmatch(body, -1) {
+		for _, ref := range issueRef.FindAllStringSubmatch(m[1], -1) {
+			if n, err := strconv.Atoi(ref[1]); err == nil {
+				numbers = append(numbers, n)
+			}
+		}
+	}
+	for _, m := range issueTaskList.FindAllStringSubmatch(body, -1) {
+		if n, err := strconv.Atoi(m[1]); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+// ImportTasks adds imported tasks to a session with a single TaskBatchAdd and
+// then records their priorities and dependencies. Tasks whose content already
+// exists in the session are skipped, so importing the same file twice is a
+// no-op; dependencies on skipped tasks point at the existing ones. Tasks
+// repeated within the import are added once, and dependencies on any of the
+// repeats point at that task. Dependency cycles between imported tasks are
+// rejected before anything is added.
+func (s *Store) ImportTasks(ctx context.Context, session string, tasks []ImportTask) (*TaskImportResult, error) {
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	result := &TaskImportResult{}
+	ids := make([]string, len(tasks))
+	canon := make([]int, len(tasks)) // Index of the first task with the same content
+	first := make(map[string]int)
+	var params []TaskAddParams
+	var added []int // Indices into tasks of the tasks to add
+	for i, task := range tasks {
+		if task.Content == "" {
+			return nil, fmt.Errorf("task %d has no content", i+1)
+		}
+		if task.Priority < 0 || task.Priority > 4 {
+			return nil, fmt.Errorf("invalid priority for %q: %d (must be 0-4)", task.Content, task.Priority)
+		}
+		// Same normalization as TaskBatchAdd's duplicate check
+		key := strings.ToLower(strings.TrimSpace(task.Content))
+		if j, ok := first[key]; ok {
+			canon[i] = j
+			result.Duplicates++
+			continue
+		}
+		first[key] = i
+		canon[i] = i
+		if existingID := findTaskByContent(state, task.Content); existingID != "" {
+			ids[i] = existingID
+			result.Skipped++
+			continue
+		}
+		params = append(params, TaskAddParams{
+			Content:  task.Content,
+			Status:   task.Status,
+			Priority: task.Priority, // 0 (critical) is omitted by TaskBatchAdd and set below
+			Origin:   task.Origin,
+		})
+		added = append(added, i)
+	}
+
+	// Dependencies from and to repeated tasks apply to their first occurrence
+	deps := make(map[int][]int)
+	for i, task := range tasks {
+		from := canon[i]
+		for _, dep := range task.DependsOn {
+			if dep < 0 || dep >= len(tasks) {
+				continue
+			}
+			to := canon[dep]
+			if to != from && !slices.Contains(deps[from], to) {
+				deps[from] = append(deps[from], to)
+			}
+		}
+	}
+	// Existing tasks never depend on new ones, so only new tasks can form a
+	// cycle. TaskDepends does not check for cycles.
+	if cycle := dependencyCycle(added, deps); cycle != nil {
+		names := make([]string, len(cycle))
+		for k, i := range cycle {
+			names[k] = strconv.Quote(tasks[i].Content)
+		}
+		return nil, fmt.Errorf("dependency cycle between imported tasks: %s", strings.Join(names, " -> "))
+	}
+
+	if len(params) > 0 {
+		created, err := s.TaskBatchAdd(ctx, session, params)
+		if err != nil {
+			return nil, err
+		}
+		for j, task := range created {
+			ids[added[j]] = task.ID
+		}
+		result.Added = created
+	}
+	for i := range tasks {
+		ids[i] = ids[canon[i]]
+	}
+
+	for j, i := range added {
+		task := result.Added[j]
+		task.Priority = tasks[i].Priority
+		if task.Priority == 0 {
+			if err := s.TaskPriority(ctx, session, TaskPriorityParams{ID: task.ID, Priority: 0}); err != nil {
+				return nil, fmt.Errorf("failed to set priority of %s: %w", task.ID, err)
+			}
+		}
+		for _, dep := range deps[i] {
+			if err := s.TaskDepends(ctx, session, TaskDependsParams{ID: task.ID, DependsOn: ids[dep]}); err != nil {
+				return nil, fmt.Errorf("failed to add dependency %s -> %s: %w", task.ID, ids[dep], err)
+			}
+			task.DependsOn = append(task.DependsOn, ids[dep])
+			result.Dependencies++
+		}
+	}
+
+	logger.Debug("Imported %d tasks into session '%s' (%d skipped, %d duplicates, %d dependencies)",
+		len(result.Added), session, result.Skipped, result.Duplicates, result.Dependencies)
+	return result, nil
+}
+
+// dependencyCycle returns a cycle in deps among the given task indices as a
+// path that starts and ends with the same task, or nil if there is none.
+func dependencyCycle(nodes []int, deps map[int][]int) []int {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	inGraph := make(map[int]bool, len(nodes))
+	for _, n := range nodes {
+		inGraph[n] = true
+	}
+	mark := make(map[int]int, len(nodes))
+	var path []int
+	var visit func(n int) []int
+	visit = func(n int) []int {
+		mark[n] = visiting
+		path = append(path, n)
+		for _, dep := range deps[n] {
+			if !inGraph[dep] {
+				continue
+			}
+			switch mark[dep] {
+			case visiting:
+				return append(slices.Clone(path[slices.Index(path, dep):]), dep)
+			case 0:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		mark[n] = visited
+		return nil
+	}
+	for _, n := range nodes {
+		if mark[n] == 0 {
+			if cycle := visit(n); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
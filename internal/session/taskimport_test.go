@@ -0,0 +1,177 @@
+package session
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChecklist(t *testing.T) {
+	spec := `# Auth
+
+## Overview
+- Plain bullets are not tasks
+- [ ] Checklists outside the Tasks section count too
+
+## Tasks
+- [ ] Login endpoint [P1]
+  - [x] User model
+  - [ ] Password hashing (critical)
+    continuation of the hashing item
+  - Notes about login
+    - [ ] Rate limiting
+* [ ] (low) Docs
+
+` + "```" + `
+- [ ] Example inside a code block
+` + "```" + `
+
+1. [X] Numbered item
+`
+	got, err := ParseChecklist(strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("ParseChecklist() error: %v", err)
+	}
+	want := []ImportTask{
+		{Content: "Checklists outside the Tasks section count too", Status: "remaining", Priority: 2, Origin: TaskOriginSpec},
+		{Content: "Login endpoint", Status: "remaining", Priority: 1, DependsOn: []int{2, 3, 4}, Origin: TaskOriginSpec},
+		{Content: "User model", Status: "completed", Priority: 2, Origin: TaskOriginSpec},
+		{Content: "Password hashing", Status: "remaining", Priority: 0, Origin: TaskOriginSpec},
+		{Content: "Rate limiting", Status: "remaining", Priority: 2, Origin: TaskOriginSpec},
+		{Content: "Docs", Status: "remaining", Priority: 3, Origin: TaskOriginSpec},
+		{Content: "Numbered item", Status: "completed", Priority: 2, Origin: TaskOriginSpec},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseChecklist() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseIssues(t *testing.T) {
+	export := `[
+	  {"number": 12, "title": "Add  login", "state": "OPEN", "body": "Depends on #10 and #11",
+	   "labels": [{"name": "enhancement"}, {"name": "priority: high"}]},
+	  {"number": 10, "title": "User model", "state": "closed", "state_reason": "completed", "body": "", "labels": []},
+	  {"number": 11, "title": "Drop legacy auth", "state": "CLOSED", "stateReason": "NOT_PLANNED", "labels": [{"name": "P0"}]},
+	  {"number": 13, "title": "Epic", "state": "open", "body": "- [ ] #12\n- [x] #99\nblocked by #13", "labels": null},
+	  {"number": 14, "title": "Fix typo", "state": "open", "pull_request": {"url": "x"}}
+	]`
+	got, err := ParseIssues([]byte(export))
+	if err != nil {
+		t.Fatalf("ParseIssues() error: %v", err)
+	}
+	want := []ImportTask{
+		{Content: "User model (#10)", Status: "completed", Priority: 2},
+		{Content: "Drop legacy auth (#11)", Status: "cancelled", Priority: 0},
+		{Content: "Add login (#12)", Status: "remaining", Priority: 1, DependsOn: []int{0, 1}},
+		{Content: "Epic (#13)", Status: "remaining", Priority: 2, DependsOn: []int{2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseIssues() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if _, err := ParseIssues([]byte(`{"number": 1}`)); err == nil {
+		t.Error("ParseIssues() accepted a JSON object, want an error")
+	}
+}
+
+func TestImportTasks(t *testing.T) {
+	store, _, _ := newSnapshotTestStore(t)
+	ctx := context.Background()
+	tasks := []ImportTask{
+		{Content: "Login endpoint", Status: "remaining", Priority: 1, DependsOn: []int{1, 2}, Origin: TaskOriginSpec},
+		{Content: "User model", Status: "completed", Priority: 2},
+		{Content: "Password hashing", Status: "remaining", Priority: 0},
+	}
+
+	result, err := store.ImportTasks(ctx, "import", tasks)
+	if err != nil {
+		t.Fatalf("ImportTasks() error: %v", err)
+	}
+	if len(result.Added) != 3 || result.Skipped != 0 || result.Dependencies != 2 {
+		t.Errorf("result = %d added, %d skipped, %d dependencies; want 3, 0, 2",
+			len(result.Added), result.Skipped, result.Dependencies)
+	}
+
+	state, err := store.LoadState(ctx, "import")
+	if err != nil {
+		t.Fatalf("LoadState() error: %v", err)
+	}
+	login := state.Tasks["TAS-1"]
+	if login == nil || login.Priority != 1 || !reflect.DeepEqual(login.DependsOn, []string{"TAS-2", "TAS-3"}) || login.Origin != TaskOriginSpec {
+		t.Errorf("TAS-1 = %+v, want priority 1 from the spec depending on TAS-2 and TAS-3", login)
+	}
+	if task := state.Tasks["TAS-2"]; task == nil || task.Status != "completed" || task.Origin != "" {
+		t.Errorf("TAS-2 = %+v, want completed without origin", task)
+	}
+	if task := state.Tasks["TAS-3"]; task == nil || task.Priority != 0 {
+		t.Errorf("TAS-3 = %+v, want critical priority", task)
+	}
+
+	// Importing again only adds the new task
+	tasks = append(tasks, ImportTask{Content: "Docs", Status: "remaining", Priority: 3, DependsOn: []int{0}})
+	result, err = store.ImportTasks(ctx, "import", tasks)
+	if err != nil {
+		t.Fatalf("second ImportTasks() error: %v", err)
+	}
+	if len(result.Added) != 1 || result.Skipped != 3 {
+		t.Errorf("second import: %d added, %d skipped; want 1, 3", len(result.Added), result.Skipped)
+	}
+	if docs := result.Added[0]; docs.ID != "TAS-4" || !reflect.DeepEqual(docs.DependsOn, []string{"TAS-1"}) {
+		t.Errorf("Docs = %+v, want TAS-4 depending on the existing TAS-1", docs)
+	}
+}
+
+func TestImportTasks_Duplicates(t *testing.T) {
+	store, _, _ := newSnapshotTestStore(t)
+	ctx := context.Background()
+	tasks := []ImportTask{
+		{Content: "Write tests", Status: "remaining", Priority: 2},
+		{Content: "Ship it", Status: "remaining", Priority: 2, DependsOn: []int{2}},
+		{Content: " write TESTS ", Status: "remaining", Priority: 2, DependsOn: []int{3}},
+		{Content: "Set up CI", Status: "remaining", Priority: 2},
+	}
+
+	result, err := store.ImportTasks(ctx, "dups", tasks)
+	if err != nil {
+		t.Fatalf("ImportTasks() error: %v", err)
+	}
+	if len(result.Added) != 3 || result.Duplicates != 1 || result.Dependencies != 2 {
+		t.Errorf("result = %d added, %d duplicates, %d dependencies; want 3, 1, 2",
+			len(result.Added), result.Duplicates, result.Dependencies)
+	}
+
+	state, err := store.LoadState(ctx, "dups")
+	if err != nil {
+		t.Fatalf("LoadState() error: %v", err)
+	}
+	// The repeat's dependency and the dependency on the repeat both use TAS-1
+	if task := state.Tasks["TAS-1"]; task == nil || !reflect.DeepEqual(task.DependsOn, []string{"TAS-3"}) {
+		t.Errorf("TAS-1 = %+v, want it to depend on TAS-3", task)
+	}
+	if task := state.Tasks["TAS-2"]; task == nil || !reflect.DeepEqual(task.DependsOn, []string{"TAS-1"}) {
+		t.Errorf("TAS-2 = %+v, want it to depend on TAS-1", task)
+	}
+}
+
+func TestImportTasks_Cycle(t *testing.T) {
+	store, _, _ := newSnapshotTestStore(t)
+	ctx := context.Background()
+	tasks := []ImportTask{
+		{Content: "A (#1)", Status: "remaining", Priority: 2, DependsOn: []int{1}},
+		{Content: "B (#2)", Status: "remaining", Priority: 2, DependsOn: []int{2}},
+		{Content: "C (#3)", Status: "remaining", Priority: 2, DependsOn: []int{0}},
+	}
+
+	_, err := store.ImportTasks(ctx, "cycle", tasks)
+	if err == nil || !strings.Contains(err.Error(), `"A (#1)" -> "B (#2)" -> "C (#3)" -> "A (#1)"`) {
+		t.Fatalf("ImportTasks() error = %v, want the dependency cycle", err)
+	}
+	state, err := store.LoadState(ctx, "cycle")
+	if err != nil {
+		t.Fatalf("LoadState() error: %v", err)
+	}
+	if len(state.Tasks) != 0 {
+		t.Errorf("cyclic import added %d tasks, want none", len(state.Tasks))
+	}
+}
@@ -0,0 +1,222 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// TaskParentParams represents the parameters for moving a task under a parent.
+type TaskParentParams struct {
+	ID        string `json:"id"`        // Task ID or prefix (3+ chars)
+	ParentID  string `json:"parent_id"` // Parent task ID or prefix; empty makes the task top-level
+	Iteration int    `json:"iteration"`
+}
+
+// TaskParent makes a task a subtask of another task, or top-level again when
+// ParentID is empty. A task cannot become a subtask of itself or of one of
+// its own subtasks.
+func (s *Store) TaskParent(ctx context.Context, session string, params TaskParentParams) error {
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+
+	// Load current state to resolve task ID prefixes
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	taskID, err := resolveTaskID(state, params.ID)
+	if err != nil {
+		return err
+	}
+	parentID := ""
+	if params.ParentID != "" {
+		if parentID, err = resolveTaskID(state, params.ParentID); err != nil {
+			return fmt.Errorf("failed to resolve parent task: %w", err)
+		}
+		if isTaskAncestor(state, taskID, parentID) {
+			return fmt.Errorf("task %s cannot be a subtask of itself or of its own subtask %s", taskID, parentID)
+		}
+	}
+
+	meta, _ := json.Marshal(map[string]any{
+		"task_id":   taskID,
+		"parent_id": parentID,
+		"iteration": params.Iteration,
+	})
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeTask,
+		Action:  "parent",
+		Data:    parentID, // Store parent ID in data field for convenience
+		Meta:    meta,
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	return err
+}
+
+// isTaskAncestor reports whether ancestorID is taskID itself or one of the
+// tasks on its parent chain.
+func isTaskAncestor(state *State, ancestorID, taskID string) bool {
+	seen := make(map[string]bool)
+	for id := taskID; id != "" && !seen[id]; {
+		if id == ancestorID {
+			return true
+		}
+		seen[id] = true
+		task, ok := state.Tasks[id]
+		if !ok {
+			return false
+		}
+		id = task.ParentID
+	}
+	return false
+}
+
+// completeParents closes the parents of taskID whose subtasks are now all
+// completed or cancelled, walking up the hierarchy. A parent becomes
+// completed if at least one subtask was completed, cancelled otherwise.
+func (s *Store) completeParents(ctx context.Context, session, taskID string, iteration int) error {
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	children := taskChildren(state)
+
+	seen := map[string]bool{taskID: true}
+	task, ok := state.Tasks[taskID]
+	for ok && task.ParentID != "" && !seen[task.ParentID] {
+		parent, exists := state.Tasks[task.ParentID]
+		if !exists || isTerminalTaskStatus(parent.Status) {
+			return nil
+		}
+		status := "cancelled"
+		for _, child := range children[parent.ID] {
+			if !isTerminalTaskStatus(child.Status) {
+				return nil
+			}
+			if child.Status == "completed" {
+				status = "completed"
+			}
+		}
+
+		meta, _ := json.Marshal(map[string]any{
+			"task_id":   parent.ID,
+			"status":    status,
+			"iteration": iteration,
+		})
+		event := Event{
+			Session: session,
+			Type:    nats.EventTypeTask,
+			Action:  "status",
+			Data:    status,
+			Meta:    meta,
+		}
+		if _, err := s.PublishEvent(ctx, event); err != nil {
+			return err
+		}
+		logger.Debug("Auto-%s parent task %s: all subtasks are done", status, parent.ID)
+
+		parent.Status = status
+		seen[parent.ID] = true
+		task = parent
+	}
+	return nil
+}
+
+// taskChildren maps each task ID to its direct subtasks.
+func taskChildren(state *State) map[string][]*Task {
+	children := make(map[string][]*Task)
+	for _, task := range state.Tasks {
+		if task.ParentID != "" {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		}
+	}
+	return children
+}
+
+// hasOpenSubtasks reports whether any direct subtask of id is not yet
+// completed or cancelled.
+func hasOpenSubtasks(children map[string][]*Task, id string) bool {
+	for _, child := range children[id] {
+		if !isTerminalTaskStatus(child.Status) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTerminalTaskStatus reports whether a task with the status needs no more work.
+func isTerminalTaskStatus(status string) bool {
+	return status == "completed" || status == "cancelled"
+}
+
+// TaskTreeItem is a task along with its nesting depth in a TaskTree.
+type TaskTreeItem struct {
+	Task  *Task
+	Depth int // 0 for top-level tasks
+}
+
+// TaskTree orders tasks depth-first so every subtask follows its parent, with
+// siblings sorted by less. Tasks whose parent is not among tasks are treated
+// as top-level.
+func TaskTree(tasks []*Task, less func(a, b *Task) bool) []TaskTreeItem {
+	byID := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = true
+	}
+
+	var roots []*Task
+	children := make(map[string][]*Task)
+	for _, task := range tasks {
+		if task.ParentID == "" || task.ParentID == task.ID || !byID[task.ParentID] {
+			roots = append(roots, task)
+		} else {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		}
+	}
+	sortTasks := func(list []*Task) {
+		sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+	}
+	sortTasks(roots)
+
+	result := make([]TaskTreeItem, 0, len(tasks))
+	visited := make(map[string]bool, len(tasks))
+	var walk func(task *Task, depth int)
+	walk = func(task *Task, depth int) {
+		if visited[task.ID] {
+			return
+		}
+		visited[task.ID] = true
+		result = append(result, TaskTreeItem{Task: task, Depth: depth})
+		kids := children[task.ID]
+		sortTasks(kids)
+		for _, child := range kids {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+
+	// Parent cycles have no root; list them at the top level
+	if len(result) < len(tasks) {
+		var rest []*Task
+		for _, task := range tasks {
+			if !visited[task.ID] {
+				rest = append(rest, task)
+			}
+		}
+		sortTasks(rest)
+		for _, task := range rest {
+			walk(task, 0)
+		}
+	}
+	return result
+}
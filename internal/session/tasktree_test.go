@@ -0,0 +1,111 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTaskTree(t *testing.T) {
+	tasks := []*Task{
+		{ID: "TAS-5", ParentID: "TAS-2"},
+		{ID: "TAS-1"},
+		{ID: "TAS-4", ParentID: "TAS-1"},
+		{ID: "TAS-2", ParentID: "TAS-1"},
+		{ID: "TAS-3", ParentID: "TAS-9"}, // Parent not listed
+		{ID: "TAS-6", ParentID: "TAS-7"}, // Cycle
+		{ID: "TAS-7", ParentID: "TAS-6"},
+	}
+	tree := TaskTree(tasks, func(a, b *Task) bool { return a.ID < b.ID })
+
+	var got []string
+	for _, item := range tree {
+		got = append(got, strings.Repeat(".", item.Depth)+item.Task.ID)
+	}
+	want := "TAS-1 .TAS-2 ..TAS-5 .TAS-4 TAS-3 TAS-6 .TAS-7"
+	if strings.Join(got, " ") != want {
+		t.Errorf("TaskTree() = %v, want %s", got, want)
+	}
+}
+
+func TestTaskHierarchy(t *testing.T) {
+	store, _, _ := newSnapshotTestStore(t)
+	ctx := context.Background()
+	name := "hierarchy"
+
+	// TAS-1 > TAS-2 > (TAS-3, TAS-4), TAS-1 > TAS-5, plus unrelated TAS-6
+	_, err := store.TaskBatchAdd(ctx, name, []TaskAddParams{
+		{Content: "Epic", Priority: 0},
+		{Content: "Feature", ParentID: "TAS-1"},
+		{Content: "Step one", ParentID: "TAS-2", Priority: 3},
+		{Content: "Step two", ParentID: "TAS-2", Priority: 3},
+		{Content: "Docs", ParentID: "TAS-1", Priority: 4},
+		{Content: "Unrelated", Priority: 1},
+	})
+	if err != nil {
+		t.Fatalf("TaskBatchAdd failed: %v", err)
+	}
+	if _, err := store.TaskAdd(ctx, name, TaskAddParams{Content: "Orphan", ParentID: "TAS-99"}); err == nil {
+		t.Error("expected error for unknown parent")
+	}
+
+	// Leaf tasks win over parents even with a worse priority
+	next, err := store.TaskNext(ctx, name)
+	if err != nil {
+		t.Fatalf("TaskNext failed: %v", err)
+	}
+	if next == nil || next.ID != "TAS-6" {
+		t.Errorf("TaskNext() = %+v, want TAS-6 (highest priority leaf)", next)
+	}
+
+	status := func(id, status string) {
+		t.Helper()
+		if err := store.TaskStatus(ctx, name, TaskStatusParams{ID: id, Status: status, Iteration: 1}); err != nil {
+			t.Fatalf("TaskStatus(%s, %s) failed: %v", id, status, err)
+		}
+	}
+	statuses := func() string {
+		t.Helper()
+		state, err := store.LoadState(ctx, name)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		var parts []string
+		for _, id := range []string{"TAS-1", "TAS-2", "TAS-3", "TAS-4", "TAS-5"} {
+			parts = append(parts, state.Tasks[id].Status)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	status("TAS-3", "completed")
+	if got := statuses(); got != "remaining remaining completed remaining remaining" {
+		t.Errorf("after one subtask: %s", got)
+	}
+
+	// Finishing TAS-4 closes TAS-2; TAS-1 still waits on TAS-5
+	status("TAS-4", "cancelled")
+	if got := statuses(); got != "remaining completed completed cancelled remaining" {
+		t.Errorf("after last step: %s", got)
+	}
+
+	// Closing TAS-5 closes TAS-1, as cancelled since no direct subtask was completed
+	if err := store.TaskParent(ctx, name, TaskParentParams{ID: "TAS-2", ParentID: ""}); err != nil {
+		t.Fatalf("TaskParent failed: %v", err)
+	}
+	status("TAS-5", "cancelled")
+	if got := statuses(); got != "cancelled completed completed cancelled cancelled" {
+		t.Errorf("after docs: %s", got)
+	}
+
+	t.Run("TaskParent rejects cycles", func(t *testing.T) {
+		if err := store.TaskParent(ctx, name, TaskParentParams{ID: "TAS-2", ParentID: "TAS-3"}); err == nil {
+			t.Error("expected error making a task a subtask of its own subtask")
+		}
+		if err := store.TaskParent(ctx, name, TaskParentParams{ID: "TAS-6", ParentID: "TAS-6"}); err == nil {
+			t.Error("expected error making a task its own subtask")
+		}
+		if err := store.TaskParent(ctx, name, TaskParentParams{ID: "TAS-6", ParentID: "TAS-2"}); err != nil {
+			t.Errorf("TaskParent failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,355 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Transcript entry kinds.
+const (
+	TranscriptKindPrompt   = "prompt"   // Prompt or message sent to the agent
+	TranscriptKindText     = "text"     // Assistant text
+	TranscriptKindThinking = "thinking" // Reasoning output
+	TranscriptKindTool     = "tool"     // Tool call, updated in place as its status changes
+	TranscriptKindFinish   = "finish"   // End of an agent turn
+)
+
+// DefaultTranscriptMaxBytes is the default size cap of one iteration's transcript.
+const DefaultTranscriptMaxBytes = 4 << 20
+
+// maxTranscriptField is the most text, tool output, or file content kept in
+// a single entry field; the rest is counted in the entry's Omitted bytes.
+const maxTranscriptField = 64 << 10
+
+// ErrTranscriptNotFound is returned when no transcript is stored for an iteration.
+var ErrTranscriptNotFound = errors.New("transcript not found")
+
+// Transcript is everything the agent said and did during one iteration.
+// It is stored as a single object in the transcript bucket, keyed by session
+// and iteration.
+type Transcript struct {
+	Session   string            `json:"session"`
+	Iteration int               `json:"iteration"`
+	Entries   []TranscriptEntry `json:"entries"`
+	Size      int               `json:"size"`              // Approximate content bytes of all entries
+	MaxBytes  int               `json:"max_bytes"`         // Size cap, 0 = unlimited
+	Dropped   int               `json:"dropped,omitempty"` // Entries and updates left out once the cap was reached
+}
+
+// TranscriptEntry is one event of a transcript.
+type TranscriptEntry struct {
+	Time     time.Time         `json:"time"`
+	Kind     string            `json:"kind"`
+	Subagent string            `json:"subagent,omitempty"` // Tool call ID of the subagent that produced the entry
+	Text     string            `json:"text,omitempty"`     // Prompt, text, or thinking content
+	Tool     *TranscriptTool   `json:"tool,omitempty"`
+	Finish   *TranscriptFinish `json:"finish,omitempty"`
+	Omitted  int               `json:"omitted,omitempty"` // Bytes cut from this entry's fields by the field cap
+}
+
+// TranscriptTool is the latest state of a tool call.
+type TranscriptTool struct {
+	ID     string          `json:"id"`
+	Title  string          `json:"title"`
+	Kind   string          `json:"kind,omitempty"`
+	Status string          `json:"status"`
+	Input  map[string]any  `json:"input,omitempty"`
+	Output string          `json:"output,omitempty"`
+	Diff   *TranscriptDiff `json:"diff,omitempty"`
+}
+
+// TranscriptDiff is a file edit made by a tool call.
+type TranscriptDiff struct {
+	File      string `json:"file"`
+	Before    string `json:"before,omitempty"`
+	After     string `json:"after,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// TranscriptFinish describes how an agent turn ended.
+type TranscriptFinish struct {
+	StopReason string        `json:"stop_reason"`
+	Error      string        `json:"error,omitempty"`
+	Model      string        `json:"model,omitempty"`
+	Provider   string        `json:"provider,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Usage      Usage         `json:"usage,omitzero"`
+}
+
+// NewTranscript returns an empty transcript for an iteration, capped at
+// maxBytes of content (0 = unlimited).
+func NewTranscript(session string, iteration, maxBytes int) *Transcript {
+	return &Transcript{Session: session, Iteration: iteration, MaxBytes: maxBytes}
+}
+
+// Add appends an entry. Streamed text and thinking chunks are merged into the
+// previous entry of the same kind and source, and tool calls replace their
+// earlier entry. Long fields are cut to maxTranscriptField, and once the
+// transcript reaches MaxBytes further content is only counted in Dropped;
+// finish entries are always kept.
+func (t *Transcript) Add(entry TranscriptEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	switch entry.Kind {
+	case TranscriptKindText, TranscriptKindThinking:
+		if n := len(t.Entries); n > 0 {
+			last := &t.Entries[n-1]
+			if last.Kind == entry.Kind && last.Subagent == entry.Subagent {
+				t.appendText(last, entry.Text)
+				return
+			}
+		}
+	case TranscriptKindTool:
+		if entry.Tool != nil {
+			for i := len(t.Entries) - 1; i >= 0; i-- {
+				if prev := t.Entries[i].Tool; prev != nil && prev.ID == entry.Tool.ID {
+					t.updateTool(&t.Entries[i], entry.Tool)
+					return
+				}
+			}
+		}
+	}
+
+	capEntry(&entry)
+	size := entrySize(entry)
+	if entry.Kind != TranscriptKindFinish && t.over(size) {
+		t.Dropped++
+		return
+	}
+	t.Size += size
+	t.Entries = append(t.Entries, entry)
+}
+
+// appendText adds a streamed chunk to a text or thinking entry.
+func (t *Transcript) appendText(entry *TranscriptEntry, text string) {
+	kept := truncateUTF8(text, maxTranscriptField-len(entry.Text))
+	if t.over(len(kept)) {
+		t.Dropped++
+		return
+	}
+	entry.Text += kept
+	entry.Omitted += len(text) - len(kept)
+	t.Size += len(kept)
+}
+
+// updateTool replaces the tool call of entry with a later update. If the
+// update does not fit in the cap, only the status is recorded.
+func (t *Transcript) updateTool(entry *TranscriptEntry, tool *TranscriptTool) {
+	updated := TranscriptEntry{Kind: TranscriptKindTool, Tool: tool}
+	capEntry(&updated)
+	oldSize, newSize := entrySize(*entry), entrySize(updated)
+	if t.over(newSize - oldSize) {
+		entry.Tool.Status = tool.Status
+		t.Dropped++
+		return
+	}
+	entry.Tool = updated.Tool
+	entry.Omitted = updated.Omitted
+	t.Size += newSize - oldSize
+}
+
+// over reports whether adding n bytes would exceed the size cap.
+func (t *Transcript) over(n int) bool {
+	return t.MaxBytes > 0 && n > 0 && t.Size+n > t.MaxBytes
+}
+
+// capEntry cuts the fields of entry to maxTranscriptField, counting what was
+// cut in entry.Omitted. The tool call and its input are copied, not modified.
+func capEntry(entry *TranscriptEntry) {
+	cut := func(s *string) {
+		kept := truncateUTF8(*s, maxTranscriptField)
+		entry.Omitted += len(*s) - len(kept)
+		*s = kept
+	}
+	entry.Omitted = 0
+	cut(&entry.Text)
+	if entry.Tool != nil {
+		tool := *entry.Tool
+		if tool.Input != nil {
+			tool.Input = capInput(tool.Input, cut).(map[string]any)
+		}
+		cut(&tool.Output)
+		if tool.Diff != nil {
+			diff := *tool.Diff
+			cut(&diff.Before)
+			cut(&diff.After)
+			tool.Diff = &diff
+		}
+		entry.Tool = &tool
+	}
+}
+
+// capInput returns a copy of a decoded JSON tool input with every string
+// value, at any depth, passed through cut (e.g. the content of a file write).
+func capInput(v any, cut func(*string)) any {
+	switch v := v.(type) {
+	case string:
+		cut(&v)
+		return v
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[k] = capInput(val, cut)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, val := range v {
+			s[i] = capInput(val, cut)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// truncateUTF8 returns at most the first n bytes of s, without splitting a rune.
+func truncateUTF8(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	n = max(n, 0)
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// entrySize returns the approximate content size of an entry in bytes.
+func entrySize(entry TranscriptEntry) int {
+	size := len(entry.Text)
+	if tool := entry.Tool; tool != nil {
+		size += len(tool.Title) + len(tool.Output)
+		if len(tool.Input) > 0 {
+			if input, err := json.Marshal(tool.Input); err == nil {
+				size += len(input)
+			}
+		}
+		if tool.Diff != nil {
+			size += len(tool.Diff.Before) + len(tool.Diff.After)
+		}
+	}
+	if entry.Finish != nil {
+		size += len(entry.Finish.Error)
+	}
+	return size
+}
+
+// transcriptBucket returns the transcript object store, creating it on first use.
+func (s *Store) transcriptBucket(ctx context.Context) (jetstream.ObjectStore, error) {
+	s.transcriptsMu.Lock()
+	defer s.transcriptsMu.Unlock()
+	if s.transcripts != nil {
+		return s.transcripts, nil
+	}
+	obs, err := nats.SetupTranscriptBucket(ctx, s.js, s.retention)
+	if err != nil {
+		return nil, err
+	}
+	s.transcripts = obs
+	return obs, nil
+}
+
+// transcriptName is the object name of an iteration's transcript.
+func transcriptName(session string, iteration int) string {
+	return fmt.Sprintf("%s/%d", session, iteration)
+}
+
+// SaveTranscript stores a transcript, replacing any earlier version of the
+// same iteration.
+func (s *Store) SaveTranscript(ctx context.Context, t *Transcript) error {
+	obs, err := s.transcriptBucket(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript bucket: %w", err)
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript: %w", err)
+	}
+	if _, err := obs.PutBytes(ctx, transcriptName(t.Session, t.Iteration), data); err != nil {
+		return fmt.Errorf("failed to store transcript: %w", err)
+	}
+	logger.Debug("Saved transcript for session '%s' iteration #%d (%d entries, %d bytes)", t.Session, t.Iteration, len(t.Entries), len(data))
+	return nil
+}
+
+// LoadTranscript returns the transcript of an iteration of a session.
+// Returns ErrTranscriptNotFound if none is stored.
+func (s *Store) LoadTranscript(ctx context.Context, session string, iteration int) (*Transcript, error) {
+	obs, err := s.transcriptBucket(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript bucket: %w", err)
+	}
+	data, err := obs.GetBytes(ctx, transcriptName(session, iteration))
+	if err != nil {
+		if errors.Is(err, jetstream.ErrObjectNotFound) {
+			return nil, fmt.Errorf("%w for iteration #%d of session %s", ErrTranscriptNotFound, iteration, session)
+		}
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	var t Transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid transcript for iteration #%d: %w", iteration, err)
+	}
+	return &t, nil
+}
+
+// TranscriptIterations returns the iterations of a session that have a
+// stored transcript, in ascending order.
+func (s *Store) TranscriptIterations(ctx context.Context, session string) ([]int, error) {
+	obs, err := s.transcriptBucket(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript bucket: %w", err)
+	}
+	infos, err := obs.List(ctx)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrNoObjectsFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list transcripts: %w", err)
+	}
+	var iterations []int
+	for _, info := range infos {
+		rest, ok := strings.CutPrefix(info.Name, session+"/")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(rest); err == nil {
+			iterations = append(iterations, n)
+		}
+	}
+	slices.Sort(iterations)
+	return iterations, nil
+}
+
+// deleteTranscripts removes all transcripts of a session.
+func (s *Store) deleteTranscripts(ctx context.Context, session string) {
+	iterations, err := s.TranscriptIterations(ctx, session)
+	if err != nil {
+		logger.Warn("Failed to list transcripts of session '%s': %v", session, err)
+		return
+	}
+	obs, err := s.transcriptBucket(ctx)
+	if err != nil {
+		return
+	}
+	for _, n := range iterations {
+		if err := obs.Delete(ctx, transcriptName(session, n)); err != nil && !errors.Is(err, jetstream.ErrObjectNotFound) {
+			logger.Warn("Failed to delete transcript of session '%s' iteration #%d: %v", session, n, err)
+		}
+	}
+}
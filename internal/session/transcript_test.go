@@ -0,0 +1,205 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestTranscriptAdd(t *testing.T) {
+	t.Run("merges streamed chunks", func(t *testing.T) {
+		tr := NewTranscript("s", 1, 0)
+		tr.Add(TranscriptEntry{Kind: TranscriptKindPrompt, Text: "Do it"})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Text: "Hello, "})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Text: "world"})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Subagent: "call-1", Text: "sub"})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindThinking, Text: "hmm"})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Text: "bye"})
+
+		var got []string
+		for _, e := range tr.Entries {
+			got = append(got, e.Kind+":"+e.Text)
+		}
+		want := []string{"prompt:Do it", "text:Hello, world", "text:sub", "thinking:hmm", "text:bye"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("entries = %v, want %v", got, want)
+		}
+		if tr.Size != len("Do itHello, worldsubhmmbye") {
+			t.Errorf("Size = %d", tr.Size)
+		}
+	})
+
+	t.Run("tool updates replace the call", func(t *testing.T) {
+		tr := NewTranscript("s", 1, 0)
+		tr.Add(TranscriptEntry{Kind: TranscriptKindTool, Tool: &TranscriptTool{ID: "t1", Title: "bash", Status: "pending"}})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Text: "running"})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindTool, Tool: &TranscriptTool{ID: "t1", Title: "bash", Status: "completed", Input: map[string]any{"command": "ls"}, Output: "a.go"}})
+		if len(tr.Entries) != 2 {
+			t.Fatalf("got %d entries, want 2", len(tr.Entries))
+		}
+		tool := tr.Entries[0].Tool
+		if tool.Status != "completed" || tool.Output != "a.go" || tool.Input["command"] != "ls" {
+			t.Errorf("tool = %+v, want the completed call", tool)
+		}
+		if want := len("bash") + len("a.go") + len(`{"command":"ls"}`) + len("running"); tr.Size != want {
+			t.Errorf("Size = %d, want %d", tr.Size, want)
+		}
+	})
+
+	t.Run("field cap", func(t *testing.T) {
+		tr := NewTranscript("s", 1, 0)
+		long := strings.Repeat("x", maxTranscriptField+10)
+		tr.Add(TranscriptEntry{Kind: TranscriptKindTool, Tool: &TranscriptTool{ID: "t1", Output: long, Diff: &TranscriptDiff{File: "f", After: long}}})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Text: long})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Text: "more"})
+
+		tool := tr.Entries[0]
+		if len(tool.Tool.Output) != maxTranscriptField || len(tool.Tool.Diff.After) != maxTranscriptField || tool.Omitted != 20 {
+			t.Errorf("tool not capped: output %d, after %d, omitted %d", len(tool.Tool.Output), len(tool.Tool.Diff.After), tool.Omitted)
+		}
+		text := tr.Entries[1]
+		if len(text.Text) != maxTranscriptField || text.Omitted != 14 {
+			t.Errorf("text not capped: %d bytes, omitted %d", len(text.Text), text.Omitted)
+		}
+	})
+
+	t.Run("field cap applies to tool input", func(t *testing.T) {
+		long := strings.Repeat("x", maxTranscriptField+10)
+		input := map[string]any{
+			"path":    "a.go",
+			"content": long,
+			"edits":   []any{map[string]any{"new": long}},
+			"line":    3.0,
+		}
+		tr := NewTranscript("s", 1, 0)
+		tr.Add(TranscriptEntry{Kind: TranscriptKindTool, Tool: &TranscriptTool{ID: "t1", Input: input}})
+
+		entry := tr.Entries[0]
+		got := entry.Tool.Input
+		nested := got["edits"].([]any)[0].(map[string]any)["new"].(string)
+		if len(got["content"].(string)) != maxTranscriptField || len(nested) != maxTranscriptField || entry.Omitted != 20 {
+			t.Errorf("input not capped: content %d, nested %d, omitted %d", len(got["content"].(string)), len(nested), entry.Omitted)
+		}
+		if got["path"] != "a.go" || got["line"] != 3.0 {
+			t.Errorf("short input values changed: %v", got)
+		}
+		if len(input["content"].(string)) != len(long) {
+			t.Error("capping should not modify the caller's input")
+		}
+	})
+
+	t.Run("field cap keeps runes whole", func(t *testing.T) {
+		tr := NewTranscript("s", 1, 0)
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Text: strings.Repeat("x", maxTranscriptField-1) + "é"})
+		if got := tr.Entries[0]; len(got.Text) != maxTranscriptField-1 || got.Omitted != 2 {
+			t.Errorf("got %d bytes, omitted %d", len(got.Text), got.Omitted)
+		}
+	})
+
+	t.Run("size cap drops entries but keeps finish", func(t *testing.T) {
+		tr := NewTranscript("s", 1, 10)
+		tr.Add(TranscriptEntry{Kind: TranscriptKindPrompt, Text: "12345678"})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Text: "too long"})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindTool, Tool: &TranscriptTool{ID: "t1", Title: "x"}})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindTool, Tool: &TranscriptTool{ID: "t1", Title: "x", Status: "completed", Output: "big output"}})
+		tr.Add(TranscriptEntry{Kind: TranscriptKindFinish, Finish: &TranscriptFinish{StopReason: "end_turn"}})
+
+		var kinds []string
+		for _, e := range tr.Entries {
+			kinds = append(kinds, e.Kind)
+		}
+		if want := []string{"prompt", "tool", "finish"}; !reflect.DeepEqual(kinds, want) {
+			t.Errorf("kinds = %v, want %v", kinds, want)
+		}
+		if tr.Dropped != 2 {
+			t.Errorf("Dropped = %d, want 2", tr.Dropped)
+		}
+		if tool := tr.Entries[1].Tool; tool.Status != "completed" || tool.Output != "" {
+			t.Errorf("over-cap tool update should only set the status, got %+v", tool)
+		}
+	})
+}
+
+func TestStoreTranscripts(t *testing.T) {
+	ctx := context.Background()
+	store, _, _ := newSnapshotTestStore(t)
+
+	if iterations, err := store.TranscriptIterations(ctx, "tr"); err != nil || len(iterations) != 0 {
+		t.Fatalf("TranscriptIterations() on empty bucket = %v, %v", iterations, err)
+	}
+	if _, err := store.LoadTranscript(ctx, "tr", 1); !errors.Is(err, ErrTranscriptNotFound) {
+		t.Fatalf("LoadTranscript() error = %v, want ErrTranscriptNotFound", err)
+	}
+
+	for _, n := range []int{10, 2, 0} {
+		tr := NewTranscript("tr", n, DefaultTranscriptMaxBytes)
+		tr.Add(TranscriptEntry{Kind: TranscriptKindText, Text: "iteration text"})
+		if err := store.SaveTranscript(ctx, tr); err != nil {
+			t.Fatalf("SaveTranscript(%d) failed: %v", n, err)
+		}
+	}
+	other := NewTranscript("tr-other", 1, 0)
+	if err := store.SaveTranscript(ctx, other); err != nil {
+		t.Fatalf("SaveTranscript failed: %v", err)
+	}
+
+	// Saving again replaces the earlier version
+	tr := NewTranscript("tr", 2, DefaultTranscriptMaxBytes)
+	tr.Add(TranscriptEntry{Kind: TranscriptKindTool, Tool: &TranscriptTool{ID: "t1", Title: "bash", Input: map[string]any{"command": "go test"}}})
+	tr.Add(TranscriptEntry{Kind: TranscriptKindFinish, Finish: &TranscriptFinish{StopReason: "end_turn", Usage: Usage{InputTokens: 5}}})
+	if err := store.SaveTranscript(ctx, tr); err != nil {
+		t.Fatalf("SaveTranscript failed: %v", err)
+	}
+	got, err := store.LoadTranscript(ctx, "tr", 2)
+	if err != nil {
+		t.Fatalf("LoadTranscript failed: %v", err)
+	}
+	if len(got.Entries) != 2 || got.Entries[0].Tool.Input["command"] != "go test" || got.Entries[1].Finish.Usage.InputTokens != 5 {
+		t.Errorf("LoadTranscript() = %+v, want the second version", got)
+	}
+
+	iterations, err := store.TranscriptIterations(ctx, "tr")
+	if err != nil {
+		t.Fatalf("TranscriptIterations failed: %v", err)
+	}
+	if !reflect.DeepEqual(iterations, []int{0, 2, 10}) {
+		t.Errorf("TranscriptIterations() = %v, want [0 2 10]", iterations)
+	}
+
+	// Resetting a session deletes its transcripts only
+	if err := store.ResetSession(ctx, "tr"); err != nil {
+		t.Fatalf("ResetSession failed: %v", err)
+	}
+	if iterations, _ := store.TranscriptIterations(ctx, "tr"); len(iterations) != 0 {
+		t.Errorf("transcripts left after reset: %v", iterations)
+	}
+	if iterations, _ := store.TranscriptIterations(ctx, "tr-other"); !reflect.DeepEqual(iterations, []int{1}) {
+		t.Errorf("other session's transcripts = %v, want [1]", iterations)
+	}
+}
+
+func TestStoreTranscripts_Retention(t *testing.T) {
+	ctx := context.Background()
+	store, js, _ := newSnapshotTestStore(t)
+	store.SetRetention(nats.Retention{MaxAge: 7 * 24 * time.Hour})
+
+	if err := store.SaveTranscript(ctx, NewTranscript("tr", 1, 0)); err != nil {
+		t.Fatalf("SaveTranscript failed: %v", err)
+	}
+	obs, err := js.ObjectStore(ctx, nats.TranscriptBucket)
+	if err != nil {
+		t.Fatalf("ObjectStore failed: %v", err)
+	}
+	status, err := obs.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.TTL() != 7*24*time.Hour {
+		t.Errorf("transcript TTL = %v, want the configured max age of 7 days", status.TTL())
+	}
+}
@@ -0,0 +1,119 @@
+package session
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Usage is token usage and estimated spend accumulated from agent turns.
+type Usage struct {
+	InputTokens         int64         `json:"input_tokens"`
+	OutputTokens        int64         `json:"output_tokens"`
+	CacheReadTokens     int64         `json:"cache_read_tokens"`
+	CacheCreationTokens int64         `json:"cache_creation_tokens"`
+	ReasoningTokens     int64         `json:"reasoning_tokens"`
+	Duration            time.Duration `json:"duration"`
+	Cost                float64       `json:"cost"` // Estimated USD (0 if the model has no price)
+}
+
+// Add accumulates other into u.
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheCreationTokens += other.CacheCreationTokens
+	u.ReasoningTokens += other.ReasoningTokens
+	u.Duration += other.Duration
+	u.Cost += other.Cost
+}
+
+// TotalTokens returns input plus output tokens.
+func (u Usage) TotalTokens() int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// IsZero reports whether no usage has been recorded.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read"`
+	CacheWrite float64 `json:"cache_write"`
+}
+
+// PriceTable maps model names to prices. Keys are either the full model
+// ("anthropic/claude-sonnet-4-5") or the name without its provider prefix.
+type PriceTable map[string]Price
+
+// Lookup returns the price for a model, preferring an exact match.
+func (p PriceTable) Lookup(model string) (Price, bool) {
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+	if idx := strings.Index(model, "/"); idx >= 0 {
+		price, ok := p[model[idx+1:]]
+		return price, ok
+	}
+	return Price{}, false
+}
+
+// Cost estimates the spend for usage on a model. Returns 0 for unpriced models.
+func (p PriceTable) Cost(model string, u Usage) float64 {
+	price, ok := p.Lookup(model)
+	if !ok {
+		return 0
+	}
+	return (float64(u.InputTokens)*price.Input +
+		float64(u.OutputTokens)*price.Output +
+		float64(u.CacheReadTokens)*price.CacheRead +
+		float64(u.CacheCreationTokens)*price.CacheWrite) / 1_000_000
+}
+
+// TaskUsage attributes each iteration's usage to the tasks it worked on,
+// split evenly between them. Iterations without worked tasks are not counted.
+func (st *State) TaskUsage() map[string]Usage {
+	result := make(map[string]Usage)
+	for _, iter := range st.Iterations {
+		if len(iter.TasksWorked) == 0 || iter.Usage.IsZero() {
+			continue
+		}
+		n := int64(len(iter.TasksWorked))
+		share := Usage{
+			InputTokens:         iter.Usage.InputTokens / n,
+			OutputTokens:        iter.Usage.OutputTokens / n,
+			CacheReadTokens:     iter.Usage.CacheReadTokens / n,
+			CacheCreationTokens: iter.Usage.CacheCreationTokens / n,
+			ReasoningTokens:     iter.Usage.ReasoningTokens / n,
+			Duration:            iter.Usage.Duration / time.Duration(n),
+			Cost:                iter.Usage.Cost / float64(n),
+		}
+		for _, id := range iter.TasksWorked {
+			u := result[id]
+			u.Add(share)
+			result[id] = u
+		}
+	}
+	return result
+}
+
+// FormatTokens formats a token count compactly (e.g. 950, 12.3k, 1.2M).
+func FormatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return trimFloat(float64(n)/1_000_000) + "M"
+	case n >= 1_000:
+		return trimFloat(float64(n)/1_000) + "k"
+	default:
+		return strconv.FormatInt(n, 10)
+	}
+}
+
+// trimFloat formats f with one decimal, dropping a trailing ".0".
+func trimFloat(f float64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(f, 'f', 1, 64), ".0")
+}
@@ -0,0 +1,70 @@
+package session
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPriceTableCost(t *testing.T) {
+	prices := PriceTable{
+		"anthropic/claude-sonnet-4-5": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+		"gpt-5":                       {Input: 1.25, Output: 10},
+	}
+	usage := Usage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadTokens: 2_000_000, CacheCreationTokens: 0}
+
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"anthropic/claude-sonnet-4-5", 3 + 1.5 + 0.6},
+		{"openai/gpt-5", 1.25 + 1},     // matched without provider prefix
+		{"gpt-5", 1.25 + 1},            // exact
+		{"anthropic/claude-opus-4", 0}, // unpriced
+		{"", 0},                        // no model
+	}
+	for _, tt := range tests {
+		if got := prices.Cost(tt.model, usage); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Cost(%q) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+
+	var none PriceTable
+	if got := none.Cost("gpt-5", usage); got != 0 {
+		t.Errorf("nil table Cost() = %v, want 0", got)
+	}
+}
+
+func TestStateTaskUsage(t *testing.T) {
+	st := &State{Iterations: []*Iteration{
+		{Number: 0, Usage: Usage{InputTokens: 500, Cost: 0.5}}, // planning: no tasks worked
+		{Number: 1, TasksWorked: []string{"TAS-1", "TAS-2"}, Usage: Usage{InputTokens: 1000, OutputTokens: 100, Cost: 1}},
+		{Number: 2, TasksWorked: []string{"TAS-2"}, Usage: Usage{InputTokens: 400, Cost: 0.4}},
+		{Number: 3, TasksWorked: []string{"TAS-3"}}, // no usage recorded
+	}}
+
+	got := st.TaskUsage()
+	if len(got) != 2 {
+		t.Fatalf("TaskUsage() = %v, want entries for TAS-1 and TAS-2", got)
+	}
+	if u := got["TAS-1"]; u.InputTokens != 500 || u.OutputTokens != 50 || math.Abs(u.Cost-0.5) > 1e-9 {
+		t.Errorf("TAS-1 usage = %+v, want half of iteration #1", u)
+	}
+	if u := got["TAS-2"]; u.InputTokens != 900 || math.Abs(u.Cost-0.9) > 1e-9 {
+		t.Errorf("TAS-2 usage = %+v, want half of #1 plus all of #2", u)
+	}
+}
+
+func TestFormatTokens(t *testing.T) {
+	tests := map[int64]string{
+		0:         "0",
+		950:       "950",
+		1000:      "1k",
+		12_345:    "12.3k",
+		1_250_000: "1.2M",
+	}
+	for n, want := range tests {
+		if got := FormatTokens(n); got != want {
+			t.Errorf("FormatTokens(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
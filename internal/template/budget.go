@@ -0,0 +1,243 @@
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// charsPerToken is the rough number of bytes per token used to estimate
+// prompt sizes without a tokenizer.
+const charsPerToken = 4
+
+// defaultHistory is the number of iteration summaries shown when nothing is trimmed.
+const defaultHistory = 5
+
+// PromptReport describes how a prompt was fitted into its token budget.
+type PromptReport struct {
+	Tokens  int      // Estimated prompt size in tokens
+	Budget  int      // Token budget, 0 = unlimited
+	Trimmed []string // What was summarized or left out, empty if nothing
+}
+
+// promptLimits controls how much session state goes into a prompt.
+type promptLimits struct {
+	history      int  // Most recent iteration summaries to show
+	notes        int  // Most recent notes to show besides "stuck" notes, -1 = all
+	foldDone     bool // Completed and cancelled tasks are only counted
+	compactTasks bool // Only in-progress and ready tasks show description and criteria
+	openOnly     bool // Only in-progress and ready tasks are listed one by one
+	cutSpec      bool // The spec is cut to specBytes
+	specBytes    int
+}
+
+// trimLevels are tried in order until a prompt fits its budget; the spec is
+// only cut once everything else is trimmed. The first level trims nothing.
+var trimLevels = []promptLimits{
+	{history: defaultHistory, notes: -1},
+	{history: 3, notes: 10, foldDone: true},
+	{history: 1, notes: 5, foldDone: true, compactTasks: true},
+	{history: 0, notes: 0, foldDone: true, compactTasks: true, openOnly: true},
+}
+
+// EstimateTokens returns a rough token count for s (about 4 bytes per token).
+func EstimateTokens(s string) int {
+	return (len(s) + charsPerToken - 1) / charsPerToken
+}
+
+// renderBudget renders tmpl, trimming the state sections level by level
+// (see trimLevels) until the prompt fits in budget tokens. Only the placeholder
+// sections are trimmed; Go templates reading .State directly see everything.
+func renderBudget(mode, tmpl string, data Data, budget int) (string, PromptReport, error) {
+	mode, err := resolveMode(mode, tmpl, data)
+	if err != nil {
+		return "", PromptReport{}, err
+	}
+	render := func(limits promptLimits) (string, []string, error) {
+		data.limits = limits
+		vars, trimmed := formatSections(data)
+		if mode == ModeLegacy {
+			return Render(tmpl, vars), trimmed, nil
+		}
+		result, err := RenderGo(tmpl, data)
+		return result, trimmed, err
+	}
+
+	var (
+		result  string
+		trimmed []string
+		limits  promptLimits
+	)
+	for _, limits = range trimLevels {
+		if result, trimmed, err = render(limits); err != nil {
+			return "", PromptReport{}, err
+		}
+		if budget <= 0 || EstimateTokens(result) <= budget {
+			return result, PromptReport{Tokens: EstimateTokens(result), Budget: budget, Trimmed: trimmed}, nil
+		}
+	}
+
+	// Still too big: cut the spec by the overflow
+	over := (EstimateTokens(result) - budget) * charsPerToken
+	limits.cutSpec = true
+	limits.specBytes = max(len(data.Spec)-over-len(specCutNote(data, 0)), 0)
+	if result, trimmed, err = render(limits); err != nil {
+		return "", PromptReport{}, err
+	}
+	report := PromptReport{Tokens: EstimateTokens(result), Budget: budget, Trimmed: trimmed}
+	if report.Tokens > budget {
+		logger.Warn("Prompt is ~%d tokens after trimming, over the budget of %d tokens", report.Tokens, budget)
+	}
+	return result, report, nil
+}
+
+// formatSections formats the state sections of data within data.limits and
+// describes everything that was left out.
+func formatSections(data Data) (Variables, []string) {
+	state := data.State
+	if state == nil {
+		state = &session.State{Tasks: map[string]*session.Task{}}
+	}
+	limits := data.limits
+	if limits == (promptLimits{}) {
+		limits = trimLevels[0]
+	}
+
+	var trimmed []string
+	note := func(s string) {
+		if s != "" {
+			trimmed = append(trimmed, s)
+		}
+	}
+	spec, specTrim := cutSpec(data, limits)
+	note(specTrim)
+	tasks, tasksTrim := formatTasksLimited(state, limits)
+	note(tasksTrim)
+	notes, notesTrim := formatNotesLimited(state, limits)
+	note(notesTrim)
+	history, historyTrim := formatHistoryLimited(state, limits)
+	note(historyTrim)
+
+	return Variables{
+		Session:   data.Session,
+		Iteration: strconv.Itoa(data.Iteration),
+		Spec:      spec,
+		Notes:     notes,
+		Tasks:     tasks,
+		History:   history,
+		Extra:     data.Extra,
+		Port:      strconv.Itoa(data.Port),
+		Binary:    data.Binary,
+	}, trimmed
+}
+
+// logSections logs the estimated size of each prompt section.
+func logSections(data Data) {
+	vars, _ := formatSections(data)
+	logger.Debug("Prompt sections: spec ~%d tokens, tasks ~%d, notes ~%d, history ~%d",
+		EstimateTokens(vars.Spec), EstimateTokens(vars.Tasks), EstimateTokens(vars.Notes), EstimateTokens(vars.History))
+}
+
+// cutSpec returns the spec, cut at a line break to limits.specBytes if
+// limits.cutSpec is set, with a note pointing at the full spec.
+func cutSpec(data Data, limits promptLimits) (string, string) {
+	spec := data.Spec
+	if !limits.cutSpec || limits.specBytes >= len(spec) {
+		return spec, ""
+	}
+	n := limits.specBytes
+	if i := strings.LastIndex(spec[:n], "\n"); i > 0 {
+		n = i
+	}
+	for n > 0 && !utf8.RuneStart(spec[n]) {
+		n--
+	}
+	return spec[:n] + specCutNote(data, len(spec)-n),
+		fmt.Sprintf("spec: cut to %d of %d bytes", n, len(spec))
+}
+
+// specCutNote is the line appended to a spec with omitted bytes left out.
+func specCutNote(data Data, omitted int) string {
+	where := "the spec file"
+	if data.Config.SpecPath != "" {
+		where = data.Config.SpecPath
+	}
+	return fmt.Sprintf("\n\n[... %d more bytes of the spec omitted; read the rest in %s]\n", omitted, where)
+}
+
+// formatHistoryLimited formats the last limits.history iteration summaries.
+func formatHistoryLimited(state *session.State, limits promptLimits) (string, string) {
+	var kept []*session.Iteration
+	for _, iter := range state.Iterations {
+		if iter.Summary != "" && !iter.RolledBack {
+			kept = append(kept, iter)
+		}
+	}
+	shown := min(len(kept), defaultHistory)
+	result := formatIterationSummaries(kept[len(kept)-min(len(kept), limits.history):])
+	if omitted := shown - min(len(kept), limits.history); omitted > 0 {
+		return result, fmt.Sprintf("history: %d of %d recent summaries omitted", omitted, shown)
+	}
+	return result, ""
+}
+
+// formatNotesLimited formats all "stuck" notes plus the limits.notes most
+// recent other notes, with a line counting the rest.
+func formatNotesLimited(state *session.State, limits promptLimits) (string, string) {
+	if limits.notes < 0 {
+		return formatNoteList(state.Notes, 0), ""
+	}
+	var kept []*session.Note
+	others := 0
+	for i := len(state.Notes) - 1; i >= 0; i-- {
+		note := state.Notes[i]
+		if note.Type != "stuck" {
+			if others >= limits.notes {
+				continue
+			}
+			others++
+		}
+		kept = append(kept, note)
+	}
+	// Back to chronological order
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	omitted := len(state.Notes) - len(kept)
+	if omitted == 0 {
+		return formatNoteList(kept, 0), ""
+	}
+	return formatNoteList(kept, omitted), fmt.Sprintf("notes: %d of %d older notes omitted", omitted, len(state.Notes))
+}
+
+// formatTasksLimited formats tasks like formatTasks, folding or compacting
+// the less relevant ones according to limits.
+func formatTasksLimited(state *session.State, limits promptLimits) (string, string) {
+	result, folded := formatTaskList(state, limits)
+	if folded == 0 {
+		return result, ""
+	}
+	what := "completed/cancelled"
+	if limits.openOnly {
+		what = "completed/cancelled/blocked/waiting"
+	}
+	return result, fmt.Sprintf("tasks: %d %s tasks only counted", folded, what)
+}
+
+// isTaskReady reports whether task is remaining with all dependencies completed.
+func isTaskReady(state *session.State, task *session.Task) bool {
+	if task.Status != "remaining" {
+		return false
+	}
+	for _, depID := range task.DependsOn {
+		dep, exists := state.Tasks[depID]
+		if !exists || dep.Status != "completed" {
+			return false
+		}
+	}
+	return true
+}
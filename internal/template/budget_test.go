@@ -0,0 +1,133 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// largeTemplateData returns data for a long-running session with plenty of
+// finished work, notes, and history.
+func largeTemplateData() Data {
+	state := &session.State{Tasks: map[string]*session.Task{}}
+	for i := 1; i <= 40; i++ {
+		id := fmt.Sprintf("TAS-%d", i)
+		state.Tasks[id] = &session.Task{ID: id, Content: fmt.Sprintf("Finished task number %d with a long description", i), Status: "completed", Priority: 2}
+	}
+	state.Tasks["TAS-41"] = &session.Task{ID: "TAS-41", Content: "Current work", Status: "in_progress", Description: "Keep this description"}
+	state.Tasks["TAS-42"] = &session.Task{ID: "TAS-42", Content: "Ready work", Status: "remaining", AcceptanceCriteria: []string{"Ready criterion"}}
+	state.Tasks["TAS-43"] = &session.Task{ID: "TAS-43", Content: "Waiting work", Status: "remaining", DependsOn: []string{"TAS-41"}, Description: "Waiting description"}
+	state.Tasks["TAS-44"] = &session.Task{ID: "TAS-44", Content: "Blocked work", Status: "blocked"}
+	for i := 1; i <= 30; i++ {
+		state.Notes = append(state.Notes, &session.Note{Content: fmt.Sprintf("Learning number %d about the codebase", i), Type: "learning", Iteration: i})
+	}
+	state.Notes = append(state.Notes[:3], append([]*session.Note{{Content: "Stuck on flaky CI", Type: "stuck", Iteration: 3}}, state.Notes[3:]...)...)
+	for i := 1; i <= 10; i++ {
+		state.Iterations = append(state.Iterations, &session.Iteration{Number: i, Summary: fmt.Sprintf("Summary of iteration %d", i), EndedAt: time.Now()})
+	}
+	return Data{
+		Session:   "long",
+		Iteration: 11,
+		Spec:      strings.Repeat("Spec line with requirements.\n", 200),
+		State:     state,
+		Config:    ConfigData{SpecPath: "specs/long.md"},
+	}
+}
+
+func TestRenderBudget(t *testing.T) {
+	data := largeTemplateData()
+	full, report, err := renderBudget(ModeAuto, DefaultTemplate, data, 0)
+	if err != nil {
+		t.Fatalf("renderBudget() error = %v", err)
+	}
+	if len(report.Trimmed) != 0 {
+		t.Errorf("unlimited budget trimmed %v", report.Trimmed)
+	}
+	if full != Render(DefaultTemplate, legacyVariables(data)) {
+		t.Error("unlimited budget should render the prompt unchanged")
+	}
+	fullTokens := report.Tokens
+
+	t.Run("first level", func(t *testing.T) {
+		got, report, err := renderBudget(ModeAuto, DefaultTemplate, data, fullTokens-1)
+		if err != nil {
+			t.Fatalf("renderBudget() error = %v", err)
+		}
+		if report.Tokens > fullTokens-1 {
+			t.Errorf("Tokens = %d, want <= %d", report.Tokens, fullTokens-1)
+		}
+		for _, want := range []string{
+			"Completed: 40 tasks (use task-list to see them)",
+			"Stuck on flaky CI",
+			"Learning number 30",
+			"(20 older notes omitted; use note-list to see them)",
+			"#10 (just now)",
+			"Keep this description",
+			"Waiting description",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("prompt missing %q", want)
+			}
+		}
+		for _, unwanted := range []string{"Finished task number", "Learning number 20 ", "#7 (just now)"} {
+			if strings.Contains(got, unwanted) {
+				t.Errorf("prompt should not contain %q", unwanted)
+			}
+		}
+		if len(report.Trimmed) != 3 {
+			t.Errorf("Trimmed = %v, want tasks, notes, and history", report.Trimmed)
+		}
+	})
+
+	t.Run("last level keeps in-progress, ready, and stuck", func(t *testing.T) {
+		specTokens := EstimateTokens(data.Spec)
+		got, report, err := renderBudget(ModeAuto, DefaultTemplate, data, specTokens+540)
+		if err != nil {
+			t.Fatalf("renderBudget() error = %v", err)
+		}
+		if report.Tokens > specTokens+540 {
+			t.Errorf("Tokens = %d, want <= %d", report.Tokens, specTokens+540)
+		}
+		for _, want := range []string{"Current work", "Keep this description", "Ready work", "Ready criterion", "Stuck on flaky CI",
+			"Blocked: 1 tasks", "(1 more waiting on dependencies; use task-list to see them)"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("prompt missing %q", want)
+			}
+		}
+		for _, unwanted := range []string{"Waiting work", "Learning number", "## Recent Progress", "omitted; read the rest"} {
+			if strings.Contains(got, unwanted) {
+				t.Errorf("prompt should not contain %q", unwanted)
+			}
+		}
+	})
+
+	t.Run("spec is cut last", func(t *testing.T) {
+		got, report, err := renderBudget(ModeAuto, DefaultTemplate, data, 1500)
+		if err != nil {
+			t.Fatalf("renderBudget() error = %v", err)
+		}
+		if report.Tokens > 1500 {
+			t.Errorf("Tokens = %d, want <= 1500", report.Tokens)
+		}
+		if !strings.Contains(got, "of the spec omitted; read the rest in specs/long.md]") {
+			t.Error("prompt should point at the full spec")
+		}
+		if !strings.Contains(got, "Spec line with requirements.\n") {
+			t.Error("prompt should keep the start of the spec")
+		}
+		if len(report.Trimmed) == 0 || !strings.HasPrefix(report.Trimmed[0], "spec: cut to ") {
+			t.Errorf("Trimmed = %v, want the spec cut listed first", report.Trimmed)
+		}
+	})
+}
+
+func TestEstimateTokens(t *testing.T) {
+	for input, want := range map[string]int{"": 0, "abc": 1, "abcd": 1, "abcde": 2} {
+		if got := EstimateTokens(input); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
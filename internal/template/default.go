@@ -17,6 +17,8 @@ Session: {{session}} | Iteration: #{{iteration}}
 ## Rules
 - ONE task per iteration - complete fully, then STOP
 - Test changes before marking complete
+- A task with acceptance criteria is complete only when every criterion is verified
+- Split a task too big for one iteration into subtasks (task-add with parent_id); the parent completes itself once all subtasks are done
 - Write iteration-summary before stopping
 - Call session-complete only when ALL tasks done
 - Respect user-added tasks even if not in spec
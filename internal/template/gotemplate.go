@@ -0,0 +1,176 @@
+package template
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	gotemplate "text/template"
+
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// Template rendering modes (BuildConfig.Mode).
+const (
+	ModeAuto   = "auto"   // Go text/template, falling back to legacy placeholders if the template does not parse or execute
+	ModeLegacy = "legacy" // Plain {{variable}} placeholder replacement only
+	ModeGo     = "go"     // Go text/template; parse and execution errors fail the build
+)
+
+// Data is the structured data available to Go text/template prompts as dot,
+// e.g. {{.Iteration}}, {{range .State.Notes}} or {{.Vars.team}}.
+type Data struct {
+	Session   string            // Session name
+	Iteration int               // Current iteration number
+	Spec      string            // Spec file content
+	Extra     string            // Extra instructions
+	Port      int               // NATS server port
+	Binary    string            // Full path to iteratr binary
+	State     *session.State    // Full session state: tasks, notes, iterations, usage
+	Git       *git.Info         // Repository status (nil outside a git repository)
+	Config    ConfigData        // Effective build configuration
+	Vars      map[string]string // User-defined variables (template_vars in iteratr.yml)
+
+	limits promptLimits // Trimming applied to the placeholder sections
+}
+
+// ConfigData is the part of the build configuration exposed to templates.
+type ConfigData struct {
+	Model      string // Model in provider/model format
+	Backend    string // Agent backend
+	Iterations int    // Max iterations (0 = infinite)
+	AutoCommit bool   // Auto-commit after each iteration
+	CommitMode string // agent, native, or native-llm
+	Review     bool   // Iterations are reviewed before commit
+	SpecSync   bool   // Spec checklist is kept in sync with tasks
+	Worktree   bool   // Session runs in its own git worktree
+	SpecPath   string // Path to the spec file
+	WorkDir    string // Agent working directory
+}
+
+// IsValidMode reports whether mode is a known rendering mode. Empty means ModeAuto.
+func IsValidMode(mode string) bool {
+	switch mode {
+	case "", ModeAuto, ModeLegacy, ModeGo:
+		return true
+	default:
+		return false
+	}
+}
+
+// RenderGo renders tmpl as a Go text/template with data as dot. The legacy
+// placeholders keep working as functions ({{tasks}}, {{notes}}, ...), next
+// to helpers for working with the state:
+//   - tasksByStatus "blocked" - tasks with a status, by priority then ID
+//   - readyCount, blockedCount - number of ready / blocked tasks
+//   - join LIST SEP - strings.Join
+func RenderGo(tmpl string, data Data) (string, error) {
+	t, err := parseGo(tmpl, data)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// renderMode renders tmpl according to mode (see ModeAuto, ModeLegacy, ModeGo).
+func renderMode(mode, tmpl string, data Data) (string, error) {
+	mode, err := resolveMode(mode, tmpl, data)
+	if err != nil {
+		return "", err
+	}
+	if mode == ModeLegacy {
+		return Render(tmpl, legacyVariables(data)), nil
+	}
+	return RenderGo(tmpl, data)
+}
+
+// resolveMode returns ModeLegacy or ModeGo for rendering tmpl in mode. In
+// ModeAuto a template that does not parse or execute as a Go template is
+// legacy, so placeholder templates containing other {{...}} text (e.g. Helm
+// snippets like {{.Values.image}}) keep rendering as before.
+func resolveMode(mode, tmpl string, data Data) (string, error) {
+	switch mode {
+	case ModeLegacy, ModeGo:
+		return mode, nil
+	case "", ModeAuto:
+		t, err := parseGo(tmpl, data)
+		if err == nil {
+			err = t.Execute(io.Discard, data)
+		}
+		if err != nil {
+			logger.Warn("Template is not a valid Go template, using legacy placeholders: %v", err)
+			return ModeLegacy, nil
+		}
+		return ModeGo, nil
+	default:
+		return "", fmt.Errorf("invalid template mode: %s (must be auto, legacy, or go)", mode)
+	}
+}
+
+// parseGo parses tmpl with the prompt functions bound to data.
+func parseGo(tmpl string, data Data) (*gotemplate.Template, error) {
+	t, err := gotemplate.New("prompt").
+		Option("missingkey=zero").
+		Funcs(templateFuncs(data)).
+		Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return t, nil
+}
+
+// legacyVariables formats data into the legacy placeholder values.
+func legacyVariables(data Data) Variables {
+	vars, _ := formatSections(data)
+	return vars
+}
+
+// templateFuncs returns the functions available to Go templates.
+func templateFuncs(data Data) gotemplate.FuncMap {
+	vars := legacyVariables(data)
+	state := data.State
+	if state == nil {
+		state = &session.State{Tasks: map[string]*session.Task{}}
+	}
+	return gotemplate.FuncMap{
+		// Legacy placeholders
+		"session":   func() string { return vars.Session },
+		"iteration": func() string { return vars.Iteration },
+		"spec":      func() string { return vars.Spec },
+		"notes":     func() string { return vars.Notes },
+		"tasks":     func() string { return vars.Tasks },
+		"history":   func() string { return vars.History },
+		"extra":     func() string { return vars.Extra },
+		"port":      func() string { return vars.Port },
+		"binary":    func() string { return vars.Binary },
+
+		// Helpers
+		"tasksByStatus": func(status string) []*session.Task { return tasksByStatus(state, status) },
+		"readyCount":    func() int { return countReadyTasks(state) },
+		"blockedCount":  func() int { return countBlockedTasks(state) },
+		"join":          strings.Join,
+	}
+}
+
+// tasksByStatus returns the tasks with the given status, ordered by priority then ID.
+func tasksByStatus(state *session.State, status string) []*session.Task {
+	var tasks []*session.Task
+	for _, task := range state.Tasks {
+		if task.Status == status {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Priority != tasks[j].Priority {
+			return tasks[i].Priority < tasks[j].Priority
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks
+}
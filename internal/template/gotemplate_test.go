@@ -0,0 +1,172 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func testTemplateData() Data {
+	return Data{
+		Session:   "demo",
+		Iteration: 3,
+		Spec:      "Build it",
+		Port:      4222,
+		Binary:    "/usr/bin/iteratr",
+		State: &session.State{
+			Tasks: map[string]*session.Task{
+				"TAS-1": {ID: "TAS-1", Content: "Write parser", Status: "remaining", Priority: 2, Labels: []string{"parser", "core"}},
+				"TAS-2": {ID: "TAS-2", Content: "Fix CI", Status: "blocked", Priority: 1},
+				"TAS-3": {ID: "TAS-3", Content: "Add tests", Status: "remaining", Priority: 0},
+			},
+		},
+		Git:    &git.Info{Branch: "main", Dirty: true},
+		Config: ConfigData{Model: "anthropic/claude-sonnet-4.5", AutoCommit: true},
+		Vars:   map[string]string{"team": "platform"},
+	}
+}
+
+func TestRenderGo(t *testing.T) {
+	data := testTemplateData()
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "fields",
+			template: "{{.Session}} #{{.Iteration}} on {{.Git.Branch}} with {{.Config.Model}}",
+			expected: "demo #3 on main with anthropic/claude-sonnet-4.5",
+		},
+		{
+			name:     "conditionals",
+			template: "{{if .Config.AutoCommit}}commit{{else}}no commit{{end}}{{if .Git.Dirty}}, dirty{{end}}",
+			expected: "commit, dirty",
+		},
+		{
+			name:     "range over tasks by status",
+			template: "{{range tasksByStatus \"remaining\"}}[{{.ID}} {{.Content}}]{{end}}",
+			expected: "[TAS-3 Add tests][TAS-1 Write parser]",
+		},
+		{
+			name:     "counts",
+			template: "ready={{readyCount}} blocked={{blockedCount}} total={{len .State.Tasks}}",
+			expected: "ready=2 blocked=1 total=3",
+		},
+		{
+			name:     "custom variables",
+			template: "team={{.Vars.team}} missing={{.Vars.missing}}",
+			expected: "team=platform missing=",
+		},
+		{
+			name:     "legacy placeholders as functions",
+			template: "{{session}} {{iteration}} {{port}} {{binary}} {{spec}}",
+			expected: "demo 3 4222 /usr/bin/iteratr Build it",
+		},
+		{
+			name:     "join",
+			template: `{{with index .State.Tasks "TAS-1"}}{{join .Labels ", "}}{{end}}`,
+			expected: "parser, core",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderGo(tt.template, data)
+			if err != nil {
+				t.Fatalf("RenderGo() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("RenderGo() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderGo_LegacyTemplatesUnchanged(t *testing.T) {
+	data := testTemplateData()
+	data.State.Notes = []*session.Note{{Content: "Use the fast path", Type: "tip", Iteration: 2}}
+
+	got, err := RenderGo(DefaultTemplate, data)
+	if err != nil {
+		t.Fatalf("RenderGo(DefaultTemplate) error = %v", err)
+	}
+	want := Render(DefaultTemplate, legacyVariables(data))
+	if got != want {
+		t.Errorf("Go rendering of the default template differs from legacy rendering\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderMode(t *testing.T) {
+	data := testTemplateData()
+
+	t.Run("auto falls back to legacy placeholders", func(t *testing.T) {
+		got, err := renderMode(ModeAuto, "{{session}} {{unknown}}", data)
+		if err != nil {
+			t.Fatalf("renderMode() error = %v", err)
+		}
+		if got != "demo {{unknown}}" {
+			t.Errorf("renderMode() = %q, want %q", got, "demo {{unknown}}")
+		}
+	})
+
+	t.Run("auto falls back when the Go template fails to execute", func(t *testing.T) {
+		got, err := renderMode(ModeAuto, "{{.Values.image}} {{session}}", data)
+		if err != nil {
+			t.Fatalf("renderMode() error = %v", err)
+		}
+		if got != "{{.Values.image}} demo" {
+			t.Errorf("renderMode() = %q, want %q", got, "{{.Values.image}} demo")
+		}
+	})
+
+	t.Run("auto renders Go templates", func(t *testing.T) {
+		got, err := renderMode("", "{{if .Vars.team}}{{.Vars.team}}{{end}}", data)
+		if err != nil {
+			t.Fatalf("renderMode() error = %v", err)
+		}
+		if got != "platform" {
+			t.Errorf("renderMode() = %q, want platform", got)
+		}
+	})
+
+	t.Run("legacy ignores Go syntax", func(t *testing.T) {
+		got, err := renderMode(ModeLegacy, "{{session}} {{.Session}}", data)
+		if err != nil {
+			t.Fatalf("renderMode() error = %v", err)
+		}
+		if got != "demo {{.Session}}" {
+			t.Errorf("renderMode() = %q, want %q", got, "demo {{.Session}}")
+		}
+	})
+
+	t.Run("go reports errors", func(t *testing.T) {
+		if _, err := renderMode(ModeGo, "{{unknown}}", data); err == nil {
+			t.Error("expected parse error for unknown function")
+		}
+		if _, err := renderMode(ModeGo, "{{.Git.Branch.Missing}}", data); err == nil {
+			t.Error("expected execution error for invalid field")
+		}
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		if _, err := renderMode("jinja", "{{session}}", data); err == nil {
+			t.Error("expected error for invalid mode")
+		}
+		if IsValidMode("jinja") || !IsValidMode("") || !IsValidMode(ModeGo) {
+			t.Error("IsValidMode() returned unexpected result")
+		}
+	})
+
+	t.Run("nil state and git", func(t *testing.T) {
+		got, err := renderMode(ModeGo, "{{if .Git}}git{{else}}no git{{end}} {{readyCount}}", Data{})
+		if err != nil {
+			t.Fatalf("renderMode() error = %v", err)
+		}
+		if got != "no git 0" {
+			t.Errorf("renderMode() = %q, want %q", got, "no git 0")
+		}
+	})
+}
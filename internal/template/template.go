@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/mark3labs/iteratr/internal/git"
 	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/session"
 )
@@ -87,18 +87,36 @@ type BuildConfig struct {
 	TemplatePath      string         // Path to custom template (optional)
 	ExtraInstructions string         // Extra instructions (optional)
 	NATSPort          int            // NATS server port
+
+	Mode   string            // Rendering mode: ModeAuto (default), ModeLegacy, or ModeGo
+	Vars   map[string]string // User-defined variables for Go templates (optional)
+	Config ConfigData        // Build configuration exposed to Go templates
+
+	TokenBudget int // Estimated prompt size limit in tokens, 0 = unlimited (see BuildPromptWithReport)
 }
 
 // BuildPrompt loads session state, formats it, and injects it into the template.
 // This is the main function for creating prompts with current state injection.
+// The template is rendered according to cfg.Mode, see renderMode.
 func BuildPrompt(ctx context.Context, cfg BuildConfig) (string, error) {
+	prompt, _, err := BuildPromptWithReport(ctx, cfg)
+	return prompt, err
+}
+
+// BuildPromptWithReport builds the prompt like BuildPrompt and keeps it
+// within cfg.TokenBudget. When the prompt is over budget the state sections
+// are trimmed to the most relevant content: older iteration summaries and
+// notes are dropped (stuck notes are kept), finished tasks are only counted,
+// then blocked and waiting tasks, and as a last resort the spec is cut. The
+// report lists what was trimmed.
+func BuildPromptWithReport(ctx context.Context, cfg BuildConfig) (string, PromptReport, error) {
 	logger.Debug("Building prompt for session: %s, iteration: %d", cfg.SessionName, cfg.IterationNumber)
 
 	// Load session state
 	state, err := cfg.Store.LoadState(ctx, cfg.SessionName)
 	if err != nil {
 		logger.Error("Failed to load session state: %v", err)
-		return "", fmt.Errorf("failed to load session state: %w", err)
+		return "", PromptReport{}, fmt.Errorf("failed to load session state: %w", err)
 	}
 
 	// Load spec file content
@@ -108,7 +126,7 @@ func BuildPrompt(ctx context.Context, cfg BuildConfig) (string, error) {
 		data, err := os.ReadFile(cfg.SpecPath)
 		if err != nil {
 			logger.Error("Failed to read spec file: %v", err)
-			return "", fmt.Errorf("failed to read spec file: %w", err)
+			return "", PromptReport{}, fmt.Errorf("failed to read spec file: %w", err)
 		}
 		specContent = string(data)
 		logger.Debug("Spec file loaded: %d bytes", len(specContent))
@@ -131,29 +149,45 @@ func BuildPrompt(ctx context.Context, cfg BuildConfig) (string, error) {
 	templateContent, err := GetTemplate(cfg.TemplatePath)
 	if err != nil {
 		logger.Error("Failed to get template: %v", err)
-		return "", fmt.Errorf("failed to get template: %w", err)
+		return "", PromptReport{}, fmt.Errorf("failed to get template: %w", err)
 	}
 
-	// Format state data
-	vars := Variables{
+	data := Data{
 		Session:   cfg.SessionName,
-		Iteration: strconv.Itoa(cfg.IterationNumber),
+		Iteration: cfg.IterationNumber,
 		Spec:      specContent,
-		Notes:     formatNotes(state),
-		Tasks:     formatTasks(state),
-		History:   formatIterationHistory(state),
 		Extra:     cfg.ExtraInstructions,
-		Port:      strconv.Itoa(cfg.NATSPort),
+		Port:      cfg.NATSPort,
 		Binary:    binaryPath,
+		State:     state,
+		Config:    cfg.Config,
+		Vars:      cfg.Vars,
+	}
+
+	// Git status costs a few git commands, so only look it up when used
+	if cfg.Mode != ModeLegacy && strings.Contains(templateContent, ".Git") {
+		info, err := git.GetInfo(cfg.Config.WorkDir)
+		if err != nil {
+			logger.Warn("Failed to get git info for template: %v", err)
+		}
+		data.Git = info
 	}
 
 	logger.Debug("Formatted state: %d tasks, %d notes",
 		len(state.Tasks), len(state.Notes))
+	logSections(data)
 
-	// Render template with variables
-	result := Render(templateContent, vars)
-	logger.Debug("Prompt rendered: %d characters", len(result))
-	return result, nil
+	// Render template with the data, trimming it to the budget
+	result, report, err := renderBudget(cfg.Mode, templateContent, data, cfg.TokenBudget)
+	if err != nil {
+		logger.Error("Failed to render template: %v", err)
+		return "", PromptReport{}, err
+	}
+	for _, trimmed := range report.Trimmed {
+		logger.Debug("Prompt trimmed to fit budget of %d tokens: %s", report.Budget, trimmed)
+	}
+	logger.Debug("Prompt rendered: %d characters (~%d tokens)", len(result), report.Tokens)
+	return result, report, nil
 }
 
 // BuildIteration0Prompt builds the prompt for Iteration #0 (planning phase).
@@ -198,13 +232,19 @@ func BuildIteration0Prompt(ctx context.Context, cfg BuildConfig) (string, error)
 // formatNotes formats notes grouped by type for template injection.
 // Returns empty string if no notes (section header will be omitted).
 func formatNotes(state *session.State) string {
-	if len(state.Notes) == 0 {
+	return formatNoteList(state.Notes, 0)
+}
+
+// formatNoteList formats notes grouped by type, followed by a line counting
+// omitted notes that were left out.
+func formatNoteList(notes []*session.Note, omitted int) string {
+	if len(notes) == 0 && omitted == 0 {
 		return ""
 	}
 
 	// Group notes by type
 	byType := make(map[string][]*session.Note)
-	for _, note := range state.Notes {
+	for _, note := range notes {
 		byType[note.Type] = append(byType[note.Type], note)
 	}
 
@@ -224,14 +264,25 @@ func formatNotes(state *session.State) string {
 			fmt.Fprintf(&sb, "  - [#%d] %s\n", note.Iteration, note.Content)
 		}
 	}
+	if omitted > 0 {
+		fmt.Fprintf(&sb, "(%d older notes omitted; use note-list to see them)\n", omitted)
+	}
 	return sb.String()
 }
 
-// formatTasks formats tasks grouped by status for template injection.
+// formatTasks formats tasks grouped by status for template injection, with
+// subtasks indented under their parent.
 // Always includes section header since workflow requires checking tasks.
 func formatTasks(state *session.State) string {
+	result, _ := formatTaskList(state, trimLevels[0])
+	return result
+}
+
+// formatTaskList formats tasks like formatTasks within limits and returns the
+// number of tasks that were only counted instead of listed.
+func formatTaskList(state *session.State, limits promptLimits) (string, int) {
 	if len(state.Tasks) == 0 {
-		return "## Current Tasks\nNo tasks loaded."
+		return "## Current Tasks\nNo tasks loaded.", 0
 	}
 
 	// Group tasks by status
@@ -241,6 +292,7 @@ func formatTasks(state *session.State) string {
 	}
 
 	var sb strings.Builder
+	folded := 0
 	sb.WriteString("## Current Tasks\n")
 	statuses := []string{"remaining", "in_progress", "completed", "blocked", "cancelled"}
 	for _, status := range statuses {
@@ -251,8 +303,43 @@ func formatTasks(state *session.State) string {
 
 		// Uppercase first letter for display
 		displayStatus := strings.ToUpper(status[:1]) + strings.ReplaceAll(status[1:], "_", " ")
+
+		// Finished, blocked, and waiting tasks are only counted when trimming
+		fold := (limits.foldDone && (status == "completed" || status == "cancelled")) ||
+			(limits.openOnly && status == "blocked")
+		if fold {
+			fmt.Fprintf(&sb, "%s: %d tasks (use task-list to see them)\n", displayStatus, len(tasks))
+			folded += len(tasks)
+			continue
+		}
+		waiting := 0
+		if limits.openOnly && status == "remaining" {
+			ready := tasks[:0:0]
+			for _, task := range tasks {
+				if isTaskReady(state, task) {
+					ready = append(ready, task)
+				}
+			}
+			waiting = len(tasks) - len(ready)
+			tasks = ready
+		}
+
 		fmt.Fprintf(&sb, "%s:\n", displayStatus)
+		// Subtasks are nested under their parent when it has the same status
+		inGroup := make(map[string]bool, len(tasks))
 		for _, task := range tasks {
+			inGroup[task.ID] = true
+		}
+		tree := session.TaskTree(tasks, func(a, b *session.Task) bool {
+			if a.Priority != b.Priority {
+				return a.Priority < b.Priority
+			}
+			return a.ID < b.ID
+		})
+		for _, item := range tree {
+			task := item.Task
+			indent := strings.Repeat("  ", item.Depth+1)
+
 			// Format priority prefix [P0]-[P4]
 			priorityPrefix := fmt.Sprintf("[P%d] ", task.Priority)
 
@@ -270,43 +357,67 @@ func formatTasks(state *session.State) string {
 				depInfo = fmt.Sprintf(" (depends on: %s)", strings.Join(depIDs, ", "))
 			}
 
-			fmt.Fprintf(&sb, "  - %s[%s] %s%s%s\n", priorityPrefix, task.ID, task.Content, iterInfo, depInfo)
+			// Point at a parent listed under another status
+			if task.ParentID != "" && !inGroup[task.ParentID] {
+				depInfo += fmt.Sprintf(" (subtask of %s)", task.ParentID)
+			}
+
+			// Format labels and estimate
+			detailInfo := ""
+			if len(task.Labels) > 0 {
+				detailInfo += fmt.Sprintf(" {labels: %s}", strings.Join(task.Labels, ", "))
+			}
+			if task.Estimate != "" {
+				detailInfo += fmt.Sprintf(" {estimate: %s}", task.Estimate)
+			}
+
+			fmt.Fprintf(&sb, "%s- %s[%s] %s%s%s%s\n", indent, priorityPrefix, task.ID, task.Content, iterInfo, depInfo, detailInfo)
+
+			// Description and acceptance criteria only matter for open tasks
+			if status == "completed" || status == "cancelled" {
+				continue
+			}
+			if limits.compactTasks && status != "in_progress" && !isTaskReady(state, task) {
+				continue
+			}
+			if task.Description != "" {
+				for _, line := range strings.Split(strings.TrimSpace(task.Description), "\n") {
+					fmt.Fprintf(&sb, "%s  %s\n", indent, strings.TrimRight(line, " \t\r"))
+				}
+			}
+			if len(task.AcceptanceCriteria) > 0 {
+				fmt.Fprintf(&sb, "%s  Acceptance criteria:\n", indent)
+				for _, criterion := range task.AcceptanceCriteria {
+					fmt.Fprintf(&sb, "%s  - [ ] %s\n", indent, criterion)
+				}
+			}
+		}
+		if waiting > 0 {
+			fmt.Fprintf(&sb, "  (%d more waiting on dependencies; use task-list to see them)\n", waiting)
+			folded += waiting
 		}
 	}
 
-	return sb.String()
+	return sb.String(), folded
 }
 
 // formatIterationHistory formats recent iteration summaries for template injection.
 // Shows the last 5 completed iterations with their summaries and tasks worked.
 // Returns empty string if no history (section header will be omitted).
 func formatIterationHistory(state *session.State) string {
-	if len(state.Iterations) == 0 {
-		return ""
-	}
-
-	// Filter to iterations with summaries
-	withSummaries := []*session.Iteration{}
-	for _, iter := range state.Iterations {
-		if iter.Summary != "" {
-			withSummaries = append(withSummaries, iter)
-		}
-	}
+	result, _ := formatHistoryLimited(state, trimLevels[0])
+	return result
+}
 
-	if len(withSummaries) == 0 {
+// formatIterationSummaries formats iterations as the "Recent Progress" section.
+func formatIterationSummaries(iterations []*session.Iteration) string {
+	if len(iterations) == 0 {
 		return ""
 	}
 
-	// Take the last 5 iterations (most recent)
-	start := 0
-	if len(withSummaries) > 5 {
-		start = len(withSummaries) - 5
-	}
-	recent := withSummaries[start:]
-
 	var sb strings.Builder
 	sb.WriteString("## Recent Progress\n")
-	for _, iter := range recent {
+	for _, iter := range iterations {
 		// Calculate time ago
 		elapsed := time.Since(iter.EndedAt)
 		timeAgo := formatTimeAgo(elapsed)
@@ -348,26 +459,7 @@ func formatTimeAgo(d time.Duration) string {
 func countReadyTasks(state *session.State) int {
 	count := 0
 	for _, task := range state.Tasks {
-		if task.Status != "remaining" {
-			continue
-		}
-
-		// Check if all dependencies are resolved (completed)
-		allDepsCompleted := true
-		for _, depID := range task.DependsOn {
-			if depTask, exists := state.Tasks[depID]; exists {
-				if depTask.Status != "completed" {
-					allDepsCompleted = false
-					break
-				}
-			} else {
-				// Dependency doesn't exist - treat as unresolved
-				allDepsCompleted = false
-				break
-			}
-		}
-
-		if allDepsCompleted {
+		if isTaskReady(state, task) {
 			count++
 		}
 	}
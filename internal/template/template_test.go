@@ -356,6 +356,48 @@ func TestFormatTasks(t *testing.T) {
 				"[P2] [task003def] Multi-dependent (depends on: task001abc, task002xyz)",
 			},
 		},
+		{
+			name: "tasks with details",
+			state: &session.State{
+				Tasks: map[string]*session.Task{
+					"task001": {
+						ID: "task001abc", Content: "Login endpoint", Status: "remaining", Priority: 1,
+						Description:        "POST /login\nreturns a session cookie",
+						AcceptanceCriteria: []string{"401 on bad password", "cookie is HttpOnly"},
+						Labels:             []string{"backend", "auth"},
+						Estimate:           "2h",
+					},
+				},
+			},
+			want: []string{
+				"  - [P1] [task001abc] Login endpoint {labels: backend, auth} {estimate: 2h}\n" +
+					"    POST /login\n" +
+					"    returns a session cookie\n" +
+					"    Acceptance criteria:\n" +
+					"    - [ ] 401 on bad password\n" +
+					"    - [ ] cookie is HttpOnly\n",
+			},
+		},
+		{
+			name: "subtasks nested under parent",
+			state: &session.State{
+				Tasks: map[string]*session.Task{
+					"TAS-1": {ID: "TAS-1", Content: "Auth", Status: "remaining", Priority: 2},
+					"TAS-2": {ID: "TAS-2", Content: "Login", Status: "remaining", Priority: 3, ParentID: "TAS-1"},
+					"TAS-3": {ID: "TAS-3", Content: "Hashing", Status: "remaining", Priority: 1, ParentID: "TAS-2"},
+					"TAS-4": {ID: "TAS-4", Content: "User model", Status: "completed", Priority: 2, ParentID: "TAS-1"},
+					"TAS-5": {ID: "TAS-5", Content: "Docs", Status: "remaining", Priority: 0},
+				},
+			},
+			want: []string{
+				"Remaining:\n" +
+					"  - [P0] [TAS-5] Docs\n" +
+					"  - [P2] [TAS-1] Auth\n" +
+					"    - [P3] [TAS-2] Login\n" +
+					"      - [P1] [TAS-3] Hashing\n",
+				"  - [P2] [TAS-4] User model (subtask of TAS-1)\n",
+			},
+		},
 	}
 
 	for _, tt := range tests {
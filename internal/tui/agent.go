@@ -917,37 +917,44 @@ func (a *AgentOutput) AppendFinish(msg AgentFinishMsg) tea.Cmd {
 		}
 	}
 
-	// 2. Append InfoMessageItem with model/provider/duration/usage (before queued messages)
-	infoMsg := &InfoMessageItem{
-		id:       fmt.Sprintf("info-%d", len(a.messages)),
+	// 2. Append InfoMessageItem and styled finish reason (before queued messages)
+	for _, item := range finishItems(msg, len(a.messages)) {
+		a.appendBeforeQueued(item)
+	}
+
+	a.refreshContent()
+	return nil
+}
+
+// finishItems returns the items shown when an agent turn finishes: an
+// InfoMessageItem with model/provider/duration/usage, followed by a styled
+// finish reason for errors and cancellations. IDs are numbered from n, the
+// number of messages before the first item.
+func finishItems(msg AgentFinishMsg, n int) []MessageItem {
+	items := []MessageItem{&InfoMessageItem{
+		id:       fmt.Sprintf("info-%d", n),
 		model:    msg.Model,
 		provider: msg.Provider,
 		duration: msg.Duration,
 		usage:    msg.Usage,
-	}
-	a.appendBeforeQueued(infoMsg)
+	}}
 
-	// 3. If error or canceled, append styled finish reason (before queued messages)
 	if msg.Error != "" {
 		// Error finish
 		errorText := theme.Current().S().FinishError.Render(fmt.Sprintf("Error: %s", msg.Error))
-		errorItem := &TextMessageItem{
-			id:      fmt.Sprintf("finish-error-%d", len(a.messages)),
+		items = append(items, &TextMessageItem{
+			id:      fmt.Sprintf("finish-error-%d", n+1),
 			content: errorText,
-		}
-		a.appendBeforeQueued(errorItem)
+		})
 	} else if msg.Reason == "cancelled" {
 		// Canceled finish
 		cancelText := theme.Current().S().FinishCanceled.Render("Iteration canceled")
-		cancelItem := &TextMessageItem{
-			id:      fmt.Sprintf("finish-cancel-%d", len(a.messages)),
+		items = append(items, &TextMessageItem{
+			id:      fmt.Sprintf("finish-cancel-%d", n+1),
 			content: cancelText,
-		}
-		a.appendBeforeQueued(cancelItem)
+		})
 	}
-
-	a.refreshContent()
-	return nil
+	return items
 }
 
 // MarkToolError marks a tool call as failed with an error message.
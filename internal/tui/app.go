@@ -18,13 +18,15 @@ import (
 	"github.com/nats-io/nats.go"
 )
 
-// Orchestrator defines the interface for pause/resume control.
+// Orchestrator defines the interface for pause/resume, rollback, and review control.
 // This interface allows the TUI to control orchestrator state without creating a circular dependency.
 type Orchestrator interface {
 	RequestPause()
 	CancelPause()
 	Resume()
 	IsPaused() bool
+	RollbackLastIteration() (string, error)
+	SubmitReview(action, feedback string)
 }
 
 // loadUIState loads the UI state from persistent storage.
@@ -47,6 +49,7 @@ type App struct {
 	noteInputModal *NoteInputModal
 	taskInputModal *TaskInputModal
 	subagentModal  *SubagentModal
+	reviewModal    *ReviewModal
 	toast          *Toast
 
 	// Layout management
@@ -74,6 +77,7 @@ type App struct {
 	eventChan         chan session.Event // Channel for receiving NATS events
 	sendChan          chan string        // Channel for sending user messages to orchestrator
 	orchestrator      Orchestrator       // Interface to orchestrator for pause/resume control
+	inSwitcher        bool               // Hosted by a Switcher (enables session switching keys)
 }
 
 // NewApp creates a new TUI application with the given session store and NATS connection.
@@ -109,6 +113,7 @@ func NewApp(ctx context.Context, store *session.Store, sessionName, workDir, dat
 		noteModal:         NewNoteModal(),
 		noteInputModal:    NewNoteInputModal(),
 		taskInputModal:    NewTaskInputModal(),
+		reviewModal:       NewReviewModal(),
 		toast:             NewToast(),
 		eventChan:         make(chan session.Event, 1000), // Buffered channel for events (needs capacity for large task batches)
 		layoutDirty:       true,                           // Calculate layout on first render
@@ -126,6 +131,7 @@ func (a *App) Init() tea.Cmd {
 		a.checkConnectionHealth(), // Start periodic connection health checks
 		a.status.StartDurationTick(),
 		a.fetchGitInfo(), // Fetch git repository status on startup
+		a.announceHistory(),
 	)
 }
 
@@ -507,6 +513,36 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return a, tea.Batch(a.status.Tick(), gitCmd)
 
+	case HistoryListMsg:
+		if a.dashboard.history != nil {
+			a.dashboard.history.SetIterations(msg.Iterations, msg.Err)
+		}
+		return a, nil
+
+	case OpenTranscriptMsg:
+		if a.dashboard.history == nil || a.store == nil {
+			return a, nil
+		}
+		a.dashboard.history.SetLoading(msg.Iteration)
+		return a, a.loadTranscript(msg.Iteration)
+
+	case TranscriptLoadedMsg:
+		if a.dashboard.history != nil {
+			a.dashboard.history.SetTranscript(msg.Iteration, msg.Transcript, msg.Err)
+		}
+		return a, nil
+
+	case CloseHistoryMsg:
+		a.dashboard.CloseHistory()
+		return a, nil
+
+	case OpenTranscriptSubagentMsg:
+		if a.subagentModal != nil {
+			a.subagentModal.Close()
+		}
+		a.subagentModal = NewTranscriptSubagentModal(msg.SubagentType, msg.Entries)
+		return a, nil
+
 	case OpenSubagentModalMsg:
 		// Close existing modal if any (shouldn't happen with full-screen modal)
 		if a.subagentModal != nil {
@@ -532,6 +568,46 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.subagentModal.err = msg.Err
 		}
 
+	case ReviewRequestMsg:
+		a.reviewModal.Show(msg)
+		a.syncReviewStatus()
+		return a, nil
+
+	case RequestRejectReviewMsg:
+		// Show confirmation dialog before reverting the iteration
+		feedback := msg.Feedback
+		a.dialog.Show(
+			"Reject Iteration",
+			fmt.Sprintf("Reject iteration #%d? Its file changes are reverted and its task updates undone.", msg.Iteration),
+			func() tea.Cmd {
+				return func() tea.Msg {
+					return ReviewDecisionMsg{Action: ReviewReject, Feedback: feedback}
+				}
+			},
+		)
+		return a, nil
+
+	case ReviewDecisionMsg:
+		if !a.reviewModal.IsPending() {
+			return a, nil
+		}
+		iteration := a.reviewModal.iteration
+		a.reviewModal.Close()
+		a.syncReviewStatus()
+		if a.orchestrator != nil {
+			a.orchestrator.SubmitReview(msg.Action, msg.Feedback)
+		}
+		var text string
+		switch msg.Action {
+		case ReviewApprove:
+			text = fmt.Sprintf("Approved iteration #%d", iteration)
+		case ReviewReject:
+			text = fmt.Sprintf("Rejected iteration #%d", iteration)
+		default:
+			text = fmt.Sprintf("Feedback sent for iteration #%d", iteration)
+		}
+		return a, a.toast.Show(text)
+
 	case ShowToastMsg:
 		return a, a.toast.Show(msg.Text)
 	}
@@ -613,6 +689,34 @@ func (a *App) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		case "r":
 			// ctrl+x r -> restart completed session
 			return a, a.restartSession()
+		case "u":
+			// ctrl+x u -> roll back the last iteration
+			return a, a.rollbackIteration()
+		case "v":
+			// ctrl+x v -> show the pending review again
+			a.reviewModal.Reopen()
+			a.syncReviewStatus()
+			return a, nil
+		case "h":
+			// ctrl+x h -> browse earlier iterations in the agent pane
+			return a, a.toggleHistory()
+		case "]", "[":
+			// ctrl+x ] / ctrl+x [ -> next/previous session (handled by Switcher)
+			if !a.inSwitcher {
+				return a, nil
+			}
+			delta := 1
+			if msg.String() == "[" {
+				delta = -1
+			}
+			return a, func() tea.Msg { return SwitchSessionMsg{Delta: delta} }
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			// ctrl+x <n> -> jump to session n (handled by Switcher)
+			if !a.inSwitcher {
+				return a, nil
+			}
+			index := int(msg.String()[0] - '0')
+			return a, func() tea.Msg { return SwitchSessionMsg{Index: index} }
 		case "ctrl+c", "esc":
 			// Allow escape or ctrl+c to exit prefix mode
 			return a, nil
@@ -622,7 +726,13 @@ func (a *App) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	// 3. Modal gets priority when visible
+	// 3. Modal gets priority when visible (review first: the loop waits on it)
+	if a.reviewModal != nil && a.reviewModal.IsVisible() {
+		cmd := a.reviewModal.Update(msg)
+		a.syncReviewStatus()
+		return a, cmd
+	}
+
 	if a.taskModal != nil && a.taskModal.IsVisible() {
 		// Forward all keys to TaskModal for interactive editing
 		cmd := a.taskModal.Update(msg)
@@ -682,7 +792,7 @@ func (a *App) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 }
 
 // handlePaste processes paste messages using hierarchical priority routing.
-// Mirrors the priority from handleKeyPress: noteInputModal → taskInputModal →
+// Mirrors the priority from handleKeyPress: reviewModal → noteInputModal → taskInputModal →
 // subagentModal → dashboard.
 func (a *App) handlePaste(msg tea.PasteMsg) (tea.Model, tea.Cmd) {
 	// Sanitize pasted content
@@ -693,7 +803,12 @@ func (a *App) handlePaste(msg tea.PasteMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
-	// 2. TaskModal has textarea for content editing — forward paste
+	// 2. Review modal has a feedback textarea — forward paste
+	if a.reviewModal != nil && a.reviewModal.IsVisible() {
+		return a, a.reviewModal.Update(tea.PasteMsg{Content: content})
+	}
+
+	// 2a. TaskModal has textarea for content editing — forward paste
 	if a.taskModal != nil && a.taskModal.IsVisible() {
 		return a, a.taskModal.Update(tea.PasteMsg{Content: content})
 	}
@@ -740,6 +855,11 @@ func (a *App) handleMouse(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
 		return a, a.dialog.HandleClick(mouse.X, mouse.Y)
 	}
 
+	// Review modal consumes all clicks while visible
+	if a.reviewModal.IsVisible() {
+		return a, nil
+	}
+
 	// Subagent modal takes priority when visible - handle clicks for expand/collapse
 	if a.subagentModal != nil {
 		// Handle click within modal (for expand/collapse on messages)
@@ -803,6 +923,11 @@ func (a *App) handleMouse(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
+	// Read-only history replaces the live output (and its input) while shown
+	if a.dashboard.history != nil {
+		return a, a.dashboard.history.HandleClick(mouse.X, mouse.Y)
+	}
+
 	// Check if input area was clicked (focus text input)
 	if a.agent.IsInputAreaClick(mouse.X, mouse.Y) {
 		// Set input focus via dashboard (same as pressing 'i')
@@ -828,7 +953,8 @@ func (a *App) focusPaneAtPosition(x, y int) {
 	prevPane := a.dashboard.focusPane
 
 	switch {
-	case a.agent.IsViewportArea(x, y):
+	case a.dashboard.history != nil && a.dashboard.history.IsViewportArea(x, y),
+		a.dashboard.history == nil && a.agent.IsViewportArea(x, y):
 		a.dashboard.focusPane = FocusAgent
 		a.dashboard.inputFocused = false
 		if a.agent != nil {
@@ -872,6 +998,12 @@ func (a *App) handleMouseWheel(msg tea.MouseWheelMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
+	// Review modal takes priority - scroll the diff when visible
+	if a.reviewModal.IsVisible() {
+		a.reviewModal.ScrollViewport(lines)
+		return a, nil
+	}
+
 	// Subagent modal takes priority - scroll modal content when visible
 	if a.subagentModal != nil {
 		a.subagentModal.ScrollViewport(lines)
@@ -879,7 +1011,11 @@ func (a *App) handleMouseWheel(msg tea.MouseWheelMsg) (tea.Model, tea.Cmd) {
 	}
 
 	// Scroll the viewport under the cursor
-	if a.agent.IsViewportArea(mouse.X, mouse.Y) {
+	if h := a.dashboard.history; h != nil && h.IsViewportArea(mouse.X, mouse.Y) {
+		h.ScrollViewport(lines)
+		return a, nil
+	}
+	if a.dashboard.history == nil && a.agent.IsViewportArea(mouse.X, mouse.Y) {
 		a.agent.ScrollViewport(lines)
 		return a, nil
 	}
@@ -987,6 +1123,72 @@ func (a *App) restartSession() tea.Cmd {
 	)
 }
 
+// rollbackIteration handles the ctrl+x u keyboard shortcut to roll back the
+// last iteration. The orchestrator refuses unless it is paused between
+// iterations or the session is complete; the outcome is shown as a toast.
+func (a *App) rollbackIteration() tea.Cmd {
+	// Guard: if orchestrator is nil, do nothing
+	if a.orchestrator == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		summary, err := a.orchestrator.RollbackLastIteration()
+		if err != nil {
+			logger.Warn("failed to roll back iteration: %v", err)
+			return ShowToastMsg{Text: fmt.Sprintf("Rollback failed: %v", err)}
+		}
+		return ShowToastMsg{Text: summary}
+	}
+}
+
+// toggleHistory handles the ctrl+x h keyboard shortcut. It shows the
+// iterations with a stored transcript in the agent pane, or returns the pane
+// to the live output when the history is already shown.
+func (a *App) toggleHistory() tea.Cmd {
+	if a.store == nil {
+		return nil
+	}
+	if a.dashboard.history != nil {
+		a.dashboard.CloseHistory()
+		return nil
+	}
+	a.dashboard.OpenHistory()
+	return func() tea.Msg {
+		iterations, err := a.store.TranscriptIterations(a.ctx, a.sessionName)
+		return HistoryListMsg{Iterations: iterations, Err: err}
+	}
+}
+
+// loadTranscript loads an iteration's transcript for replay.
+func (a *App) loadTranscript(iteration int) tea.Cmd {
+	return func() tea.Msg {
+		t, err := a.store.LoadTranscript(a.ctx, a.sessionName, iteration)
+		return TranscriptLoadedMsg{Iteration: iteration, Transcript: t, Err: err}
+	}
+}
+
+// announceHistory points to ctrl+x h when a resumed session has transcripts
+// of earlier iterations, since the agent pane starts out empty.
+func (a *App) announceHistory() tea.Cmd {
+	if a.store == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		iterations, err := a.store.TranscriptIterations(a.ctx, a.sessionName)
+		if err != nil || len(iterations) == 0 {
+			return nil
+		}
+		return ShowToastMsg{Text: fmt.Sprintf("%d earlier iterations recorded · %s to replay", len(iterations), KeyCtrlXH)}
+	}
+}
+
+// syncReviewStatus shows the review hint in the status bar while a review is
+// pending but its modal is hidden.
+func (a *App) syncReviewStatus() {
+	a.status.SetReviewPending(a.reviewModal.IsPending() && !a.reviewModal.IsVisible())
+}
+
 // handleSidebarToggle toggles the sidebar visibility and manages focus and persistence.
 // When hiding: moves focus from sidebar to messages panel and sets user-hidden flag.
 // When showing: restores sidebar and clears user-hidden flag.
@@ -1120,6 +1322,9 @@ func (a *App) Draw(scr uv.Screen, area uv.Rectangle) *tea.Cursor {
 	if a.taskInputModal.IsVisible() {
 		a.taskInputModal.Draw(scr, area)
 	}
+	if a.reviewModal.IsVisible() {
+		a.reviewModal.Draw(scr, area)
+	}
 	if a.dialog.IsVisible() {
 		a.dialog.Draw(scr, area)
 	}
@@ -324,6 +324,33 @@ func TestPrefixKeys_TogglePause(t *testing.T) {
 	}
 }
 
+// TestPrefixKeys_RollbackIteration tests ctrl+x u asks the orchestrator to roll back
+func TestPrefixKeys_RollbackIteration(t *testing.T) {
+	t.Parallel()
+
+	orch := &mockOrchestrator{paused: true}
+	app := NewApp(context.Background(), nil, testfixtures.FixedSessionName, "/tmp", t.TempDir(), nil, nil, orch)
+	app.width = testfixtures.TestTermWidth
+	app.height = testfixtures.TestTermHeight
+
+	// Send ctrl+x u
+	app.Update(tea.KeyPressMsg{Text: "ctrl+x"})
+	_, cmd := app.Update(tea.KeyPressMsg{Text: "u"})
+	require.NotNil(t, cmd, "Should return command from rollbackIteration")
+	require.False(t, app.awaitingPrefixKey, "Should exit prefix mode after second key")
+
+	// The rollback runs in the command and reports back as a toast
+	msg := cmd()
+	require.True(t, orch.rolledBack, "Should roll back through the orchestrator")
+	require.Equal(t, ShowToastMsg{Text: "Rolled back iteration #2"}, msg)
+
+	// Without an orchestrator the key does nothing
+	app = NewApp(context.Background(), nil, testfixtures.FixedSessionName, "/tmp", t.TempDir(), nil, nil, nil)
+	app.Update(tea.KeyPressMsg{Text: "ctrl+x"})
+	_, cmd = app.Update(tea.KeyPressMsg{Text: "u"})
+	require.Nil(t, cmd)
+}
+
 // TestPrefixKeys_ExitPrefixMode tests escaping prefix mode with esc or ctrl+c
 func TestPrefixKeys_ExitPrefixMode(t *testing.T) {
 	t.Parallel()
@@ -550,6 +577,9 @@ type mockOrchestrator struct {
 	pauseRequested bool
 	pauseCancelled bool
 	resumed        bool
+	rolledBack     bool
+	reviewAction   string
+	reviewFeedback string
 }
 
 func (m *mockOrchestrator) RequestPause() {
@@ -567,3 +597,13 @@ func (m *mockOrchestrator) Resume() {
 func (m *mockOrchestrator) IsPaused() bool {
 	return m.paused
 }
+
+func (m *mockOrchestrator) RollbackLastIteration() (string, error) {
+	m.rolledBack = true
+	return "Rolled back iteration #2", nil
+}
+
+func (m *mockOrchestrator) SubmitReview(action, feedback string) {
+	m.reviewAction = action
+	m.reviewFeedback = feedback
+}
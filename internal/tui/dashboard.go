@@ -28,6 +28,7 @@ type Dashboard struct {
 	width        int
 	height       int
 	agentOutput  *AgentOutput // Reference to agent output for rendering
+	history      *HistoryView // Read-only history shown instead of agentOutput (nil = live)
 	sidebar      *Sidebar     // Sidebar on the right (tasks + notes)
 	focusPane    FocusPane    // Which pane has keyboard focus
 	focused      bool         // Whether the dashboard has focus
@@ -65,6 +66,8 @@ func (d *Dashboard) Update(msg tea.Msg) tea.Cmd {
 	case tea.KeyPressMsg:
 		// Global 'i' key: focus input from any state
 		if msg.String() == "i" && d.focusPane != FocusInput {
+			// The input belongs to the live output
+			d.history = nil
 			d.focusPane = FocusInput
 			d.inputFocused = true
 			if d.agentOutput != nil {
@@ -131,6 +134,9 @@ func (d *Dashboard) Update(msg tea.Msg) tea.Cmd {
 		case FocusTasks, FocusNotes:
 			return d.sidebar.Update(msg)
 		case FocusAgent:
+			if d.history != nil {
+				return d.history.Update(msg)
+			}
 			if d.agentOutput != nil {
 				return d.agentOutput.Update(msg)
 			}
@@ -144,11 +150,20 @@ func (d *Dashboard) Update(msg tea.Msg) tea.Cmd {
 func (d *Dashboard) Draw(scr uv.Screen, area uv.Rectangle) *tea.Cursor {
 	// Draw title with rule line: "Agent Output ────────"
 	agentPanelFocused := d.focusPane == FocusAgent && d.focusPane != FocusInput
-	inner := DrawPanel(scr, area, "Agent Output", agentPanelFocused)
+	title := "Agent Output"
+	if d.history != nil {
+		title = d.history.Title()
+	}
+	inner := DrawPanel(scr, area, title, agentPanelFocused)
 
 	// Add 1-row padding between header rule and messages viewport
 	inner.Min.Y += 1
 
+	// Read-only history replaces the live output until closed
+	if d.history != nil {
+		return d.history.Draw(scr, inner)
+	}
+
 	// Delegate to AgentOutput.Draw for content rendering
 	if d.agentOutput != nil {
 		return d.agentOutput.Draw(scr, inner)
@@ -182,6 +197,25 @@ func (d *Dashboard) SetState(state *session.State) {
 	if state != nil {
 		d.sessionName = state.Session
 	}
+	if d.history != nil {
+		d.history.SetState(state)
+	}
+}
+
+// OpenHistory shows the read-only history in the agent pane and focuses it.
+func (d *Dashboard) OpenHistory() {
+	d.history = NewHistoryView(d.state)
+	d.focusPane = FocusAgent
+	d.inputFocused = false
+	if d.agentOutput != nil {
+		d.agentOutput.SetInputFocused(false)
+	}
+	d.updateScrollListFocus()
+}
+
+// CloseHistory returns the agent pane to the live output.
+func (d *Dashboard) CloseHistory() {
+	d.history = nil
 }
 
 // SetFocus sets the focus state of the dashboard (implements Focusable interface).
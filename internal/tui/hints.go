@@ -22,9 +22,14 @@ const (
 	KeyCtrlXT   = "ctrl+x t" // Create task
 	KeyCtrlXP   = "ctrl+x p" // Pause/resume
 	KeyCtrlXR   = "ctrl+x r" // Restart completed session
+	KeyCtrlXU   = "ctrl+x u" // Roll back last iteration
+	KeyCtrlXV   = "ctrl+x v" // Show pending review
+	KeyCtrlXH   = "ctrl+x h" // Browse earlier iterations
 	KeyPgUpDown = "pgup/pgdn"
 	KeyHomeEnd  = "home/end"
 	KeyI        = "i"
+
+	KeyCtrlXSwitch = "ctrl+x [/]" // Previous/next session (multi-session builds)
 )
 
 // RenderHint renders a single key-description pair.
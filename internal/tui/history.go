@@ -0,0 +1,585 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/theme"
+)
+
+// historyPromptLines is how many lines of a replayed prompt are shown.
+// Iteration prompts include the whole template; iteratr transcript prints
+// them in full.
+const historyPromptLines = 20
+
+// HistoryListMsg carries the iterations that have a stored transcript.
+type HistoryListMsg struct {
+	Iterations []int
+	Err        error
+}
+
+// OpenTranscriptMsg asks the App to load an iteration's transcript for replay.
+type OpenTranscriptMsg struct {
+	Iteration int
+}
+
+// TranscriptLoadedMsg carries a transcript loaded for replay.
+type TranscriptLoadedMsg struct {
+	Iteration  int
+	Transcript *session.Transcript
+	Err        error
+}
+
+// CloseHistoryMsg returns the agent pane to the live output.
+type CloseHistoryMsg struct{}
+
+// OpenTranscriptSubagentMsg opens the subagent modal on the entries a
+// subagent recorded in a replayed transcript.
+type OpenTranscriptSubagentMsg struct {
+	SubagentType string
+	Entries      []session.TranscriptEntry
+}
+
+// HistoryView is the agent pane's read-only view of earlier iterations. It
+// lists the iterations that have a stored transcript and replays the selected
+// one through the same message items as the live output. Live output keeps
+// accumulating in AgentOutput while the history is shown.
+type HistoryView struct {
+	iterations []int          // Iterations with a stored transcript, newest first
+	cursor     int            // Selected row in the list
+	offset     int            // First visible row in the list
+	state      *session.State // For iteration details in the list
+
+	transcript *session.Transcript                  // Replayed transcript (nil while listing)
+	subagents  map[string][]session.TranscriptEntry // Subagent entries by parent tool call ID
+	loading    int                                  // Iteration being loaded (-1 = none)
+	listed     bool                                 // Whether the iteration list has arrived
+	err        error
+
+	scrollList        *ScrollList
+	messages          []MessageItem
+	messageLineStarts []int
+	viewportArea      uv.Rectangle
+}
+
+// NewHistoryView creates a HistoryView that waits for its iteration list.
+func NewHistoryView(state *session.State) *HistoryView {
+	scrollList := NewScrollList(80, 20) // Placeholder dimensions, updated on Draw
+	scrollList.SetItemGap(1)
+	scrollList.SetFocused(true)
+	return &HistoryView{
+		state:      state,
+		loading:    -1,
+		scrollList: scrollList,
+	}
+}
+
+// Title returns the agent pane title while the history is shown.
+func (h *HistoryView) Title() string {
+	if h.transcript != nil {
+		return fmt.Sprintf("Iteration #%d (read-only)", h.transcript.Iteration)
+	}
+	return "History"
+}
+
+// SetState updates the session state used for iteration details.
+func (h *HistoryView) SetState(state *session.State) {
+	h.state = state
+}
+
+// SetIterations sets the iterations that can be replayed.
+func (h *HistoryView) SetIterations(iterations []int, err error) {
+	h.listed = true
+	h.err = err
+	h.iterations = make([]int, len(iterations))
+	for i, n := range iterations {
+		h.iterations[len(iterations)-1-i] = n
+	}
+	h.cursor = 0
+	h.offset = 0
+}
+
+// SetLoading marks an iteration's transcript as loading.
+func (h *HistoryView) SetLoading(iteration int) {
+	h.loading = iteration
+	h.err = nil
+}
+
+// SetTranscript replays a loaded transcript. Results for an iteration that
+// is no longer being loaded are ignored.
+func (h *HistoryView) SetTranscript(iteration int, t *session.Transcript, err error) {
+	if iteration != h.loading {
+		return
+	}
+	h.loading = -1
+	if err != nil {
+		h.err = err
+		return
+	}
+	h.transcript = t
+	h.messages, h.subagents = transcriptItems(t)
+	for i, n := range h.iterations {
+		if n == iteration {
+			h.cursor = i
+		}
+	}
+	h.scrollList.SetAutoScroll(false)
+	h.refreshContent()
+	h.scrollList.GotoTop()
+}
+
+// Update handles keys while the agent pane shows the history.
+func (h *HistoryView) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return nil
+	}
+
+	// Replay: scroll, step through iterations, or go back to the list
+	if h.transcript != nil {
+		switch keyMsg.String() {
+		case "esc":
+			h.transcript = nil
+			h.messages = nil
+			h.subagents = nil
+			h.err = nil
+			h.refreshContent()
+			return nil
+		case "[":
+			return h.open(h.cursor + 1)
+		case "]":
+			return h.open(h.cursor - 1)
+		}
+		return h.scrollList.Update(msg)
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		if h.loading >= 0 {
+			// Stop waiting; the late result is ignored by SetTranscript
+			h.loading = -1
+			return nil
+		}
+		return func() tea.Msg { return CloseHistoryMsg{} }
+	case "up", "k":
+		if h.cursor > 0 {
+			h.cursor--
+		}
+	case "down", "j":
+		if h.cursor < len(h.iterations)-1 {
+			h.cursor++
+		}
+	case "home":
+		h.cursor = 0
+	case "end":
+		h.cursor = max(len(h.iterations)-1, 0)
+	case "enter", "space":
+		return h.open(h.cursor)
+	}
+	return nil
+}
+
+// open asks for the transcript of the iteration at list row i.
+func (h *HistoryView) open(i int) tea.Cmd {
+	if i < 0 || i >= len(h.iterations) || h.loading >= 0 {
+		return nil
+	}
+	iteration := h.iterations[i]
+	return func() tea.Msg { return OpenTranscriptMsg{Iteration: iteration} }
+}
+
+// Draw renders the iteration list or the replayed transcript.
+func (h *HistoryView) Draw(scr uv.Screen, area uv.Rectangle) *tea.Cursor {
+	if area.Dx() < 2 || area.Dy() < 2 {
+		return nil
+	}
+	s := theme.Current().S()
+
+	// Content area with 1-char left margin, hint line at the bottom
+	contentArea := uv.Rect(area.Min.X+1, area.Min.Y, area.Dx()-1, max(area.Dy()-2, 1))
+	h.viewportArea = contentArea
+	hintArea := uv.Rect(area.Min.X+2, area.Max.Y-1, area.Dx()-2, 1)
+
+	var hint string
+	switch {
+	case h.loading >= 0:
+		uv.NewStyledString(s.Dim.Render(fmt.Sprintf("Loading iteration #%d...", h.loading))).Draw(scr, contentArea)
+		hint = RenderHintBar(KeyEsc, "back")
+	case h.transcript != nil:
+		h.drawReplay(scr, contentArea)
+		hint = RenderHintBar(KeyUpDownJK, "scroll", "[/]", "prev/next", KeyEsc, "list")
+	default:
+		h.drawList(scr, contentArea)
+		hint = RenderHintBar(KeyUpDown, "select", KeyEnter, "replay", KeyEsc, "live output")
+	}
+	uv.NewStyledString(hint).Draw(scr, hintArea)
+	return nil
+}
+
+// drawReplay renders the replayed transcript with a scroll indicator.
+func (h *HistoryView) drawReplay(scr uv.Screen, area uv.Rectangle) {
+	if h.scrollList.width != area.Dx() || h.scrollList.height != area.Dy() {
+		h.scrollList.SetWidth(area.Dx())
+		h.scrollList.SetHeight(area.Dy())
+		h.refreshContent()
+	}
+	uv.NewStyledString(h.scrollList.View()).Draw(scr, area)
+
+	if h.scrollList.TotalLineCount() > h.scrollList.height {
+		DrawScrollIndicator(scr, area, h.scrollList.ScrollPercent())
+	}
+}
+
+// drawList renders the iterations that can be replayed, newest first.
+func (h *HistoryView) drawList(scr uv.Screen, area uv.Rectangle) {
+	s := theme.Current().S()
+	if h.err != nil {
+		// Show the error above the list so another iteration can be picked
+		uv.NewStyledString(s.Error.Render(fmt.Sprintf("× %s", h.err.Error()))).Draw(scr, area)
+		area.Min.Y += 2
+		if area.Dy() < 1 || len(h.iterations) == 0 {
+			return
+		}
+	}
+	switch {
+	case !h.listed:
+		uv.NewStyledString(s.Dim.Render("Loading history...")).Draw(scr, area)
+		return
+	case len(h.iterations) == 0:
+		uv.NewStyledString(s.Dim.Render("No transcripts stored for this session yet")).Draw(scr, area)
+		return
+	}
+
+	// Keep the cursor visible
+	rows := area.Dy()
+	if h.cursor < h.offset {
+		h.offset = h.cursor
+	} else if h.cursor >= h.offset+rows {
+		h.offset = h.cursor - rows + 1
+	}
+
+	var lines []string
+	rowStyle := lipgloss.NewStyle().MaxWidth(area.Dx())
+	for i := h.offset; i < len(h.iterations) && i < h.offset+rows; i++ {
+		label := fmt.Sprintf("#%d", h.iterations[i])
+		prefix := "  "
+		if i == h.cursor {
+			prefix = s.TaskSelected.Render("▸ ")
+			label = s.Highlight.Render(label)
+		}
+		line := prefix + label
+		if details := h.iterationDetails(h.iterations[i]); details != "" {
+			line += "  " + s.Dim.Render(details)
+		}
+		lines = append(lines, rowStyle.Render(line))
+	}
+	uv.NewStyledString(strings.Join(lines, "\n")).Draw(scr, area)
+}
+
+// iterationDetails describes an iteration from the session state.
+func (h *HistoryView) iterationDetails(n int) string {
+	if n == 0 {
+		return "planning"
+	}
+	if h.state == nil {
+		return ""
+	}
+	for _, iter := range h.state.Iterations {
+		if iter.Number != n {
+			continue
+		}
+		parts := []string{iter.StartedAt.Local().Format("15:04")}
+		if iter.EndedAt.IsZero() {
+			parts = append(parts, "running")
+		} else {
+			parts = append(parts, iter.EndedAt.Sub(iter.StartedAt).Round(time.Second).String())
+		}
+		if !iter.Usage.IsZero() {
+			parts = append(parts, session.FormatTokens(iter.Usage.TotalTokens())+" tok")
+		}
+		if iter.RolledBack {
+			parts = append(parts, "rolled back")
+		} else if iter.TimedOut {
+			parts = append(parts, "timed out")
+		}
+		if iter.Summary != "" {
+			parts = append(parts, strings.Join(strings.Fields(iter.Summary), " "))
+		}
+		return strings.Join(parts, " · ")
+	}
+	return ""
+}
+
+// HandleClick toggles expandable items in the replay, or opens the subagent
+// modal when a subagent call is clicked.
+func (h *HistoryView) HandleClick(x, y int) tea.Cmd {
+	if h.transcript == nil || !h.IsViewportArea(x, y) {
+		return nil
+	}
+
+	// Translate screen Y to content line (accounting for scroll offset)
+	contentLine := (y - h.viewportArea.Min.Y) + h.scrollList.currentOffsetInLines()
+	msgIdx := -1
+	for i := len(h.messageLineStarts) - 1; i >= 0; i-- {
+		if contentLine >= h.messageLineStarts[i] {
+			msgIdx = i
+			break
+		}
+	}
+	if msgIdx < 0 || msgIdx >= len(h.messages) {
+		return nil
+	}
+
+	if subagentMsg, ok := h.messages[msgIdx].(*SubagentMessageItem); ok {
+		msg := OpenTranscriptSubagentMsg{
+			SubagentType: subagentMsg.subagentType,
+			Entries:      h.subagents[subagentMsg.id],
+		}
+		return func() tea.Msg { return msg }
+	}
+	if expandable, ok := h.messages[msgIdx].(Expandable); ok {
+		expandable.ToggleExpanded()
+		h.refreshContent()
+	}
+	return nil
+}
+
+// IsViewportArea checks if the given screen coordinates fall within the view.
+func (h *HistoryView) IsViewportArea(x, y int) bool {
+	return x >= h.viewportArea.Min.X && x < h.viewportArea.Max.X &&
+		y >= h.viewportArea.Min.Y && y < h.viewportArea.Max.Y
+}
+
+// ScrollViewport scrolls the replay by the given number of lines, or moves
+// the list selection.
+func (h *HistoryView) ScrollViewport(lines int) {
+	if h.transcript != nil {
+		h.scrollList.ScrollBy(lines)
+		return
+	}
+	h.cursor = min(max(h.cursor+lines/3, 0), max(len(h.iterations)-1, 0))
+}
+
+// refreshContent updates the ScrollList with the replayed message items.
+func (h *HistoryView) refreshContent() {
+	items := make([]ScrollItem, len(h.messages))
+	for i, msg := range h.messages {
+		items[i] = msg
+	}
+	h.scrollList.SetItems(items)
+
+	// Compute messageLineStarts for click-to-expand hit detection.
+	// Must include gap lines between items to match currentOffsetInLines.
+	h.messageLineStarts = make([]int, len(items))
+	offset := 0
+	gap := h.scrollList.ItemGap()
+	for i, item := range items {
+		h.messageLineStarts[i] = offset
+		item.Render(h.scrollList.width)
+		offset += item.Height()
+		if gap > 0 && i < len(items)-1 {
+			offset += gap
+		}
+	}
+}
+
+// transcriptItems converts a transcript to the message items of the live
+// agent output. Subagent entries are returned by parent tool call ID instead,
+// for the subagent modal.
+func transcriptItems(t *session.Transcript) ([]MessageItem, map[string][]session.TranscriptEntry) {
+	var items []MessageItem
+	subagents := make(map[string][]session.TranscriptEntry)
+	if t == nil {
+		return items, subagents
+	}
+
+	for i, entry := range t.Entries {
+		if entry.Subagent != "" {
+			subagents[entry.Subagent] = append(subagents[entry.Subagent], entry)
+			continue
+		}
+		switch entry.Kind {
+		case session.TranscriptKindPrompt:
+			items = append(items, &UserMessageItem{
+				id:      fmt.Sprintf("prompt-%d", i),
+				content: truncatePrompt(entry.Text, t.Iteration) + omittedNote(entry.Omitted),
+			})
+		case session.TranscriptKindText:
+			items = append(items, &TextMessageItem{
+				id:      fmt.Sprintf("text-%d", i),
+				content: entry.Text + omittedNote(entry.Omitted),
+			})
+		case session.TranscriptKindThinking:
+			items = append(items, &ThinkingMessageItem{
+				id:        fmt.Sprintf("thinking-%d", i),
+				content:   entry.Text + omittedNote(entry.Omitted),
+				collapsed: true,
+				finished:  true,
+			})
+		case session.TranscriptKindTool:
+			if entry.Tool != nil {
+				items = append(items, transcriptToolItem(entry))
+			}
+		case session.TranscriptKindFinish:
+			if entry.Finish == nil {
+				continue
+			}
+			if entry.Finish.StopReason == "cancelled" {
+				cancelPendingTools(items)
+			}
+			items = append(items, finishItems(transcriptFinishMsg(entry.Finish), len(items))...)
+		}
+	}
+
+	if t.Dropped > 0 {
+		items = append(items, &TextMessageItem{
+			id:      "dropped",
+			content: theme.Current().S().Dim.Render(fmt.Sprintf("%d later events were not recorded: the transcript reached its %d byte cap", t.Dropped, t.MaxBytes)),
+		})
+	}
+	return items, subagents
+}
+
+// transcriptToolItem converts a recorded tool call to a ToolMessageItem, or
+// a SubagentMessageItem for subagent calls.
+func transcriptToolItem(entry session.TranscriptEntry) MessageItem {
+	tool := entry.Tool
+	msg := AgentToolCallMsg{
+		ToolCallID: tool.ID,
+		Title:      tool.Title,
+		Status:     tool.Status,
+		Kind:       tool.Kind,
+		Input:      tool.Input,
+		Output:     tool.Output,
+	}
+	if subagentType, isSubagent := detectSubagent(msg); isSubagent {
+		item := &SubagentMessageItem{
+			id:           tool.ID,
+			subagentType: subagentType,
+			description:  extractSubagentDescription(msg),
+			status:       mapToolStatus(tool.Status),
+		}
+		if item.status == ToolStatusRunning {
+			// Static frame: replayed items are never animated
+			spinner := NewDefaultSpinner()
+			item.spinner = &spinner
+		}
+		return item
+	}
+
+	item := &ToolMessageItem{
+		id:       tool.ID,
+		toolName: tool.Title,
+		kind:     tool.Kind,
+		status:   mapToolStatus(tool.Status),
+		input:    tool.Input,
+		output:   tool.Output + omittedNote(entry.Omitted),
+		maxLines: 10,
+	}
+	if tool.Diff != nil {
+		item.fileDiff = convertFileDiff(transcriptFileDiff(tool.Diff))
+	}
+	return item
+}
+
+// transcriptFileDiff converts a recorded file diff to the agent type.
+func transcriptFileDiff(diff *session.TranscriptDiff) *agent.FileDiff {
+	return &agent.FileDiff{
+		File:      diff.File,
+		Before:    diff.Before,
+		After:     diff.After,
+		Additions: diff.Additions,
+		Deletions: diff.Deletions,
+	}
+}
+
+// transcriptFinishMsg converts a recorded finish to the message the live
+// output receives.
+func transcriptFinishMsg(finish *session.TranscriptFinish) AgentFinishMsg {
+	msg := AgentFinishMsg{
+		Reason:   finish.StopReason,
+		Error:    finish.Error,
+		Model:    finish.Model,
+		Provider: finish.Provider,
+		Duration: finish.Duration,
+	}
+	if !finish.Usage.IsZero() {
+		msg.Usage = &AgentUsage{
+			InputTokens:         finish.Usage.InputTokens,
+			OutputTokens:        finish.Usage.OutputTokens,
+			TotalTokens:         finish.Usage.TotalTokens(),
+			ReasoningTokens:     finish.Usage.ReasoningTokens,
+			CacheCreationTokens: finish.Usage.CacheCreationTokens,
+			CacheReadTokens:     finish.Usage.CacheReadTokens,
+		}
+	}
+	return msg
+}
+
+// cancelPendingTools marks pending and running tool calls as canceled, as
+// AppendFinish does for a cancelled turn.
+func cancelPendingTools(items []MessageItem) {
+	for _, item := range items {
+		if toolMsg, ok := item.(*ToolMessageItem); ok {
+			if toolMsg.status == ToolStatusPending || toolMsg.status == ToolStatusRunning {
+				toolMsg.status = ToolStatusCanceled
+			}
+		}
+	}
+}
+
+// truncatePrompt keeps the first historyPromptLines lines of a prompt.
+func truncatePrompt(prompt string, iteration int) string {
+	lines := strings.Split(strings.TrimRight(prompt, "\n"), "\n")
+	if len(lines) <= historyPromptLines {
+		return strings.Join(lines, "\n")
+	}
+	return fmt.Sprintf("%s\n… %d more lines (iteratr transcript --iteration %d)",
+		strings.Join(lines[:historyPromptLines], "\n"), len(lines)-historyPromptLines, iteration)
+}
+
+// omittedNote notes bytes cut from an entry when it was recorded.
+func omittedNote(omitted int) string {
+	if omitted <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n[%d bytes omitted]", omitted)
+}
+
+// NewTranscriptSubagentModal creates a SubagentModal showing the entries a
+// subagent recorded in a transcript, without loading its session.
+func NewTranscriptSubagentModal(subagentType string, entries []session.TranscriptEntry) *SubagentModal {
+	m := NewSubagentModal("", subagentType, "")
+	m.loading = false
+	m.scrollList.SetAutoScroll(false)
+	for _, entry := range entries {
+		switch entry.Kind {
+		case session.TranscriptKindText:
+			m.appendText(entry.Text)
+		case session.TranscriptKindThinking:
+			m.appendThinking(entry.Text)
+		case session.TranscriptKindTool:
+			if tool := entry.Tool; tool != nil {
+				event := agent.ToolCallEvent{
+					ToolCallID: tool.ID,
+					Title:      tool.Title,
+					Status:     tool.Status,
+					RawInput:   tool.Input,
+					Output:     tool.Output,
+					Kind:       tool.Kind,
+				}
+				if tool.Diff != nil {
+					event.FileDiff = transcriptFileDiff(tool.Diff)
+				}
+				m.appendToolCall(event)
+			}
+		}
+	}
+	return m
+}
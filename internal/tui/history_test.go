@@ -0,0 +1,195 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/stretchr/testify/require"
+)
+
+func testTranscript(iteration int) *session.Transcript {
+	prompt := strings.TrimSuffix(strings.Repeat("line\n", historyPromptLines+5), "\n")
+	return &session.Transcript{
+		Session:   testfixtures.FixedSessionName,
+		Iteration: iteration,
+		MaxBytes:  1000,
+		Dropped:   3,
+		Entries: []session.TranscriptEntry{
+			{Kind: session.TranscriptKindPrompt, Text: prompt},
+			{Kind: session.TranscriptKindThinking, Text: "Plan the edit"},
+			{Kind: session.TranscriptKindText, Text: "Editing now", Omitted: 12},
+			{Kind: session.TranscriptKindTool, Tool: &session.TranscriptTool{
+				ID: "edit-1", Title: "edit", Kind: "edit", Status: "completed",
+				Input: map[string]any{"path": "a.go"},
+				Diff:  &session.TranscriptDiff{File: "a.go", Before: "old\n", After: "new\n", Additions: 1, Deletions: 1},
+			}},
+			{Kind: session.TranscriptKindTool, Tool: &session.TranscriptTool{
+				ID: "sub-1", Title: "spawn_subagent", Kind: "agent", Status: "completed",
+				Input: map[string]any{"task": "Check the tests"},
+			}},
+			{Kind: session.TranscriptKindText, Subagent: "sub-1", Text: "Tests pass"},
+			{Kind: session.TranscriptKindTool, Subagent: "sub-1", Tool: &session.TranscriptTool{ID: "bash-1", Title: "bash", Status: "completed", Output: "ok"}},
+			{Kind: session.TranscriptKindTool, Tool: &session.TranscriptTool{ID: "bash-2", Title: "bash", Status: "in_progress"}},
+			{Kind: session.TranscriptKindFinish, Finish: &session.TranscriptFinish{
+				StopReason: "cancelled", Model: "m", Duration: time.Second,
+				Usage: session.Usage{InputTokens: 100, OutputTokens: 10},
+			}},
+		},
+	}
+}
+
+func TestTranscriptItems(t *testing.T) {
+	t.Parallel()
+
+	items, subagents := transcriptItems(testTranscript(2))
+	require.Len(t, items, 9)
+
+	prompt, ok := items[0].(*UserMessageItem)
+	require.True(t, ok, "prompt should be a UserMessageItem")
+	require.Equal(t, historyPromptLines+1, strings.Count(prompt.content, "\n")+1, "prompt should be cut to historyPromptLines")
+	require.Contains(t, prompt.content, "… 5 more lines (iteratr transcript --iteration 2)")
+
+	thinking, ok := items[1].(*ThinkingMessageItem)
+	require.True(t, ok)
+	require.True(t, thinking.finished && thinking.collapsed)
+
+	text, ok := items[2].(*TextMessageItem)
+	require.True(t, ok)
+	require.Equal(t, "Editing now\n[12 bytes omitted]", text.content)
+
+	edit, ok := items[3].(*ToolMessageItem)
+	require.True(t, ok)
+	require.Equal(t, ToolStatusSuccess, edit.status)
+	require.NotNil(t, edit.fileDiff)
+	require.Equal(t, "new\n", edit.fileDiff.After)
+
+	subagent, ok := items[4].(*SubagentMessageItem)
+	require.True(t, ok, "agent tool calls should be SubagentMessageItems")
+	require.Equal(t, "Check the tests", subagent.description)
+	require.Len(t, subagents["sub-1"], 2, "subagent entries should be kept for the modal, not shown inline")
+
+	bash, ok := items[5].(*ToolMessageItem)
+	require.True(t, ok)
+	require.Equal(t, ToolStatusCanceled, bash.status, "a cancelled turn should cancel its running tools")
+
+	info, ok := items[6].(*InfoMessageItem)
+	require.True(t, ok)
+	require.Equal(t, int64(110), info.usage.TotalTokens)
+	require.Contains(t, items[7].(*TextMessageItem).content, "Iteration canceled")
+	require.Contains(t, items[8].(*TextMessageItem).content, "3 later events were not recorded")
+
+	// Every item renders
+	for _, item := range items {
+		require.NotEmpty(t, item.Render(80))
+	}
+}
+
+func TestHistoryView_Navigation(t *testing.T) {
+	t.Parallel()
+
+	d := NewDashboard(NewAgentOutput(), NewSidebar())
+	d.focusPane = FocusTasks
+	d.OpenHistory()
+	require.Equal(t, FocusAgent, d.focusPane, "opening the history should focus the agent pane")
+	h := d.history
+	require.Equal(t, "History", h.Title())
+
+	h.SetIterations([]int{0, 1, 2}, nil)
+	require.Equal(t, []int{2, 1, 0}, h.iterations, "newest iteration should be listed first")
+
+	d.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	cmd := d.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	require.Equal(t, OpenTranscriptMsg{Iteration: 1}, cmd())
+
+	h.SetLoading(1)
+	h.SetTranscript(2, testTranscript(2), nil) // stale result
+	require.Nil(t, h.transcript)
+	h.SetTranscript(1, testTranscript(1), nil)
+	require.Equal(t, "Iteration #1 (read-only)", h.Title())
+
+	// [ steps to the previous iteration, ] to the next
+	cmd = d.Update(tea.KeyPressMsg{Code: '[', Text: "["})
+	require.Equal(t, OpenTranscriptMsg{Iteration: 0}, cmd())
+	cmd = d.Update(tea.KeyPressMsg{Code: ']', Text: "]"})
+	require.Equal(t, OpenTranscriptMsg{Iteration: 2}, cmd())
+
+	// esc goes back to the list, then to the live output
+	d.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	require.Nil(t, h.transcript)
+	require.Equal(t, 1, h.cursor, "the replayed iteration should stay selected")
+	cmd = d.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	require.Equal(t, CloseHistoryMsg{}, cmd())
+}
+
+func TestHistoryView_Draw(t *testing.T) {
+	t.Parallel()
+
+	state := &session.State{Iterations: []*session.Iteration{{
+		Number:    1,
+		StartedAt: time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local),
+		EndedAt:   time.Date(2026, 1, 1, 10, 4, 12, 0, time.Local),
+		Summary:   "Added the parser",
+	}}}
+	h := NewHistoryView(state)
+	h.SetIterations([]int{0, 1}, nil)
+
+	render := func() string {
+		canvas := uv.NewScreenBuffer(100, 30)
+		h.Draw(canvas, canvas.Bounds())
+		return canvas.Render()
+	}
+
+	list := render()
+	require.Contains(t, list, "#1")
+	require.Contains(t, list, "10:00 · 4m12s · Added the parser")
+	require.Contains(t, list, "planning")
+
+	h.SetLoading(1)
+	h.SetTranscript(1, nil, errors.New("transcript not found"))
+	require.Contains(t, render(), "transcript not found")
+
+	h.SetLoading(1)
+	h.SetTranscript(1, testTranscript(1), nil)
+	replay := render()
+	require.Contains(t, replay, "line")
+	require.Contains(t, replay, "prev/next")
+}
+
+func TestApp_HistoryMessages(t *testing.T) {
+	t.Parallel()
+
+	app := NewApp(context.Background(), nil, testfixtures.FixedSessionName, "/tmp", t.TempDir(), nil, nil, nil)
+	app.width = testfixtures.TestTermWidth
+	app.height = testfixtures.TestTermHeight
+
+	// Without a store there is no history to browse
+	app.Update(tea.KeyPressMsg{Text: "ctrl+x"})
+	app.Update(tea.KeyPressMsg{Text: "h"})
+	require.Nil(t, app.dashboard.history)
+
+	app.dashboard.OpenHistory()
+	app.Update(HistoryListMsg{Iterations: []int{1}})
+	app.dashboard.history.SetLoading(1)
+	app.Update(TranscriptLoadedMsg{Iteration: 1, Transcript: testTranscript(1)})
+	require.NotNil(t, app.dashboard.history.transcript)
+
+	// The live output keeps receiving events while the history is shown
+	app.Update(AgentOutputMsg{Content: "live text"})
+	require.NotEmpty(t, app.agent.messages)
+
+	app.Update(OpenTranscriptSubagentMsg{SubagentType: "subagent", Entries: testTranscript(1).Entries[5:7]})
+	require.NotNil(t, app.subagentModal)
+	require.False(t, app.subagentModal.loading)
+	require.Len(t, app.subagentModal.messages, 2)
+
+	app.Update(CloseHistoryMsg{})
+	require.Nil(t, app.dashboard.history)
+}
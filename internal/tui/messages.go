@@ -1101,12 +1101,13 @@ func toolDisplayName(rawName string) string {
 
 // hookDisplayNames maps raw hook type strings to human-friendly display names.
 var hookDisplayNames = map[string]string{
-	"session_start":    "Session Start",
-	"pre_iteration":    "Pre Iteration",
-	"post_iteration":   "Post Iteration",
-	"session_end":      "Session End",
-	"on_task_complete": "Task Complete",
-	"on_error":         "On Error",
+	"session_start":      "Session Start",
+	"pre_iteration":      "Pre Iteration",
+	"post_iteration":     "Post Iteration",
+	"session_end":        "Session End",
+	"on_task_complete":   "Task Complete",
+	"on_error":           "On Error",
+	"on_budget_exceeded": "Budget Exceeded",
 }
 
 // hookDisplayName returns a human-friendly display name for a hook type.
@@ -486,6 +486,17 @@ func (m *TaskModal) buildContent(width int) string {
 		sections = append(sections, depsLabel+depsContent)
 	}
 
+	// === Parent Section ===
+	if m.task.ParentID != "" {
+		sections = append(sections, s.ModalLabel.Render("Subtask of: ")+s.ModalValue.Render(m.task.ParentID))
+	}
+
+	// === Details Section ===
+	if details := m.renderDetails(width - 2); len(details) > 0 {
+		sections = append(sections, details...)
+		sections = append(sections, "")
+	}
+
 	// === Timestamps Section ===
 	createdLine := s.ModalLabel.Render("Created:  ") + s.ModalValue.Render(m.formatTime(m.task.CreatedAt))
 	updatedLine := s.ModalLabel.Render("Updated:  ") + s.ModalValue.Render(m.formatTime(m.task.UpdatedAt))
@@ -503,6 +514,35 @@ func (m *TaskModal) buildContent(width int) string {
 	return strings.Join(sections, "\n")
 }
 
+// renderDetails renders the task's optional labels, estimate, description
+// and acceptance criteria. Returns nil when the task has none of them.
+func (m *TaskModal) renderDetails(width int) []string {
+	s := theme.Current().S()
+	var lines []string
+
+	if len(m.task.Labels) > 0 {
+		lines = append(lines, s.ModalLabel.Render("Labels:   ")+s.ModalValue.Render(strings.Join(m.task.Labels, ", ")))
+	}
+	if m.task.Estimate != "" {
+		lines = append(lines, s.ModalLabel.Render("Estimate: ")+s.ModalValue.Render(m.task.Estimate))
+	}
+	if m.task.Description != "" {
+		lines = append(lines, s.ModalLabel.Render("Description:"))
+		for _, paragraph := range strings.Split(strings.TrimSpace(m.task.Description), "\n") {
+			lines = append(lines, s.ModalValue.Render(m.wordWrap(paragraph, width)))
+		}
+	}
+	if len(m.task.AcceptanceCriteria) > 0 {
+		lines = append(lines, s.ModalLabel.Render("Acceptance criteria:"))
+		for _, criterion := range m.task.AcceptanceCriteria {
+			wrapped := strings.ReplaceAll(m.wordWrap(criterion, width-2), "\n", "\n  ")
+			lines = append(lines, s.ModalValue.Render("• "+wrapped))
+		}
+	}
+
+	return lines
+}
+
 // renderStatusBadges renders all status badges with the active one highlighted.
 func (m *TaskModal) renderStatusBadges() string {
 	t := theme.Current()
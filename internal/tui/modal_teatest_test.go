@@ -469,6 +469,32 @@ func TestTaskModalGolden_NoDependencies(t *testing.T) {
 	testfixtures.CompareGolden(t, goldenFile, content)
 }
 
+func TestTaskModalGolden_Details(t *testing.T) {
+	modal := NewTaskModal()
+	task := &session.Task{
+		ID:                 "TAS-009",
+		Content:            "Add login endpoint",
+		Status:             "in_progress",
+		Priority:           1,
+		DependsOn:          []string{},
+		Description:        "POST /login checks the password hash and returns a session cookie for the browser client.",
+		AcceptanceCriteria: []string{"returns 401 on a bad password", "cookie is HttpOnly and Secure"},
+		Labels:             []string{"backend", "auth"},
+		Estimate:           "2h",
+		CreatedAt:          testfixtures.FixedTime,
+		UpdatedAt:          testfixtures.FixedTime,
+	}
+	modal.SetTask(task)
+
+	content := modal.buildContent(60)
+	require.Contains(t, content, "Acceptance criteria:")
+	require.Contains(t, content, "returns 401 on a bad password")
+	require.Contains(t, content, "backend, auth")
+
+	goldenFile := filepath.Join("testdata", "task_modal_details.golden")
+	testfixtures.CompareGolden(t, goldenFile, content)
+}
+
 // compareGolden compares rendered output with golden file
 // Note: compareGolden is defined in messages_expanded_test.go and shared across golden file tests
 
@@ -0,0 +1,309 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textarea"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+
+	"github.com/mark3labs/iteratr/internal/tui/theme"
+)
+
+// Review actions a reviewer can take on an iteration's changes.
+const (
+	ReviewApprove  = "approve"  // Keep the changes and let auto-commit run
+	ReviewReject   = "reject"   // Roll the iteration back
+	ReviewAnnotate = "annotate" // Keep the changes uncommitted and send feedback
+)
+
+// ReviewRequestMsg asks the user to review the changes of an iteration.
+// Sent by the orchestrator, which waits for the decision before committing.
+type ReviewRequestMsg struct {
+	Iteration int
+	Files     []string // Modified paths (relative to the working directory)
+	Diff      string   // Unified diff of Files against HEAD
+}
+
+// ReviewDecisionMsg carries the reviewer's decision back to the App.
+type ReviewDecisionMsg struct {
+	Action   string // ReviewApprove, ReviewReject, or ReviewAnnotate
+	Feedback string // Optional message for the agent's next iteration
+}
+
+// RequestRejectReviewMsg asks for confirmation before rejecting an iteration.
+type RequestRejectReviewMsg struct {
+	Iteration int
+	Feedback  string
+}
+
+// ReviewModal shows the diff of an iteration and lets the user approve,
+// reject, or annotate it with feedback. Hiding it (esc) keeps the review
+// pending; ctrl+x v shows it again.
+type ReviewModal struct {
+	visible   bool
+	pending   bool // A review is waiting for a decision
+	iteration int
+	files     []string
+	diff      string
+	viewport  viewport.Model
+	textarea  textarea.Model
+	editing   bool // Feedback textarea has focus
+}
+
+// NewReviewModal creates a new ReviewModal component.
+func NewReviewModal() *ReviewModal {
+	ta := textarea.New()
+	ta.Placeholder = "Feedback for the next iteration..."
+	ta.CharLimit = 2000
+	ta.ShowLineNumbers = false
+	ta.Prompt = ""
+	ta.SetHeight(3)
+	ta.KeyMap.LineNext = key.NewBinding(key.WithKeys("down"))
+
+	t := theme.Current()
+	styles := textarea.DefaultDarkStyles()
+	styles.Cursor.Color = lipgloss.Color(t.Secondary)
+	styles.Cursor.Shape = tea.CursorBlock
+	styles.Cursor.Blink = true
+	ta.SetStyles(styles)
+
+	return &ReviewModal{
+		viewport: viewport.New(),
+		textarea: ta,
+	}
+}
+
+// IsVisible returns whether the modal is currently visible.
+func (m *ReviewModal) IsVisible() bool {
+	return m.visible
+}
+
+// IsPending returns whether a review is waiting for a decision.
+func (m *ReviewModal) IsPending() bool {
+	return m.pending
+}
+
+// Show opens the modal for a new review request.
+func (m *ReviewModal) Show(msg ReviewRequestMsg) {
+	m.visible = true
+	m.pending = true
+	m.iteration = msg.Iteration
+	m.files = msg.Files
+	m.diff = msg.Diff
+	m.editing = false
+	m.textarea.SetValue("")
+	m.textarea.Blur()
+	m.viewport.SetContent(renderReviewDiff(msg.Diff))
+	m.viewport.GotoTop()
+}
+
+// Reopen shows a pending review again after it was hidden.
+func (m *ReviewModal) Reopen() {
+	if m.pending {
+		m.visible = true
+	}
+}
+
+// Hide closes the modal but keeps the review pending.
+func (m *ReviewModal) Hide() {
+	m.visible = false
+	m.editing = false
+	m.textarea.Blur()
+}
+
+// Close hides the modal and clears the pending review.
+func (m *ReviewModal) Close() {
+	m.Hide()
+	m.pending = false
+	m.diff = ""
+	m.files = nil
+	m.textarea.SetValue("")
+}
+
+// ScrollViewport scrolls the diff by the given number of lines.
+func (m *ReviewModal) ScrollViewport(lines int) {
+	if lines < 0 {
+		m.viewport.ScrollUp(-lines)
+	} else {
+		m.viewport.ScrollDown(lines)
+	}
+}
+
+// Update handles keyboard input for the modal.
+// Diff view: a approve, r reject, f feedback, arrows scroll, esc hide.
+// Feedback input: ctrl+enter sends the feedback (annotate), tab/esc return to the diff.
+func (m *ReviewModal) Update(msg tea.Msg) tea.Cmd {
+	if !m.visible {
+		return nil
+	}
+
+	if pasteMsg, ok := msg.(tea.PasteMsg); ok {
+		if m.editing {
+			var cmd tea.Cmd
+			m.textarea, cmd = m.textarea.Update(pasteMsg)
+			return cmd
+		}
+		return nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return nil
+	}
+
+	if m.editing {
+		switch keyMsg.String() {
+		case "esc", "tab":
+			m.editing = false
+			m.textarea.Blur()
+			return nil
+		case "ctrl+enter":
+			feedback := m.feedback()
+			if feedback == "" {
+				return nil
+			}
+			return m.decide(ReviewAnnotate, feedback)
+		}
+		var cmd tea.Cmd
+		m.textarea, cmd = m.textarea.Update(msg)
+		return cmd
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		m.Hide()
+		return nil
+	case "a":
+		return m.decide(ReviewApprove, m.feedback())
+	case "r":
+		iteration, feedback := m.iteration, m.feedback()
+		return func() tea.Msg {
+			return RequestRejectReviewMsg{Iteration: iteration, Feedback: feedback}
+		}
+	case "f", "tab":
+		m.editing = true
+		return m.textarea.Focus()
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return cmd
+}
+
+// feedback returns the trimmed feedback text.
+func (m *ReviewModal) feedback() string {
+	return strings.TrimSpace(m.textarea.Value())
+}
+
+// decide returns a command that reports the decision to the App.
+func (m *ReviewModal) decide(action, feedback string) tea.Cmd {
+	return func() tea.Msg {
+		return ReviewDecisionMsg{Action: action, Feedback: feedback}
+	}
+}
+
+// renderReviewDiff colors a unified diff line by line.
+func renderReviewDiff(diff string) string {
+	s := theme.Current().S()
+	if strings.TrimSpace(diff) == "" {
+		return s.EmptyState.Render("No changes against HEAD")
+	}
+
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			lines[i] = s.DiagFile.Render(line)
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+			strings.HasPrefix(line, "index "), strings.HasPrefix(line, "new file"),
+			strings.HasPrefix(line, "deleted file"):
+			lines[i] = s.Dim.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = s.Info.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = s.Success.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = s.Error.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hint returns the hint bar for the current input mode.
+func (m *ReviewModal) hint() string {
+	if m.editing {
+		return RenderHintBar("ctrl+enter", "send feedback", KeyTab, "back to diff")
+	}
+	return RenderHintBar("a", "approve", "r", "reject", "f", "feedback", KeyUpDownJK, "scroll", KeyEsc, "hide")
+}
+
+// Draw renders the review modal as a full-screen overlay.
+func (m *ReviewModal) Draw(scr uv.Screen, area uv.Rectangle) {
+	if !m.visible {
+		return
+	}
+
+	modalWidth := area.Dx() - 4
+	modalHeight := area.Dy() - 4
+	if modalWidth < 40 {
+		modalWidth = area.Dx()
+	}
+	if modalHeight < 16 {
+		modalHeight = area.Dy()
+	}
+
+	// Layout: title, separator, diff, separator, feedback (3), hint
+	contentWidth := modalWidth - 6 // Border (2) + padding (4)
+	feedbackHeight := 3
+	diffHeight := modalHeight - 4 - 4 - feedbackHeight // Padding (2) + border (2), fixed lines (4)
+	if contentWidth < 1 {
+		contentWidth = 1
+	}
+	if diffHeight < 1 {
+		diffHeight = 1
+	}
+	m.viewport.SetWidth(contentWidth)
+	m.viewport.SetHeight(diffHeight)
+	m.textarea.SetWidth(contentWidth)
+	m.textarea.SetHeight(feedbackHeight)
+
+	s := theme.Current().S()
+	title := renderModalTitle(fmt.Sprintf("Review Iteration #%d (%d files)", m.iteration, len(m.files)), contentWidth)
+	separator := s.ModalSeparator.Render(strings.Repeat("─", contentWidth))
+
+	content := strings.Join([]string{
+		title,
+		separator,
+		m.viewport.View(),
+		separator,
+		m.textarea.View(),
+		m.hint(),
+	}, "\n")
+
+	modalContent := s.ModalContainer.
+		Width(modalWidth).
+		Height(modalHeight).
+		Render(content)
+
+	renderedWidth := lipgloss.Width(modalContent)
+	renderedHeight := lipgloss.Height(modalContent)
+	x := (area.Dx() - renderedWidth) / 2
+	y := (area.Dy() - renderedHeight) / 2
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+
+	modalArea := uv.Rectangle{
+		Min: uv.Position{X: area.Min.X + x, Y: area.Min.Y + y},
+		Max: uv.Position{X: area.Min.X + x + renderedWidth, Y: area.Min.Y + y + renderedHeight},
+	}
+	uv.NewStyledString(modalContent).Draw(scr, modalArea)
+}
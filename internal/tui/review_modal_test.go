@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/stretchr/testify/require"
+)
+
+const testReviewDiff = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1 +1,2 @@
+ package main
++func main() {}
+`
+
+func newReviewApp(t *testing.T, orch *mockOrchestrator) *App {
+	t.Helper()
+	app := NewApp(context.Background(), nil, testfixtures.FixedSessionName, "/tmp", t.TempDir(), nil, nil, orch)
+	app.width = testfixtures.TestTermWidth
+	app.height = testfixtures.TestTermHeight
+	app.Update(ReviewRequestMsg{Iteration: 3, Files: []string{"main.go"}, Diff: testReviewDiff})
+	require.True(t, app.reviewModal.IsVisible(), "Review request should open the modal")
+	return app
+}
+
+// sendDecision runs cmd and feeds the resulting decision back into the app
+func sendDecision(t *testing.T, app *App, cmd tea.Cmd) {
+	t.Helper()
+	require.NotNil(t, cmd)
+	msg := cmd()
+	require.IsType(t, ReviewDecisionMsg{}, msg)
+	app.Update(msg)
+}
+
+func TestReviewModal_Approve(t *testing.T) {
+	t.Parallel()
+
+	orch := &mockOrchestrator{}
+	app := newReviewApp(t, orch)
+
+	_, cmd := app.Update(tea.KeyPressMsg{Text: "a"})
+	sendDecision(t, app, cmd)
+
+	require.Equal(t, ReviewApprove, orch.reviewAction)
+	require.False(t, app.reviewModal.IsPending(), "Decision should close the review")
+	require.False(t, app.reviewModal.IsVisible())
+}
+
+func TestReviewModal_RejectConfirms(t *testing.T) {
+	t.Parallel()
+
+	orch := &mockOrchestrator{}
+	app := newReviewApp(t, orch)
+
+	_, cmd := app.Update(tea.KeyPressMsg{Text: "r"})
+	require.NotNil(t, cmd)
+	app.Update(cmd())
+	require.True(t, app.dialog.IsVisible(), "Reject should ask for confirmation")
+	require.Empty(t, orch.reviewAction, "Nothing is sent before confirmation")
+
+	cmd = app.dialog.Update(tea.KeyPressMsg{Text: "enter"})
+	sendDecision(t, app, cmd)
+	require.Equal(t, ReviewReject, orch.reviewAction)
+}
+
+func TestReviewModal_AnnotateWithFeedback(t *testing.T) {
+	t.Parallel()
+
+	orch := &mockOrchestrator{}
+	app := newReviewApp(t, orch)
+
+	app.Update(tea.KeyPressMsg{Text: "f"})
+	require.True(t, app.reviewModal.editing, "f should focus the feedback input")
+
+	// Empty feedback cannot be sent
+	_, cmd := app.Update(tea.KeyPressMsg{Text: "ctrl+enter"})
+	require.Nil(t, cmd)
+
+	app.Update(tea.PasteMsg{Content: "Add a test"})
+	_, cmd = app.Update(tea.KeyPressMsg{Text: "ctrl+enter"})
+	sendDecision(t, app, cmd)
+
+	require.Equal(t, ReviewAnnotate, orch.reviewAction)
+	require.Equal(t, "Add a test", orch.reviewFeedback)
+}
+
+func TestReviewModal_HideAndReopen(t *testing.T) {
+	t.Parallel()
+
+	app := newReviewApp(t, &mockOrchestrator{})
+
+	app.Update(tea.KeyPressMsg{Text: "esc"})
+	require.False(t, app.reviewModal.IsVisible(), "esc should hide the modal")
+	require.True(t, app.reviewModal.IsPending(), "Hidden review should stay pending")
+	require.True(t, app.status.reviewPending, "Status bar should point at the pending review")
+
+	app.Update(tea.KeyPressMsg{Text: "ctrl+x"})
+	app.Update(tea.KeyPressMsg{Text: "v"})
+	require.True(t, app.reviewModal.IsVisible(), "ctrl+x v should reopen the review")
+	require.False(t, app.status.reviewPending)
+}
+
+func TestReviewModal_IgnoresStaleDecision(t *testing.T) {
+	t.Parallel()
+
+	orch := &mockOrchestrator{}
+	app := NewApp(context.Background(), nil, testfixtures.FixedSessionName, "/tmp", t.TempDir(), nil, nil, orch)
+
+	app.Update(ReviewDecisionMsg{Action: ReviewApprove})
+	require.Empty(t, orch.reviewAction, "Decisions without a pending review are dropped")
+}
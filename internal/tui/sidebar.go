@@ -2,7 +2,6 @@ package tui
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
@@ -20,6 +19,7 @@ type OpenTaskModalMsg struct {
 // taskScrollItem wraps a task for use in ScrollList.
 type taskScrollItem struct {
 	task       *session.Task
+	depth      int // Nesting level in the task hierarchy
 	isSelected bool
 	width      int
 	rendered   string
@@ -74,8 +74,11 @@ func (t *taskScrollItem) renderTask() string {
 		indicatorStyle = s.StatusRemaining
 	}
 
+	// Indent subtasks under their parent
+	indent := strings.Repeat("  ", t.depth)
+
 	// Truncate content to fit width (leave room for indicator and padding)
-	maxContentWidth := t.width - 6 // 2 for indicator+space, 2 padding, 2 for selection arrow
+	maxContentWidth := t.width - 6 - len(indent) // 2 for indicator+space, 2 padding, 2 for selection arrow
 	if maxContentWidth < 10 {
 		maxContentWidth = 10
 	}
@@ -87,7 +90,7 @@ func (t *taskScrollItem) renderTask() string {
 
 	// Build line (selection arrow handled by ScrollList)
 	styledIndicator := indicatorStyle.Render(indicator)
-	line := fmt.Sprintf(" %s %s", styledIndicator, content)
+	line := fmt.Sprintf(" %s%s %s", indent, styledIndicator, content)
 
 	return line
 }
@@ -414,8 +417,19 @@ func (s *Sidebar) drawLogo(scr uv.Screen, area uv.Rectangle) {
 	}
 }
 
-// getTasks returns all tasks ordered by ID.
+// getTasks returns all tasks ordered by ID, with subtasks following their parent.
 func (s *Sidebar) getTasks() []*session.Task {
+	tree := s.getTaskTree()
+	tasks := make([]*session.Task, len(tree))
+	for i, item := range tree {
+		tasks[i] = item.Task
+	}
+	return tasks
+}
+
+// getTaskTree returns the tasks in display order, each subtask following its
+// parent, along with their nesting depth.
+func (s *Sidebar) getTaskTree() []session.TaskTreeItem {
 	if s.state == nil {
 		return nil
 	}
@@ -425,11 +439,9 @@ func (s *Sidebar) getTasks() []*session.Task {
 		tasks = append(tasks, task)
 	}
 
-	sort.Slice(tasks, func(i, j int) bool {
-		return tasks[i].ID < tasks[j].ID
+	return session.TaskTree(tasks, func(a, b *session.Task) bool {
+		return a.ID < b.ID
 	})
-
-	return tasks
 }
 
 // Draw renders the sidebar to the screen buffer with logo, tasks, and notes sections.
@@ -651,19 +663,21 @@ func (s *Sidebar) updateContent() {
 	s.rebuildIndex()
 
 	// Update tasks ScrollList
-	tasks := s.getTasks()
-	taskItems := make([]ScrollItem, 0, len(tasks))
-	for idx, task := range tasks {
+	tree := s.getTaskTree()
+	taskItems := make([]ScrollItem, 0, len(tree))
+	for idx, item := range tree {
+		task := item.Task
 		isSelected := (s.focused && idx == s.cursor) || task.ID == s.activeTaskID
 		taskItems = append(taskItems, &taskScrollItem{
 			task:       task,
+			depth:      item.Depth,
 			isSelected: isSelected,
 			width:      s.tasksScrollList.width,
 		})
 	}
 	s.tasksScrollList.SetItems(taskItems)
 	// Set selected index for cursor highlighting
-	if s.focused && s.cursor >= 0 && s.cursor < len(tasks) {
+	if s.focused && s.cursor >= 0 && s.cursor < len(tree) {
 		s.tasksScrollList.SetSelected(s.cursor)
 	} else {
 		s.tasksScrollList.SetSelected(-1)
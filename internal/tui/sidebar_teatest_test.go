@@ -934,3 +934,36 @@ func TestSidebar_Render_SmallScreen(t *testing.T) {
 	goldenFile := filepath.Join("testdata", "sidebar_small_screen.golden")
 	testfixtures.CompareGolden(t, goldenFile, rendered)
 }
+
+// TestSidebar_TaskHierarchy tests that subtasks are listed indented under their parent
+func TestSidebar_TaskHierarchy(t *testing.T) {
+	sidebar := NewSidebar()
+	sidebar.SetSize(40, 30)
+	sidebar.SetState(&session.State{
+		Tasks: map[string]*session.Task{
+			"TAS-1": {ID: "TAS-1", Content: "Epic", Status: "remaining"},
+			"TAS-2": {ID: "TAS-2", Content: "Other", Status: "remaining"},
+			"TAS-3": {ID: "TAS-3", Content: "Step", Status: "completed", ParentID: "TAS-1"},
+			"TAS-4": {ID: "TAS-4", Content: "Substep", Status: "remaining", ParentID: "TAS-3"},
+		},
+	})
+
+	wantOrder := []string{"TAS-1", "TAS-3", "TAS-4", "TAS-2"}
+	wantDepth := []int{0, 1, 2, 0}
+	items := sidebar.tasksScrollList.items
+	if len(items) != len(wantOrder) {
+		t.Fatalf("expected %d task items, got %d", len(wantOrder), len(items))
+	}
+	for i, item := range items {
+		taskItem := item.(*taskScrollItem)
+		if taskItem.task.ID != wantOrder[i] || taskItem.depth != wantDepth[i] {
+			t.Errorf("item %d = %s at depth %d, want %s at depth %d", i, taskItem.task.ID, taskItem.depth, wantOrder[i], wantDepth[i])
+		}
+	}
+	if idx := sidebar.taskIndex["TAS-4"]; idx != 2 {
+		t.Errorf("expected TAS-4 at cursor index 2, got %d", idx)
+	}
+	if line := items[2].Render(40); line[:5] != "     " {
+		t.Errorf("expected substep indented two levels, got %q", line)
+	}
+}
@@ -1063,7 +1063,7 @@ func (m *WizardModel) startAgentPhase() tea.Msg {
 
 	// Create agent runner (all callbacks use only local vars or program sender)
 	logger.Debug("Creating agent runner")
-	agentRunner := agent.NewKitAgent(agent.KitAgentConfig{
+	agentRunner := agent.NewKitAgent(agent.BackendConfig{
 		Model:        model,
 		WorkDir:      workDir,
 		SessionName:  "spec-wizard",
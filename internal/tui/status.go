@@ -34,6 +34,7 @@ type StatusBar struct {
 	modifiedFileCount int  // Number of files modified in current iteration
 	prefixMode        bool // Whether waiting for second key after ctrl+x
 	sidebarHidden     bool // Whether sidebar is currently hidden
+	reviewPending     bool // Whether a hidden review waits for a decision
 
 	// Git status fields
 	gitBranch string // Branch name or "HEAD" if detached
@@ -121,6 +122,11 @@ func (s *StatusBar) buildLeft() string {
 		left += sep + stats
 	}
 
+	// Add session token usage and estimated cost once recorded
+	if usage := s.buildUsage(); usage != "" {
+		left += sep + theme.Current().S().HeaderInfo.Render(usage)
+	}
+
 	// Add modified file count if any files modified
 	if s.modifiedFileCount > 0 {
 		fileInfo := fmt.Sprintf("%d file", s.modifiedFileCount)
@@ -224,6 +230,19 @@ func (s *StatusBar) buildTaskStats() string {
 	return strings.Join(parts, " ")
 }
 
+// buildUsage builds the session usage summary.
+// Format: 12.3k tok ~$0.42 (cost omitted when no price is configured)
+func (s *StatusBar) buildUsage() string {
+	if s.state == nil || s.state.Usage.IsZero() {
+		return ""
+	}
+	usage := session.FormatTokens(s.state.Usage.TotalTokens()) + " tok"
+	if s.state.Usage.Cost > 0 {
+		usage += fmt.Sprintf(" ~$%.2f", s.state.Usage.Cost)
+	}
+	return usage
+}
+
 // buildRight builds the right side with keybinding hints.
 func (s *StatusBar) buildRight() string {
 	// Show prefix mode indicator when waiting for second key
@@ -232,12 +251,17 @@ func (s *StatusBar) buildRight() string {
 			theme.Current().S().HintDesc.Render("(awaiting key...)")
 	}
 
+	// Show review hint while a hidden review waits for a decision
+	if s.reviewPending {
+		return RenderHintBar(KeyCtrlXV, "review", KeyCtrlXL, "logs", KeyCtrlC, "quit")
+	}
+
 	// Show restart hint when session is complete
 	if s.state != nil && s.state.Complete {
 		if s.sidebarHidden {
-			return RenderHintBar(KeyCtrlXR, "restart", KeyCtrlXB, "sidebar", KeyCtrlXL, "logs", KeyCtrlC, "quit")
+			return RenderHintBar(KeyCtrlXR, "restart", KeyCtrlXU, "undo", KeyCtrlXB, "sidebar", KeyCtrlXL, "logs", KeyCtrlC, "quit")
 		}
-		return RenderHintBar(KeyCtrlXR, "restart", KeyCtrlXL, "logs", KeyCtrlC, "quit")
+		return RenderHintBar(KeyCtrlXR, "restart", KeyCtrlXU, "undo", KeyCtrlXL, "logs", KeyCtrlC, "quit")
 	}
 
 	// Show sidebar hint when hidden
@@ -284,6 +308,11 @@ func (s *StatusBar) SetSidebarHidden(hidden bool) {
 	s.sidebarHidden = hidden
 }
 
+// SetReviewPending updates whether a hidden review waits for a decision.
+func (s *StatusBar) SetReviewPending(pending bool) {
+	s.reviewPending = pending
+}
+
 // SetGitInfo updates the git repository status fields.
 func (s *StatusBar) SetGitInfo(msg GitInfoMsg) {
 	s.gitBranch = msg.Branch
@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+	"github.com/mark3labs/iteratr/internal/tui/theme"
+)
+
+// SessionMsg tags a message for one session's App inside a Switcher.
+// Orchestrators sharing a Switcher send through a SessionSender, which wraps
+// every message in a SessionMsg.
+type SessionMsg struct {
+	Session string
+	Msg     tea.Msg
+}
+
+// SwitchSessionMsg asks the Switcher to show another session.
+// Delta moves relative to the visible session (wrapping around); Index, when
+// non-zero, selects a session by its 1-based position instead.
+type SwitchSessionMsg struct {
+	Delta int
+	Index int
+}
+
+// SessionSender delivers messages to one session's App in a Switcher.
+type SessionSender struct {
+	program *tea.Program
+	session string
+}
+
+// NewSessionSender returns a sender that tags messages for session.
+func NewSessionSender(program *tea.Program, session string) SessionSender {
+	return SessionSender{program: program, session: session}
+}
+
+// Send delivers msg to the session's App.
+func (s SessionSender) Send(msg tea.Msg) {
+	s.program.Send(SessionMsg{Session: s.session, Msg: msg})
+}
+
+// Quit stops the shared program.
+func (s SessionSender) Quit() {
+	s.program.Quit()
+}
+
+// teaPkgPath identifies messages defined by Bubbletea itself (quit, window
+// title, clipboard, ...), which must reach the runtime untagged.
+var teaPkgPath = reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+
+// Switcher hosts one App per session in a single Bubbletea program and shows
+// one of them at a time below a session tab bar. Keyboard, mouse, and paste
+// input go to the visible App; messages from orchestrators arrive as
+// SessionMsg and go to their own App whether it is visible or not.
+type Switcher struct {
+	apps     []*App
+	active   int
+	width    int
+	height   int
+	quitting bool
+}
+
+// NewSwitcher creates a Switcher over apps, showing the first one.
+func NewSwitcher(apps []*App) *Switcher {
+	for _, app := range apps {
+		app.inSwitcher = true
+	}
+	return &Switcher{apps: apps}
+}
+
+// Init initializes every App.
+func (s *Switcher) Init() tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(s.apps))
+	for _, app := range s.apps {
+		cmds = append(cmds, wrapSessionCmd(app.sessionName, app.Init()))
+	}
+	return tea.Batch(cmds...)
+}
+
+// Update routes msg to the App it belongs to.
+func (s *Switcher) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SessionMsg:
+		if switchMsg, ok := msg.Msg.(SwitchSessionMsg); ok {
+			s.switchTo(switchMsg)
+			return s, nil
+		}
+		app := s.app(msg.Session)
+		if app == nil {
+			return s, nil
+		}
+		return s, s.updateApp(app, msg.Msg)
+
+	case SwitchSessionMsg:
+		s.switchTo(msg)
+		return s, nil
+
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+		// The tab bar takes the top row
+		inner := tea.WindowSizeMsg{Width: msg.Width, Height: max(msg.Height-1, 0)}
+		cmds := make([]tea.Cmd, 0, len(s.apps))
+		for _, app := range s.apps {
+			cmds = append(cmds, s.updateApp(app, inner))
+		}
+		return s, tea.Batch(cmds...)
+
+	case tea.MouseClickMsg:
+		if msg.Y == 0 {
+			return s, nil
+		}
+		msg.Y--
+		return s, s.updateActive(msg)
+
+	case tea.MouseWheelMsg:
+		if msg.Y == 0 {
+			return s, nil
+		}
+		msg.Y--
+		return s, s.updateActive(msg)
+
+	case tea.KeyPressMsg, tea.PasteMsg, tea.MouseMotionMsg, tea.MouseReleaseMsg:
+		return s, s.updateActive(msg)
+	}
+
+	// Anything else (focus, terminal capabilities, ...) concerns every App
+	cmds := make([]tea.Cmd, 0, len(s.apps))
+	for _, app := range s.apps {
+		cmds = append(cmds, s.updateApp(app, msg))
+	}
+	return s, tea.Batch(cmds...)
+}
+
+// View renders the tab bar above the visible App.
+func (s *Switcher) View() tea.View {
+	if len(s.apps) == 0 {
+		return tea.View{}
+	}
+	view := s.apps[s.active].View()
+	if s.quitting {
+		return view
+	}
+	view.Content = s.renderTabs() + "\n" + view.Content
+	if view.Cursor != nil {
+		view.Cursor.Y++
+	}
+	return view
+}
+
+// Active returns the session name of the visible App.
+func (s *Switcher) Active() string {
+	if len(s.apps) == 0 {
+		return ""
+	}
+	return s.apps[s.active].sessionName
+}
+
+// updateActive forwards msg to the visible App.
+func (s *Switcher) updateActive(msg tea.Msg) tea.Cmd {
+	if len(s.apps) == 0 {
+		return nil
+	}
+	return s.updateApp(s.apps[s.active], msg)
+}
+
+// updateApp forwards msg to app and tags the resulting command so its
+// messages come back to the same App.
+func (s *Switcher) updateApp(app *App, msg tea.Msg) tea.Cmd {
+	_, cmd := app.Update(msg)
+	if app.quitting {
+		// ctrl+c in any session quits the whole program
+		s.quitting = true
+	}
+	return wrapSessionCmd(app.sessionName, cmd)
+}
+
+// switchTo changes the visible session.
+func (s *Switcher) switchTo(msg SwitchSessionMsg) {
+	n := len(s.apps)
+	if n == 0 {
+		return
+	}
+	if msg.Index > 0 {
+		if msg.Index <= n {
+			s.active = msg.Index - 1
+		}
+		return
+	}
+	s.active = ((s.active+msg.Delta)%n + n) % n
+}
+
+// app returns the App for session, or nil.
+func (s *Switcher) app(session string) *App {
+	for _, app := range s.apps {
+		if app.sessionName == session {
+			return app
+		}
+	}
+	return nil
+}
+
+// renderTabs renders the session tab bar.
+// Format: 1 alpha ✓ │ 2 beta │ 3 gamma ⏸      ctrl+x [/] switch
+func (s *Switcher) renderTabs() string {
+	th := theme.Current().S()
+	tabs := make([]string, 0, len(s.apps))
+	for i, app := range s.apps {
+		label := fmt.Sprintf("%d %s", i+1, app.sessionName)
+		switch {
+		case app.status.state != nil && app.status.state.Complete:
+			label += " ✓"
+		case app.status.paused:
+			label += " ⏸"
+		}
+		if i == s.active {
+			tabs = append(tabs, th.HeaderTitle.Render(label))
+		} else {
+			tabs = append(tabs, th.Muted.Render(label))
+		}
+	}
+	left := " " + strings.Join(tabs, th.HeaderSeparator.Render(" │ "))
+	right := RenderHintBar(KeyCtrlXSwitch, "switch") + " "
+
+	padding := s.width - lipgloss.Width(left) - lipgloss.Width(right)
+	if padding < 1 {
+		return lipgloss.NewStyle().MaxWidth(s.width).Render(left)
+	}
+	return left + strings.Repeat(" ", padding) + right
+}
+
+// wrapSessionCmd tags the messages produced by cmd for session. Batches are
+// unpacked so each command is tagged, and Bubbletea's own messages are passed
+// through for the runtime to handle.
+func wrapSessionCmd(session string, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		switch msg := msg.(type) {
+		case nil:
+			return nil
+		case tea.BatchMsg:
+			cmds := make([]tea.Cmd, 0, len(msg))
+			for _, c := range msg {
+				cmds = append(cmds, wrapSessionCmd(session, c))
+			}
+			return tea.BatchMsg(cmds)
+		}
+		if reflect.TypeOf(msg).PkgPath() == teaPkgPath {
+			return msg
+		}
+		return SessionMsg{Session: session, Msg: msg}
+	}
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSwitcher(t *testing.T, sessions ...string) *Switcher {
+	t.Helper()
+	apps := make([]*App, 0, len(sessions))
+	for _, name := range sessions {
+		apps = append(apps, NewApp(context.Background(), nil, name, "/tmp", t.TempDir(), nil, nil, nil))
+	}
+	s := NewSwitcher(apps)
+	s.Update(tea.WindowSizeMsg{Width: testfixtures.TestTermWidth, Height: testfixtures.TestTermHeight})
+	return s
+}
+
+func TestSwitcher_RoutesSessionMessages(t *testing.T) {
+	t.Parallel()
+	s := newTestSwitcher(t, "alpha", "beta")
+
+	state := &session.State{Session: "beta", Complete: true}
+	s.Update(SessionMsg{Session: "beta", Msg: StateUpdateMsg{State: state}})
+
+	require.Same(t, state, s.apps[1].status.state, "beta should receive its state update")
+	require.Nil(t, s.apps[0].status.state, "alpha should not receive beta's messages")
+	require.Contains(t, s.renderTabs(), "2 beta ✓", "tab bar should mark beta complete")
+
+	// Unknown sessions are ignored
+	_, cmd := s.Update(SessionMsg{Session: "gamma", Msg: StateUpdateMsg{State: state}})
+	require.Nil(t, cmd)
+}
+
+func TestSwitcher_SwitchSessions(t *testing.T) {
+	t.Parallel()
+	s := newTestSwitcher(t, "alpha", "beta", "gamma")
+	require.Equal(t, "alpha", s.Active())
+
+	// ctrl+x ] goes to the next session; the App's command comes back tagged
+	s.Update(tea.KeyPressMsg{Text: "ctrl+x"})
+	_, cmd := s.Update(tea.KeyPressMsg{Text: "]"})
+	require.NotNil(t, cmd)
+	msg := cmd()
+	require.Equal(t, SessionMsg{Session: "alpha", Msg: SwitchSessionMsg{Delta: 1}}, msg)
+	s.Update(msg)
+	require.Equal(t, "beta", s.Active())
+	require.False(t, s.apps[0].awaitingPrefixKey, "prefix mode should end after the switch key")
+
+	// Previous wraps around
+	s.Update(SwitchSessionMsg{Delta: -1})
+	s.Update(SwitchSessionMsg{Delta: -1})
+	require.Equal(t, "gamma", s.Active())
+
+	// Jump by number; out-of-range numbers are ignored
+	s.Update(SwitchSessionMsg{Index: 1})
+	require.Equal(t, "alpha", s.Active())
+	s.Update(SwitchSessionMsg{Index: 9})
+	require.Equal(t, "alpha", s.Active())
+}
+
+func TestSwitcher_KeysGoToActiveSession(t *testing.T) {
+	t.Parallel()
+	s := newTestSwitcher(t, "alpha", "beta")
+	s.Update(SwitchSessionMsg{Index: 2})
+
+	s.Update(tea.KeyPressMsg{Text: "ctrl+x"})
+	require.False(t, s.apps[0].awaitingPrefixKey)
+	require.True(t, s.apps[1].awaitingPrefixKey)
+}
+
+func TestSwitcher_WindowSizeLeavesRoomForTabs(t *testing.T) {
+	t.Parallel()
+	s := newTestSwitcher(t, "alpha", "beta")
+
+	for _, app := range s.apps {
+		require.Equal(t, testfixtures.TestTermWidth, app.width)
+		require.Equal(t, testfixtures.TestTermHeight-1, app.height)
+	}
+}
+
+func TestWrapSessionCmd(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, wrapSessionCmd("alpha", nil))
+
+	// App messages are tagged with the session
+	cmd := wrapSessionCmd("alpha", func() tea.Msg { return AgentBusyMsg{Busy: true} })
+	require.Equal(t, SessionMsg{Session: "alpha", Msg: AgentBusyMsg{Busy: true}}, cmd())
+
+	// Bubbletea's own messages reach the runtime untagged
+	require.Equal(t, tea.QuitMsg{}, wrapSessionCmd("alpha", tea.Quit)())
+
+	// Batches are unpacked and each command tagged
+	batch := wrapSessionCmd("alpha", tea.Batch(
+		func() tea.Msg { return AgentBusyMsg{} },
+		func() tea.Msg { return PauseStateMsg{} },
+	))
+	cmds, ok := batch().(tea.BatchMsg)
+	require.True(t, ok)
+	require.Len(t, cmds, 2)
+	require.Equal(t, SessionMsg{Session: "alpha", Msg: PauseStateMsg{}}, cmds[1]())
+}
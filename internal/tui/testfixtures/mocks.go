@@ -321,6 +321,8 @@ type MockOrchestrator struct {
 	pauseRequested bool
 	pauseCancelled bool
 	resumed        bool
+	rolledBack     bool
+	reviewAction   string
 }
 
 // NewMockOrchestrator creates a new MockOrchestrator.
@@ -356,6 +358,35 @@ func (m *MockOrchestrator) IsPaused() bool {
 	return m.paused
 }
 
+// RollbackLastIteration records the rollback request.
+func (m *MockOrchestrator) RollbackLastIteration() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rolledBack = true
+	return "Rolled back iteration", nil
+}
+
+// SubmitReview records the review decision.
+func (m *MockOrchestrator) SubmitReview(action, feedback string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reviewAction = action
+}
+
+// ReviewAction returns the last action passed to SubmitReview.
+func (m *MockOrchestrator) ReviewAction() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.reviewAction
+}
+
+// WasRolledBack returns true if RollbackLastIteration was called.
+func (m *MockOrchestrator) WasRolledBack() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.rolledBack
+}
+
 // SetPaused sets the paused state (for testing).
 func (m *MockOrchestrator) SetPaused(paused bool) {
 	m.mu.Lock()
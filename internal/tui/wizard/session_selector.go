@@ -58,6 +58,15 @@ func (s *SessionItem) Render(width int) string {
 	progressText := fmt.Sprintf("%d/%d tasks", s.info.TasksCompleted, s.info.TasksTotal)
 	parts = append(parts, progressText)
 
+	// Token usage and estimated cost (once recorded)
+	if !s.info.Usage.IsZero() {
+		usageText := session.FormatTokens(s.info.Usage.TotalTokens()) + " tok"
+		if s.info.Usage.Cost > 0 {
+			usageText += fmt.Sprintf(" ~$%.2f", s.info.Usage.Cost)
+		}
+		parts = append(parts, usageText)
+	}
+
 	// Relative time
 	relativeTime := formatRelativeTime(s.info.LastActivity)
 	parts = append(parts, relativeTime)